@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/safedep/codex/pkg/cache"
+	"github.com/safedep/codex/pkg/parser/py/imports"
+	"github.com/safedep/dry/log"
+	"github.com/safedep/vet/pkg/common/logger"
+	"github.com/spf13/cobra"
+)
+
+var cache_dir string
+var cache_max_size int64
+var no_cache bool
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Manage the cache of parsed files",
+	Long: `Manage the cache of parsed files. Scans store the analysis of every file keyed by
+	the hash of its content, so unchanged files are not parsed again by later scans.`,
+}
+
+var cmdCacheClean = &cobra.Command{
+	Use:   "clean",
+	Short: "Remove every entry of the cache",
+	Run: func(cmd *cobra.Command, args []string) {
+		log.Debugf("Running Cache Clean..")
+		cleanCache()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(cacheCmd)
+	cacheCmd.AddCommand(cmdCacheClean)
+
+	for _, cmd := range []*cobra.Command{scanCmd, graphCmd, checkCmd, cacheCmd} {
+		cmd.PersistentFlags().StringVar(&cache_dir, "cache-dir", "",
+			"Directory of the cache, default is codex in the user cache directory")
+	}
+	for _, cmd := range []*cobra.Command{scanCmd, graphCmd, checkCmd} {
+		cmd.PersistentFlags().BoolVar(&no_cache, "no-cache", false, "Parse every file without using the cache")
+		cmd.PersistentFlags().Int64Var(&cache_max_size, "cache-max-size", cache.DEFAULT_MAX_SIZE/(1024*1024),
+			"Size limit of the cache in MiB, least recently used entries are removed beyond it")
+	}
+}
+
+func getCacheDir() (string, error) {
+	if cache_dir != "" {
+		return cache_dir, nil
+	}
+	return cache.DefaultDir()
+}
+
+// setupCache enables the cache of the parser unless disabled by --no-cache
+func setupCache(parser *imports.CodeParser) error {
+	if no_cache {
+		return nil
+	}
+
+	dir, err := getCacheDir()
+	if err != nil {
+		return fmt.Errorf("error while finding cache directory: %w", err)
+	}
+
+	diskCache, err := cache.NewDiskCache(dir, cache_max_size*1024*1024)
+	if err != nil {
+		return err
+	}
+
+	parser.SetCache(diskCache)
+	return nil
+}
+
+func cleanCache() {
+	dir, err := getCacheDir()
+	if err != nil {
+		logger.Warnf("Error while finding cache directory %v", err)
+		return
+	}
+
+	if err := cache.Clean(dir); err != nil {
+		logger.Warnf("Error while cleaning cache %v", err)
+		return
+	}
+
+	fmt.Printf("Removed cache entries from %s\n", dir)
+}
@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+
+	"github.com/safedep/codex/pkg/callgraph/py/callgraph"
+	"github.com/safedep/codex/pkg/parser/py/imports"
+	"github.com/safedep/codex/pkg/report"
+	"github.com/safedep/codex/pkg/utils/pathfilter"
+	"github.com/safedep/dry/log"
+	"github.com/safedep/vet/pkg/common/logger"
+	"github.com/spf13/cobra"
+)
+
+var function_name string
+
+var callgraphCmd = &cobra.Command{
+	Use:   "callgraph",
+	Short: "Build the call graph of the functions of the project",
+	Long: `Build the call graph of the functions of the project. Calls are resolved across files
+	through imports, aliases and self method calls. Calls of functions of other packages,
+	e.g. requests.get, are linked to external nodes.
+	For example:
+	go run main.go callgraph --input <project_path>
+	go run main.go callgraph --input <project_path> --function app.main.main
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		log.Debugf("Running Call Graph..")
+		printCallGraph()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(callgraphCmd)
+
+	callgraphCmd.Flags().StringVar(&input_file, "input", "", "Path of the project")
+	callgraphCmd.MarkFlagRequired("input")
+	callgraphCmd.Flags().StringVar(&function_name, "function", "",
+		"Only print the calls made by and made to the function with this qualified name")
+	callgraphCmd.Flags().StringSliceVar(&include_patterns, "include", []string{"**/*.py"},
+		"Glob patterns of the files to scan, relative to the input directory")
+	callgraphCmd.Flags().StringSliceVar(&exclude_patterns, "exclude", []string{},
+		"Glob patterns of the files and directories to skip, relative to the input directory")
+	callgraphCmd.Flags().BoolVar(&no_ignore, "no-ignore", false,
+		"Scan the files ignored by ignore files, virtualenvs and version control directories")
+	callgraphCmd.Flags().IntVar(&concurrency, "concurrency", runtime.NumCPU(),
+		"Number of files parsed in parallel")
+}
+
+// findCallGraph builds the call graph of the project in the directory
+func findCallGraph(ctx context.Context, dirpath string) (*callgraph.Graph, *imports.RepoSymbols, error) {
+	cf := imports.NewPyCodeParserFactory()
+	parser, err := cf.NewCodeParser()
+	if err != nil {
+		return nil, nil, fmt.Errorf("error while creating parser: %w", err)
+	}
+	err = parser.SetConcurrency(concurrency)
+	if err != nil {
+		return nil, nil, err
+	}
+	parser.SetPathFilter(pathfilter.Config{Include: include_patterns,
+		Exclude:      exclude_patterns,
+		HonorIgnores: !no_ignore})
+
+	// Files which fail to parse are reported along with the results
+	repoSymbols, err := parser.FindSymbols(ctx, dirpath, false, []string{".py"}, []string{})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return callgraph.Build(dirpath, repoSymbols), repoSymbols, nil
+}
+
+func printCallGraph() {
+	ctx := context.Background()
+	graph, repoSymbols, err := findCallGraph(ctx, input_file)
+	if err != nil {
+		logger.Warnf("Error while building call graph %v", err)
+		return
+	}
+
+	if function_name != "" {
+		if _, ok := graph.GetNode(function_name); !ok {
+			logger.Warnf("Function %s is not in the call graph", function_name)
+			return
+		}
+	}
+
+	r := report.NewReport("callgraph", input_file)
+	r.AddCallGraph(graph)
+	if function_name != "" {
+		filterCallGraph(r.CallGraph, function_name)
+	}
+	for _, e := range repoSymbols.Errors {
+		r.Errors = append(r.Errors, &report.ParseError{Path: e.Path, Message: e.Err.Error()})
+	}
+	if writeReport(r) {
+		return
+	}
+
+	fmt.Println("Calls:")
+	for _, edge := range r.CallGraph.Edges {
+		fmt.Printf("%s -> %s (%s:%d)\n", edge.Caller, edge.Callee, edge.Path, edge.Line)
+	}
+	fmt.Printf("Unresolved calls: %d\n", len(graph.GetUnresolvedCalls()))
+
+	printParseErrors(repoSymbols.Errors)
+}
+
+// filterCallGraph keeps the calls made by and made to the function, and their nodes
+func filterCallGraph(cg *report.CallGraph, function string) {
+	kept := map[string]bool{function: true}
+	edges := make([]*report.CallGraphEdge, 0)
+	for _, edge := range cg.Edges {
+		if edge.Caller == function || edge.Callee == function {
+			edges = append(edges, edge)
+			kept[edge.Caller] = true
+			kept[edge.Callee] = true
+		}
+	}
+
+	nodes := make([]*report.CallGraphNode, 0)
+	for _, node := range cg.Nodes {
+		if kept[node.ID] {
+			nodes = append(nodes, node)
+		}
+	}
+
+	cg.Nodes = nodes
+	cg.Edges = edges
+}
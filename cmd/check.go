@@ -0,0 +1,268 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/safedep/codex/pkg/architecture/py/architecture"
+	"github.com/safedep/codex/pkg/modulegraph/py/modulegraph"
+	"github.com/safedep/codex/pkg/policy/py/policy"
+	"github.com/safedep/codex/pkg/report"
+	"github.com/safedep/dry/log"
+	"github.com/safedep/vet/pkg/common/logger"
+	"github.com/spf13/cobra"
+)
+
+var fail_on_deferred bool
+var architecture_config string
+var policy_config string
+
+var checkCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Check the code of the project for CI",
+	Long: `Check the code of the project. It has few subcommands, which exit with
+	a non-zero code when the check fails.`,
+}
+
+var cmdCheckCycles = &cobra.Command{
+	Use:   "cycles",
+	Short: "Check that the modules of the project do not import each other in cycles",
+	Long: `Check that the modules of the project do not import each other in cycles.
+	The shortest cycle of every group of modules importing each other is printed with
+	the file and line of its imports. Cycles going through imports inside functions or
+	TYPE_CHECKING blocks are harmless at runtime, they are reported as deferred and only
+	fail the check with --fail-on-deferred.
+	For example:
+	go run main.go check cycles --input <project_path>
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		log.Debugf("Running Check Cycles..")
+		if !checkCycles() {
+			os.Exit(1)
+		}
+	},
+}
+
+var cmdCheckArchitecture = &cobra.Command{
+	Use:   "architecture",
+	Short: "Check the imports of the project against architecture contracts",
+	Long: `Check the imports of the project against the contracts of a YAML file,
+	architecture.yaml in the project directory by default. Contracts forbid modules to
+	import other modules, or restrict the modules and the paths which may import a
+	module or a package. Every import breaking a contract is printed with its file and line.
+	For example:
+	go run main.go check architecture --input <project_path>
+	go run main.go check architecture --input <project_path> --config contracts.yaml
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		log.Debugf("Running Check Architecture..")
+		if !checkArchitecture() {
+			os.Exit(1)
+		}
+	},
+}
+
+var cmdCheckPolicy = &cobra.Command{
+	Use:   "policy",
+	Short: "Check the imports of the project against a policy",
+	Long: `Check the imports of the project against the rules of a YAML file,
+	policy.yaml in the project directory by default. Rules are CEL expressions over
+	the variables of every import: pkg, module, name, classification, path, scope,
+	dynamic, optional and guards. Every import for which a rule is true is printed
+	with its file and line.
+	For example:
+	go run main.go check policy --input <project_path>
+	go run main.go check policy --input <project_path> --config policy.yaml --format sarif
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		log.Debugf("Running Check Policy..")
+		if !checkPolicy() {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(checkCmd)
+	checkCmd.AddCommand(cmdCheckCycles)
+	checkCmd.AddCommand(cmdCheckArchitecture)
+	checkCmd.AddCommand(cmdCheckPolicy)
+
+	checkCmd.PersistentFlags().StringVar(&input_file, "input", "", "Path of the project")
+	checkCmd.MarkPersistentFlagRequired("input")
+	checkCmd.PersistentFlags().StringSliceVar(&include_patterns, "include", []string{"**/*.py"},
+		"Glob patterns of the files to scan, relative to the input directory")
+	checkCmd.PersistentFlags().StringSliceVar(&exclude_patterns, "exclude", []string{},
+		"Glob patterns of the files and directories to skip, relative to the input directory")
+	checkCmd.PersistentFlags().BoolVar(&no_ignore, "no-ignore", false,
+		"Scan the files ignored by ignore files, virtualenvs and version control directories")
+	checkCmd.PersistentFlags().IntVar(&concurrency, "concurrency", runtime.NumCPU(),
+		"Number of files parsed in parallel")
+
+	cmdCheckCycles.Flags().BoolVar(&fail_on_deferred, "fail-on-deferred", false,
+		"Fail on cycles going through imports inside functions or TYPE_CHECKING blocks")
+	cmdCheckArchitecture.Flags().StringVar(&architecture_config, "config", "",
+		"Contracts file, default is "+architecture.DEFAULT_CONFIG_FILE+" in the project directory")
+	cmdCheckPolicy.Flags().StringVar(&policy_config, "config", "",
+		"Policy file, default is "+policy.DEFAULT_CONFIG_FILE+" in the project directory")
+}
+
+// checkCycles reports the import cycles of the project and tells whether the check passes
+func checkCycles() bool {
+	ctx := context.Background()
+	graph, r, err := findModuleGraph(ctx, input_file)
+	if err != nil {
+		logger.Warnf("Error while building module graph %v", err)
+		return false
+	}
+
+	cycles := modulegraph.FindCycles(graph)
+	passed := true
+	for _, cycle := range cycles {
+		if !cycle.Deferred || fail_on_deferred {
+			passed = false
+		}
+	}
+
+	r.Command = "check-cycles"
+	r.ModuleGraph = nil
+	r.AddImportCycles(cycles)
+	if writeReport(r) {
+		return passed
+	}
+
+	for _, cycle := range r.ImportCycles {
+		modules := []string{}
+		for _, edge := range cycle.Path {
+			modules = append(modules, edge.From)
+		}
+		modules = append(modules, cycle.Path[0].From)
+
+		if cycle.Deferred {
+			fmt.Printf("Deferred import cycle (harmless at runtime): %s\n", strings.Join(modules, " -> "))
+		} else {
+			fmt.Printf("Import cycle: %s\n", strings.Join(modules, " -> "))
+		}
+		for _, edge := range cycle.Path {
+			for _, occurrence := range edge.Imports {
+				guards := ""
+				if len(occurrence.Guards) > 0 {
+					guards = fmt.Sprintf(" (%s)", strings.Join(occurrence.Guards, ", "))
+				}
+				fmt.Printf("\t%s:%d %s imports %s: %s%s\n", occurrence.Path, occurrence.LineStart,
+					edge.From, edge.To, occurrence.Statement, guards)
+			}
+		}
+		if len(cycle.Modules) > len(cycle.Path) {
+			fmt.Printf("\tModules importing each other: %s\n", strings.Join(cycle.Modules, ", "))
+		}
+	}
+
+	for _, f := range r.Findings {
+		fmt.Printf("%s:%d %s\n", f.Path, f.LineStart, f.Message)
+	}
+	for _, e := range r.Errors {
+		fmt.Printf("%s %s\n", e.Path, e.Message)
+	}
+
+	if len(cycles) == 0 {
+		fmt.Println("No import cycles")
+	}
+	return passed
+}
+
+// checkArchitecture reports the imports breaking the architecture contracts and tells whether the check passes
+func checkArchitecture() bool {
+	configPath := architecture_config
+	if configPath == "" {
+		configPath = filepath.Join(input_file, architecture.DEFAULT_CONFIG_FILE)
+	}
+	config, err := architecture.LoadConfig(configPath)
+	if err != nil {
+		logger.Warnf("Error while loading architecture contracts %v", err)
+		return false
+	}
+
+	ctx := context.Background()
+	rootPkgs, _, err := findImportedModules(ctx, input_file)
+	if err != nil {
+		logger.Warnf("Error while finding imported modules %v", err)
+		return false
+	}
+
+	violations := architecture.Check(input_file, rootPkgs.GetRepoCodeAnalysis(), config)
+
+	r := report.NewReport("check-architecture", input_file)
+	r.AddArchitectureViolations(violations)
+	for _, e := range rootPkgs.GetParseErrors() {
+		r.Errors = append(r.Errors, &report.ParseError{Path: e.Path, Message: e.Err.Error()})
+	}
+	if writeReport(r) {
+		return len(violations) == 0
+	}
+
+	for _, v := range r.Violations {
+		fmt.Printf("%s:%d %s\n", v.Path, v.LineStart, v.Message)
+		fmt.Printf("\t%s\n", v.Statement)
+	}
+	for _, e := range r.Errors {
+		fmt.Printf("%s %s\n", e.Path, e.Message)
+	}
+
+	if len(violations) == 0 {
+		fmt.Printf("%d contracts kept\n", len(config.Contracts))
+	}
+	return len(violations) == 0
+}
+
+// checkPolicy reports the imports flagged by the rules of the policy and tells whether the check passes
+func checkPolicy() bool {
+	configPath := policy_config
+	if configPath == "" {
+		configPath = filepath.Join(input_file, policy.DEFAULT_CONFIG_FILE)
+	}
+	config, err := policy.LoadConfig(configPath)
+	if err != nil {
+		logger.Warnf("Error while loading policy %v", err)
+		return false
+	}
+
+	ctx := context.Background()
+	rootPkgs, _, err := findImportedModules(ctx, input_file)
+	if err != nil {
+		logger.Warnf("Error while finding imported modules %v", err)
+		return false
+	}
+
+	violations, err := policy.Evaluate(rootPkgs.GetRepoCodeAnalysis(), config)
+	if err != nil {
+		logger.Warnf("Error while evaluating policy %v", err)
+		return false
+	}
+
+	r := report.NewReport("check-policy", input_file)
+	r.AddPolicyViolations(violations)
+	for _, e := range rootPkgs.GetParseErrors() {
+		r.Errors = append(r.Errors, &report.ParseError{Path: e.Path, Message: e.Err.Error()})
+	}
+	if writeReport(r) {
+		return len(violations) == 0
+	}
+
+	for _, v := range r.Violations {
+		fmt.Printf("%s:%d %s\n", v.Path, v.LineStart, v.Message)
+		fmt.Printf("\t%s\n", v.Statement)
+	}
+	for _, e := range r.Errors {
+		fmt.Printf("%s %s\n", e.Path, e.Message)
+	}
+
+	if len(violations) == 0 {
+		fmt.Printf("%d rules passed\n", len(config.Rules))
+	}
+	return len(violations) == 0
+}
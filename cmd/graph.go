@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+
+	"github.com/safedep/codex/pkg/modulegraph/py/modulegraph"
+	"github.com/safedep/codex/pkg/parser/py/imports"
+	"github.com/safedep/codex/pkg/report"
+	"github.com/safedep/dry/log"
+	"github.com/safedep/vet/pkg/common/logger"
+	"github.com/spf13/cobra"
+)
+
+var graphCmd = &cobra.Command{
+	Use:   "graph",
+	Short: "Export graphs of the project",
+	Long:  `Export graphs of the project. It has few subcommands.`,
+}
+
+var cmdGraphModules = &cobra.Command{
+	Use:   "modules",
+	Short: "Export the graph of the imports between the modules of the project",
+	Long: `Export the graph of the imports between the modules of the project, with the
+	third-party packages they import as leaves. Imports inside functions or TYPE_CHECKING
+	blocks are dashed edges.
+	For example:
+	go run main.go graph modules --input <project_path> --format dot | dot -Tsvg > modules.svg
+	go run main.go graph modules --input <project_path> --format mermaid
+`,
+	Annotations: map[string]string{EXTRA_FORMATS_ANNOTATION: graphFormatNames()},
+	Run: func(cmd *cobra.Command, args []string) {
+		log.Debugf("Running Graph Modules..")
+		exportModuleGraph()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(graphCmd)
+	graphCmd.AddCommand(cmdGraphModules)
+
+	graphCmd.PersistentFlags().StringVar(&input_file, "input", "", "Path of the project")
+	graphCmd.MarkPersistentFlagRequired("input")
+	graphCmd.PersistentFlags().StringSliceVar(&include_patterns, "include", []string{"**/*.py"},
+		"Glob patterns of the files to scan, relative to the input directory")
+	graphCmd.PersistentFlags().StringSliceVar(&exclude_patterns, "exclude", []string{},
+		"Glob patterns of the files and directories to skip, relative to the input directory")
+	graphCmd.PersistentFlags().BoolVar(&no_ignore, "no-ignore", false,
+		"Scan the files ignored by ignore files, virtualenvs and version control directories")
+	graphCmd.PersistentFlags().IntVar(&concurrency, "concurrency", runtime.NumCPU(),
+		"Number of files parsed in parallel")
+}
+
+// findModuleGraph builds the graph of the imports of the modules of the project in the directory
+func findModuleGraph(ctx context.Context, dirpath string) (*modulegraph.Graph, *report.Report, error) {
+	rootPkgs, _, err := findImportedModules(ctx, dirpath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	graph := modulegraph.Build(dirpath, rootPkgs.GetRepoCodeAnalysis())
+
+	r := report.NewReport("graph-modules", dirpath)
+	r.AddModuleGraph(graph)
+	for _, f := range rootPkgs.GetFindings() {
+		// Imports which are not resolved are missing from the graph
+		if f.Type == imports.FINDING_UNRESOLVED_RELATIVE_IMPORT {
+			r.AddFinding(f)
+		}
+	}
+	for _, e := range rootPkgs.GetParseErrors() {
+		r.Errors = append(r.Errors, &report.ParseError{Path: e.Path, Message: e.Err.Error()})
+	}
+
+	return graph, r, nil
+}
+
+func exportModuleGraph() {
+	ctx := context.Background()
+	graph, r, err := findModuleGraph(ctx, input_file)
+	if err != nil {
+		logger.Warnf("Error while building module graph %v", err)
+		return
+	}
+
+	format, _ := report.ParseFormat(output_format)
+	if isGraphFormat(format) {
+		if err := modulegraph.Write(os.Stdout, modulegraph.Format(format), graph); err != nil {
+			logger.Warnf("Error while writing module graph %v", err)
+		}
+		return
+	}
+	if writeReport(r) {
+		return
+	}
+
+	for _, node := range r.ModuleGraph.Nodes {
+		if node.Kind == string(modulegraph.NODE_PACKAGE) {
+			continue
+		}
+
+		imported := []string{}
+		for _, edge := range graph.GetImports(node.ID) {
+			name := edge.To
+			if edge.IsDeferred() {
+				name += " (deferred)"
+			}
+			imported = append(imported, name)
+		}
+		fmt.Printf("%s: %s\n", node.ID, strings.Join(imported, ", "))
+	}
+
+	for _, f := range r.Findings {
+		fmt.Printf("%s:%d %s\n", f.Path, f.LineStart, f.Message)
+	}
+	for _, e := range r.Errors {
+		fmt.Printf("%s %s\n", e.Path, e.Message)
+	}
+}
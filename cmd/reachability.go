@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"strings"
+
+	"github.com/safedep/codex/pkg/manifest/py/manifest"
+	"github.com/safedep/codex/pkg/reachability/py/reachability"
+	"github.com/safedep/codex/pkg/report"
+	"github.com/safedep/codex/pkg/utils/py/dir"
+	"github.com/safedep/dry/log"
+	"github.com/safedep/vet/pkg/common/logger"
+	"github.com/spf13/cobra"
+)
+
+var vulnerable_symbols []string
+var symbols_files []string
+
+var reachabilityCmd = &cobra.Command{
+	Use:   "reachability",
+	Short: "Find whether the code of the project can reach vulnerable functions",
+	Long: `Find whether the code of the project can reach vulnerable functions. Paths start from
+	the entry points of the project: __main__ blocks, console scripts, Flask, FastAPI and Django
+	handlers, and test functions. They go through the imports and the calls of the call graph.
+	For example:
+	go run main.go reachability --input <project_path> --symbol yaml.load --symbol jinja2.Template.render
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		log.Debugf("Running Reachability..")
+		findReachability()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(reachabilityCmd)
+
+	reachabilityCmd.Flags().StringVar(&input_file, "input", "", "Path of the project")
+	reachabilityCmd.MarkFlagRequired("input")
+	reachabilityCmd.Flags().StringSliceVar(&vulnerable_symbols, "symbol", []string{},
+		"Qualified names of the vulnerable functions, e.g. yaml.load or requests.sessions.Session.request")
+	reachabilityCmd.Flags().StringSliceVar(&symbols_files, "symbols-file", []string{},
+		"Files listing the qualified names of the vulnerable functions, one per line")
+	reachabilityCmd.Flags().StringSliceVar(&include_patterns, "include", []string{"**/*.py"},
+		"Glob patterns of the files to scan, relative to the input directory")
+	reachabilityCmd.Flags().StringSliceVar(&exclude_patterns, "exclude", []string{},
+		"Glob patterns of the files and directories to skip, relative to the input directory")
+	reachabilityCmd.Flags().BoolVar(&no_ignore, "no-ignore", false,
+		"Scan the files ignored by ignore files, virtualenvs and version control directories")
+	reachabilityCmd.Flags().IntVar(&concurrency, "concurrency", runtime.NumCPU(),
+		"Number of files parsed in parallel")
+}
+
+// readSymbols returns the symbols of the flags and of the files, skipping comments
+func readSymbols() ([]string, error) {
+	symbols := append([]string{}, vulnerable_symbols...)
+	for _, file := range symbols_files {
+		lines, err := dir.ReadAllLines(file)
+		if err != nil {
+			return nil, err
+		}
+		for _, line := range lines {
+			line, _, _ = strings.Cut(line, "#")
+			if line = strings.TrimSpace(line); line != "" {
+				symbols = append(symbols, line)
+			}
+		}
+	}
+
+	return symbols, nil
+}
+
+func findReachability() {
+	symbols, err := readSymbols()
+	if err != nil {
+		logger.Warnf("Error while reading symbols %v", err)
+		return
+	}
+	if len(symbols) == 0 {
+		logger.Warnf("No vulnerable symbol given, use --symbol or --symbols-file")
+		return
+	}
+
+	ctx := context.Background()
+	graph, repoSymbols, err := findCallGraph(ctx, input_file)
+	if err != nil {
+		logger.Warnf("Error while building call graph %v", err)
+		return
+	}
+
+	manifests, err := manifest.FindManifests(input_file)
+	if err != nil {
+		logger.Warnf("Error while finding manifests %v", err)
+		return
+	}
+
+	entryPoints := reachability.FindEntryPoints(graph, repoSymbols, manifest.GetConsoleScripts(manifests))
+	results := reachability.Analyze(graph, entryPoints, symbols)
+
+	r := report.NewReport("reachability", input_file)
+	r.AddReachability(entryPoints, results)
+	for _, e := range repoSymbols.Errors {
+		r.Errors = append(r.Errors, &report.ParseError{Path: e.Path, Message: e.Err.Error()})
+	}
+	if writeReport(r) {
+		return
+	}
+
+	fmt.Printf("Entry points: %d\n", len(entryPoints))
+	fmt.Println("Reachable:")
+	for _, s := range r.Reachability.Symbols {
+		if !s.Reachable {
+			continue
+		}
+		fmt.Printf("%s (%s %s)\n", s.Symbol, s.Match, s.Node)
+		fmt.Printf("  %s:%d %s [%s]\n", s.EntryPoint.Path, s.EntryPoint.Line, s.EntryPoint.Node, s.EntryPoint.Kind)
+		for _, step := range s.Path {
+			verb := "calls"
+			if step.Import {
+				verb = "imports"
+			}
+			fmt.Printf("  %s:%d %s %s %s\n", step.Path, step.Line, step.Caller, verb, step.Callee)
+		}
+	}
+
+	fmt.Println("Not Reachable:")
+	for _, s := range r.Reachability.Symbols {
+		if !s.Reachable {
+			fmt.Println(s.Symbol)
+		}
+	}
+
+	printParseErrors(repoSymbols.Errors)
+}
@@ -1,16 +1,22 @@
 /*
 Copyright © 2023 NAME HERE <EMAIL ADDRESS>
-
 */
 package cmd
 
 import (
+	"fmt"
 	"os"
+	"strings"
 
+	"github.com/safedep/codex/pkg/report"
 	"github.com/spf13/cobra"
 )
 
+var output_format string
 
+// EXTRA_FORMATS_ANNOTATION lists, separated by commas, the formats a command
+// supports besides the report formats, e.g. vet or the graph formats
+const EXTRA_FORMATS_ANNOTATION = "extra-formats"
 
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
@@ -25,6 +31,9 @@ to quickly create a Cobra application.`,
 	// Uncomment the following line if your bare application
 	// has an action associated with it:
 	// Run: func(cmd *cobra.Command, args []string) { },
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		return checkFormat(cmd)
+	},
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
@@ -42,10 +51,64 @@ func init() {
 	// will be global for your application.
 
 	// rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.codex.yaml)")
+	formats := []string{}
+	for _, format := range report.SupportedFormats() {
+		formats = append(formats, string(format))
+	}
+	rootCmd.PersistentFlags().StringVar(&output_format, "format", string(report.FORMAT_TEXT),
+		fmt.Sprintf("Output format (%s)", strings.Join(formats, ", ")))
 
 	// Cobra also supports local flags, which will only run
 	// when this action is called directly.
 	rootCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
 }
 
+// graphFormatNames returns the graph formats separated by commas
+func graphFormatNames() string {
+	names := []string{}
+	for _, format := range report.GraphFormats() {
+		names = append(names, string(format))
+	}
+	return strings.Join(names, ",")
+}
+
+func isGraphFormat(format report.Format) bool {
+	for _, f := range report.GraphFormats() {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
+// checkFormat rejects the --format values which the command does not support
+func checkFormat(cmd *cobra.Command) error {
+	format, err := report.ParseFormat(output_format)
+	if err != nil {
+		return err
+	}
+	if format != report.FORMAT_VET && !isGraphFormat(format) {
+		return nil
+	}
+
+	for _, extra := range strings.Split(cmd.Annotations[EXTRA_FORMATS_ANNOTATION], ",") {
+		if extra == string(format) {
+			return nil
+		}
+	}
+	return fmt.Errorf("format %s is not supported by %s", format, cmd.CommandPath())
+}
+
+// writeReport writes the report in the machine readable format of the --format flag.
+// It returns false when the text output of the command should be printed instead.
+func writeReport(r *report.Report) bool {
+	format, _ := report.ParseFormat(output_format)
+	if format == report.FORMAT_TEXT {
+		return false
+	}
 
+	if err := report.Write(os.Stdout, format, r); err != nil {
+		fmt.Fprintf(os.Stderr, "Error while writing report %v\n", err)
+	}
+	return true
+}
@@ -6,15 +6,39 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"os"
 	"path"
+	"runtime"
+	"strings"
 
+	"github.com/safedep/codex/pkg/exporter/py/vet"
+	"github.com/safedep/codex/pkg/manifest/py/manifest"
+	jsimports "github.com/safedep/codex/pkg/parser/js/imports"
 	"github.com/safedep/codex/pkg/parser/py/imports"
+	"github.com/safedep/codex/pkg/report"
+	"github.com/safedep/codex/pkg/utils/pathfilter"
+	"github.com/safedep/codex/pkg/utils/py/stdlib"
 	"github.com/safedep/dry/log"
 	"github.com/safedep/vet/pkg/common/logger"
 	"github.com/spf13/cobra"
 )
 
 var input_file string
+var python_version string
+var separate_optional bool
+var concurrency int
+var include_patterns []string
+var exclude_patterns []string
+var no_ignore bool
+var scope_names []string
+var scope_rules []string
+var language string
+
+// Languages of the projects scanned by find-direct-deps
+const (
+	LANGUAGE_PYTHON     = "python"
+	LANGUAGE_JAVASCRIPT = "javascript" // JavaScript and TypeScript
+)
 
 // scanCmd represents the scan command
 var scanCmd = &cobra.Command{
@@ -45,24 +69,28 @@ var cmdDirectDeps = &cobra.Command{
 	Long: `Find direct dependencies of the project based on imported modules, not just package file. 
 	For example:
 	go run main.go scan find-direct-deps --input <project_path>
+	go run main.go scan find-direct-deps --input <project_path> --language javascript
 
 	Imported Modules:
-	json
-	argparse
 	feedparser
 	mock
-	shlex
-	unittest
 	git
 	titlecase
 	nc
-	configparser
 
 	Exported Modules:
 	my_project
 
 
 `,
+	Annotations: map[string]string{EXTRA_FORMATS_ANNOTATION: string(report.FORMAT_VET)},
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		if output_format == string(report.FORMAT_VET) && language != LANGUAGE_PYTHON {
+			// The vet manifest resolves import names to PyPI distributions
+			return fmt.Errorf("format %s is not supported for %s", output_format, language)
+		}
+		return nil
+	},
 	Run: func(cmd *cobra.Command, args []string) {
 		log.Debugf("Running Direct Deps..")
 		findDirectDeps()
@@ -75,6 +103,35 @@ func init() {
 
 	scanCmd.PersistentFlags().StringVar(&input_file, "input", "", "Provide  Github Acc Name")
 	scanCmd.MarkPersistentFlagRequired("input")
+	scanCmd.PersistentFlags().StringVar(&python_version, "python-version", "",
+		fmt.Sprintf("Python version to classify standard library modules (%s), default is any Python 3",
+			strings.Join(stdlib.SupportedVersions(), ", ")))
+
+	scanCmd.PersistentFlags().StringSliceVar(&include_patterns, "include", []string{"**/*.py"},
+		"Glob patterns of the files to scan, relative to the input directory")
+	scanCmd.PersistentFlags().StringSliceVar(&exclude_patterns, "exclude", []string{},
+		"Glob patterns of the files and directories to skip, relative to the input directory")
+	scanCmd.PersistentFlags().BoolVar(&no_ignore, "no-ignore", false,
+		fmt.Sprintf("Scan the files ignored by %s, virtualenvs and %s",
+			strings.Join(pathfilter.IGNORE_FILES, ", "), strings.Join(pathfilter.DEFAULT_EXCLUDES, ", ")))
+	scanCmd.PersistentFlags().StringSliceVar(&scope_names, "scope", []string{},
+		"Only report dependencies of these scopes (runtime, build, test, dev), default is every scope")
+	scanCmd.PersistentFlags().StringSliceVar(&scope_rules, "scope-rule", []string{},
+		"Rules classifying files into scopes as <glob pattern>=<scope>, e.g. 'tools/**=dev', "+
+			"applied before the default rules")
+	scanCmd.PersistentFlags().IntVar(&concurrency, "concurrency", runtime.NumCPU(),
+		"Number of files parsed in parallel")
+
+	cmdDirectDeps.Flags().StringSliceVar(&site_packages_dirs, "site-packages", []string{},
+		"site-packages directories used to resolve import names to distributions and versions with --format vet")
+	cmdDirectDeps.Flags().StringSliceVar(&mapping_override_files, "mapping-overrides", []string{},
+		"Files mapping import names to distributions, one '<import name> <distribution>...' per line")
+	cmdDirectDeps.Flags().StringVar(&language, "language", LANGUAGE_PYTHON,
+		fmt.Sprintf("Language of the project (%s, %s), javascript includes TypeScript",
+			LANGUAGE_PYTHON, LANGUAGE_JAVASCRIPT))
+	cmdDirectDeps.Flags().BoolVar(&separate_optional, "separate-optional", false,
+		"Separate hard runtime dependencies from optional ones imported only in try/except ImportError, "+
+			"TYPE_CHECKING, version or platform gated blocks")
 
 	scanCmd.AddCommand(cmdDirectDeps)
 	scanCmd.AddCommand(cmdScanFile)
@@ -84,29 +141,241 @@ func init() {
 func findDirectDeps() {
 	filename := input_file
 
+	if language != LANGUAGE_PYTHON && language != LANGUAGE_JAVASCRIPT {
+		logger.Warnf("Unsupported language %s, supported languages are %s and %s",
+			language, LANGUAGE_PYTHON, LANGUAGE_JAVASCRIPT)
+		return
+	}
 	ctx := context.Background()
-	cf := imports.NewPyCodeParserFactory()
-	parser, err := cf.NewCodeParser()
-	if err != nil {
-		logger.Warnf("Error while creating parser %v", err)
+	var rootPkgs *imports.ImportedModules
+	var exportedModules *imports.ExportedModules
+	if language == LANGUAGE_JAVASCRIPT {
+		var err error
+		rootPkgs, exportedModules, err = findJsModules(ctx, filename)
+		if err != nil {
+			logger.Warnf("Error while finding imported modules %v", err)
+			return
+		}
+	} else {
+		pkgs, parser, err := findImportedModules(ctx, filename)
+		if err != nil {
+			logger.Warnf("Error while finding imported modules %v", err)
+			return
+		}
+
+		if output_format == string(report.FORMAT_VET) {
+			writeVetManifest(filename, pkgs)
+			return
+		}
+
+		rootPkgs = pkgs
+		exportedModules, _ = parser.FindExportedModules(ctx, filename)
+	}
+
+	r := report.NewReport("find-direct-deps", filename)
+	r.AddImportedModules(rootPkgs)
+	r.AddExportedModules(exportedModules)
+	if writeReport(r) {
 		return
 	}
-	includeExtensions := []string{".py"}
-	excludeDirs := []string{".git", "test"}
 
-	rootPkgs, _ := parser.FindImportedModules(ctx, filename, true, includeExtensions, excludeDirs)
-	fmt.Println("Imported Modules:")
-	for _, k := range rootPkgs.GetPackagesNames() {
-		fmt.Println(k)
+	if separate_optional {
+		printHardAndOptionalDeps(rootPkgs)
+	} else {
+		fmt.Println("Imported Modules:")
+		for _, k := range rootPkgs.GetPackagesNames() {
+			fmt.Println(k)
+		}
+	}
+
+	if unused := rootPkgs.GetUnusedDependencies(); len(unused) > 0 {
+		fmt.Println("Unused Dependencies:")
+		for _, k := range unused {
+			fmt.Println(k)
+		}
 	}
 
-	exportedModules, _ := parser.FindExportedModules(ctx, filename)
+	if findings := rootPkgs.GetFindings(); len(findings) > 0 {
+		fmt.Println("Findings:")
+		for _, f := range findings {
+			fmt.Printf("%s:%d %s\n", f.Path, f.Node.RowStart+1, f.Message)
+		}
+	}
+
+	printParseErrors(rootPkgs.GetParseErrors())
+
 	fmt.Println("Exported Modules:")
 	for _, k := range exportedModules.GetExportedModules() {
 		fmt.Println(k)
 	}
 }
 
+func printParseErrors(parseErrors []*imports.ParseError) {
+	if len(parseErrors) == 0 {
+		return
+	}
+
+	fmt.Println("Errors:")
+	for _, e := range parseErrors {
+		fmt.Printf("%s %v\n", e.Path, e.Err)
+	}
+}
+
+// writeVetManifest writes the imported packages as a vet package manifest
+func writeVetManifest(dirpath string, rootPkgs *imports.ImportedModules) {
+	resolver, err := newDistResolver()
+	if err != nil {
+		logger.Warnf("Error while creating resolver %v", err)
+		return
+	}
+
+	manifests, err := manifest.FindManifests(dirpath)
+	if err != nil {
+		logger.Warnf("Error while finding manifests %v", err)
+		return
+	}
+
+	pm := vet.NewPackageManifest(rootPkgs, vet.ExporterConfig{Path: dirpath,
+		Resolver:  resolver,
+		Manifests: manifests})
+	if err := vet.Write(os.Stdout, pm); err != nil {
+		logger.Warnf("Error while writing vet manifest %v", err)
+	}
+}
+
+func printHardAndOptionalDeps(rootPkgs *imports.ImportedModules) {
+	fmt.Println("Hard Dependencies:")
+	for _, k := range rootPkgs.GetHardDependencies() {
+		fmt.Println(k)
+	}
+
+	fmt.Println("Optional Dependencies:")
+	for _, k := range rootPkgs.GetOptionalDependencies() {
+		// Show why the package is optional
+		guards := []string{}
+		seen := map[imports.ImportGuard]bool{}
+		for _, prov := range rootPkgs.GetProvenance(k) {
+			for _, guard := range prov.Guards {
+				if !seen[guard] {
+					seen[guard] = true
+					guards = append(guards, string(guard))
+				}
+			}
+		}
+		fmt.Printf("%s (%s)\n", k, strings.Join(guards, ", "))
+	}
+}
+
+// findImportedModules creates a parser for the scan flags and finds the modules imported in the directory
+func findImportedModules(ctx context.Context, dirpath string) (*imports.ImportedModules, *imports.CodeParser, error) {
+	cf := imports.NewPyCodeParserFactory()
+	parser, err := cf.NewCodeParser()
+	if err != nil {
+		return nil, nil, fmt.Errorf("error while creating parser: %w", err)
+	}
+	err = parser.SetPythonVersion(python_version)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error while setting python version: %w", err)
+	}
+	err = parser.SetConcurrency(concurrency)
+	if err != nil {
+		return nil, nil, err
+	}
+	err = setupCache(parser)
+	if err != nil {
+		return nil, nil, err
+	}
+	err = setupScopes(parser)
+	if err != nil {
+		return nil, nil, err
+	}
+	parser.SetPathFilter(pathfilter.Config{Include: include_patterns,
+		Exclude:      exclude_patterns,
+		HonorIgnores: !no_ignore})
+	includeExtensions := []string{".py"}
+	excludeDirs := []string{}
+
+	// Files which fail to parse are reported along with the results
+	rootPkgs, err := parser.FindImportedModules(ctx, dirpath, false, includeExtensions, excludeDirs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return rootPkgs, parser, nil
+}
+
+// findJsModules creates a JavaScript and TypeScript parser for the scan flags and finds
+// the packages imported by the directory and the modules exported by its package.json
+func findJsModules(ctx context.Context, dirpath string) (*imports.ImportedModules, *imports.ExportedModules, error) {
+	parser, err := jsimports.NewJsCodeParserFactory().NewCodeParser()
+	if err != nil {
+		return nil, nil, fmt.Errorf("error while creating parser: %w", err)
+	}
+	err = parser.SetConcurrency(concurrency)
+	if err != nil {
+		return nil, nil, err
+	}
+	err = setupScopes(parser)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// The default patterns of --include select Python files
+	includes := include_patterns
+	if !scanCmd.PersistentFlags().Changed("include") {
+		includes = jsimports.DEFAULT_INCLUDES
+	}
+	parser.SetPathFilter(pathfilter.Config{Include: includes,
+		Exclude:      exclude_patterns,
+		HonorIgnores: !no_ignore})
+
+	// Files which fail to parse are reported along with the results
+	rootPkgs, err := parser.FindImportedModules(ctx, dirpath, false, jsimports.EXTENSIONS, []string{})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	exportedModules, err := parser.FindExportedModules(ctx, dirpath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return rootPkgs, exportedModules, nil
+}
+
+// scopedParser is a parser classifying the files into scopes
+type scopedParser interface {
+	SetScopeRules(rules []imports.ScopeRule) error
+	SetScopes(scopes []imports.DependencyScope)
+}
+
+// setupScopes sets the scope rules and the scopes of the reported dependencies
+func setupScopes(parser scopedParser) error {
+	rules := make([]imports.ScopeRule, 0)
+	for _, rule := range scope_rules {
+		scopeRule, err := imports.ParseScopeRule(rule)
+		if err != nil {
+			return err
+		}
+		rules = append(rules, scopeRule)
+	}
+	if err := parser.SetScopeRules(rules); err != nil {
+		return err
+	}
+
+	scopes := make([]imports.DependencyScope, 0)
+	for _, name := range scope_names {
+		scope, err := imports.ParseScope(name)
+		if err != nil {
+			return err
+		}
+		scopes = append(scopes, scope)
+	}
+	parser.SetScopes(scopes)
+
+	return nil
+}
+
 func scanFile() {
 	ctx := context.Background()
 	cf := imports.NewPyCodeParserFactory()
@@ -119,9 +388,46 @@ func scanFile() {
 	basePath, filename := path.Split(input_file)
 	parsedCode, err := parser.ParseFile(ctx, basePath, filename)
 	if err != nil {
-		panic(err)
+		logger.Warnf("Error while parsing file %v", err)
+		return
 	}
 
-	parsedCode.ExtractModules()
+	modules, err := parsedCode.ExtractModules()
+	if err != nil {
+		logger.Warnf("Error while extracting modules %v", err)
+		return
+	}
 	parsedCode.MakeMethodMap()
+
+	fileAnalysis := &imports.FileCodeAnalysis{Path: input_file,
+		Modules:  modules,
+		Findings: parsedCode.GetFindings()}
+
+	r := report.NewReport("file", input_file)
+	r.AddFileAnalysis(fileAnalysis)
+	if writeReport(r) {
+		return
+	}
+
+	fmt.Println("Imported Modules:")
+	for _, m := range r.Files[0].Modules {
+		name := m.Name
+		if m.Definition != "" && strings.HasSuffix(m.Name, ".") {
+			// Relative imports such as from . import views
+			name = m.Name + m.Definition
+		} else if m.Definition != "" {
+			name = fmt.Sprintf("%s.%s", m.Name, m.Definition)
+		}
+		if m.Alias != "" {
+			name = fmt.Sprintf("%s as %s", name, m.Alias)
+		}
+		fmt.Printf("%d %s\n", m.LineStart, name)
+	}
+
+	if len(r.Files[0].Findings) > 0 {
+		fmt.Println("Findings:")
+		for _, f := range r.Files[0].Findings {
+			fmt.Printf("%d %s\n", f.LineStart, f.Message)
+		}
+	}
 }
@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/safedep/codex/pkg/manifest/py/manifest"
+	"github.com/safedep/codex/pkg/report"
+	"github.com/safedep/codex/pkg/resolver/py/dist"
+	"github.com/safedep/dry/log"
+	"github.com/safedep/vet/pkg/common/logger"
+	"github.com/spf13/cobra"
+)
+
+var site_packages_dirs []string
+var mapping_override_files []string
+var drift_all_groups bool
+
+var cmdManifestDrift = &cobra.Command{
+	Use:   "manifest-drift",
+	Short: "Find dependencies declared in manifests but never imported, and imported but never declared",
+	Long: `Find dependencies declared in manifests but never imported, and imported but never declared.
+	Supported manifests are requirements*.txt, pyproject.toml (PEP 621 and Poetry), setup.cfg,
+	setup.py and Pipfile. Import names are resolved to distribution names using the bundled
+	mapping, the site-packages of the environment and user overrides. Only runtime dependencies
+	are reported as unused, unless --all-groups is set.
+	For example:
+	go run main.go scan manifest-drift --input <project_path> --site-packages .venv/lib/python3.11/site-packages
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		log.Debugf("Running Manifest Drift..")
+		findManifestDrift()
+	},
+}
+
+func init() {
+	cmdManifestDrift.Flags().StringSliceVar(&site_packages_dirs, "site-packages", []string{},
+		"site-packages directories used to resolve import names to distributions")
+	cmdManifestDrift.Flags().StringSliceVar(&mapping_override_files, "mapping-overrides", []string{},
+		"Files mapping import names to distributions, one '<import name> <distribution>...' per line")
+
+	cmdManifestDrift.Flags().BoolVar(&drift_all_groups, "all-groups", false,
+		"Report unused dependencies of every group, e.g. dev groups, extras and test requirements, not only runtime ones")
+
+	scanCmd.AddCommand(cmdManifestDrift)
+}
+
+func findManifestDrift() {
+	ctx := context.Background()
+
+	drift, err := buildManifestDrift(ctx, input_file)
+	if err != nil {
+		logger.Warnf("Error while finding manifest drift %v", err)
+		return
+	}
+
+	r := report.NewReport("manifest-drift", input_file)
+	r.AddManifestDrift(drift)
+	if writeReport(r) {
+		return
+	}
+
+	fmt.Println("Declared but not imported:")
+	for _, dep := range drift.Unused {
+		group := ""
+		if dep.Group != manifest.GROUP_RUNTIME {
+			group = " [" + dep.Group + "]"
+		}
+		fmt.Printf("%s%s (%s:%d)\n", dep.Name, group, dep.Manifest, dep.Line)
+	}
+
+	fmt.Println("Imported but not declared:")
+	for _, dep := range drift.Undeclared {
+		location := ""
+		if len(dep.Provenance) > 0 {
+			location = fmt.Sprintf(" (%s:%d)", dep.Provenance[0].Path, dep.Provenance[0].RowStart+1)
+		}
+		fmt.Printf("%s -> %s%s\n", dep.ImportName, dep.Resolution.Best().Distribution, location)
+	}
+}
+
+func buildManifestDrift(ctx context.Context, dirpath string) (*manifest.DriftReport, error) {
+	resolver, err := newDistResolver()
+	if err != nil {
+		return nil, err
+	}
+
+	manifests, err := manifest.FindManifests(dirpath)
+	if err != nil {
+		return nil, fmt.Errorf("error while finding manifests: %w", err)
+	}
+
+	rootPkgs, _, err := findImportedModules(ctx, dirpath)
+	if err != nil {
+		return nil, err
+	}
+
+	return manifest.FindDrift(manifests, rootPkgs, resolver, drift_all_groups), nil
+}
+
+func newDistResolver() (*dist.Resolver, error) {
+	return dist.NewResolver(dist.ResolverConfig{SitePackagesDirs: site_packages_dirs,
+		OverrideFiles: mapping_override_files})
+}
@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	"github.com/safedep/codex/pkg/sbom/py/cyclonedx"
+	"github.com/safedep/dry/log"
+	"github.com/safedep/vet/pkg/common/logger"
+	"github.com/spf13/cobra"
+)
+
+var cmdSbom = &cobra.Command{
+	Use:   "sbom",
+	Short: "Generate a CycloneDX 1.5 SBOM of the packages imported in the code",
+	Long: `Generate a CycloneDX 1.5 SBOM of the packages imported in the code. Every third-party
+	package is a component with the files and lines importing it as evidence occurrences.
+	The exported modules of the project are the root component.
+	For example:
+	go run main.go scan sbom --input <project_path> --site-packages .venv/lib/python3.11/site-packages > bom.json
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		log.Debugf("Running SBOM..")
+		generateSbom()
+	},
+}
+
+func init() {
+	cmdSbom.Flags().StringSliceVar(&site_packages_dirs, "site-packages", []string{},
+		"site-packages directories used to resolve import names to distributions and versions")
+	cmdSbom.Flags().StringSliceVar(&mapping_override_files, "mapping-overrides", []string{},
+		"Files mapping import names to distributions, one '<import name> <distribution>...' per line")
+
+	scanCmd.AddCommand(cmdSbom)
+}
+
+func generateSbom() {
+	ctx := context.Background()
+
+	rootPkgs, parser, err := findImportedModules(ctx, input_file)
+	if err != nil {
+		logger.Warnf("Error while finding imported modules %v", err)
+		return
+	}
+
+	exportedModules, err := parser.FindExportedModules(ctx, input_file)
+	if err != nil {
+		logger.Warnf("Error while finding exported modules %v", err)
+		return
+	}
+
+	resolver, err := newDistResolver()
+	if err != nil {
+		logger.Warnf("Error while creating resolver %v", err)
+		return
+	}
+
+	rootName := input_file
+	if absPath, err := filepath.Abs(input_file); err == nil {
+		rootName = filepath.Base(absPath)
+	}
+
+	bom, err := cyclonedx.NewBom(rootPkgs, exportedModules, cyclonedx.BomConfig{RootName: rootName,
+		Resolver: resolver})
+	if err != nil {
+		logger.Warnf("Error while generating SBOM %v", err)
+		return
+	}
+
+	if err := cyclonedx.Write(os.Stdout, bom); err != nil {
+		logger.Warnf("Error while writing SBOM %v", err)
+	}
+}
@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/safedep/codex/pkg/report"
+	"github.com/safedep/codex/pkg/usage/py/usage"
+	"github.com/safedep/dry/log"
+	"github.com/safedep/vet/pkg/common/logger"
+	"github.com/spf13/cobra"
+)
+
+var cmdApiUsage = &cobra.Command{
+	Use:   "api-usage",
+	Short: "List the symbols of every third-party package used by the code",
+	Long: `List the symbols of every third-party package used by the code: the modules and names
+	imported from the package and the functions called on them, with counts and locations.
+	For example:
+	go run main.go scan api-usage --input <project_path>
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		log.Debugf("Running API Usage..")
+		findApiUsage()
+	},
+}
+
+func init() {
+	scanCmd.AddCommand(cmdApiUsage)
+}
+
+func findApiUsage() {
+	ctx := context.Background()
+	rootPkgs, parser, err := findImportedModules(ctx, input_file)
+	if err != nil {
+		logger.Warnf("Error while finding imported modules %v", err)
+		return
+	}
+
+	// Files which fail to parse are already reported by the imported modules
+	repoSymbols, err := parser.FindSymbols(ctx, input_file, false, []string{".py"}, []string{})
+	if err != nil {
+		logger.Warnf("Error while finding symbols %v", err)
+		return
+	}
+
+	r := report.NewReport("api-usage", input_file)
+	r.AddApiUsage(usage.FindUsage(rootPkgs, repoSymbols))
+	for _, e := range rootPkgs.GetParseErrors() {
+		r.Errors = append(r.Errors, &report.ParseError{Path: e.Path, Message: e.Err.Error()})
+	}
+	if writeReport(r) {
+		return
+	}
+
+	for _, pkg := range r.ApiUsage {
+		fmt.Printf("%s:\n", pkg.Package)
+		for _, symbol := range pkg.Symbols {
+			locations := make([]string, 0, len(symbol.Locations))
+			for _, loc := range symbol.Locations {
+				locations = append(locations, fmt.Sprintf("%s:%d", loc.Path, loc.Line))
+			}
+			fmt.Printf("  %s (%s) %d: %s\n", symbol.Symbol, symbol.Kind, symbol.Count, strings.Join(locations, ", "))
+		}
+	}
+
+	printParseErrors(rootPkgs.GetParseErrors())
+}
@@ -0,0 +1,151 @@
+package architecture
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+	"github.com/safedep/codex/pkg/parser/py/imports"
+	"github.com/safedep/codex/pkg/utils/py/dir"
+)
+
+// Violation is an import breaking a contract
+type Violation struct {
+	Contract *Contract
+	Importer string // module of the importing file
+	Imported string // imported module matching the contract
+	Path     string
+	Node     imports.TypedValue // import statement, or the imported module when the statement is not known
+	Message  string
+}
+
+// Check evaluates the contracts against the imports of every analyzed file and
+// returns the violations sorted by file and row. Imports are matched by their
+// absolute module and by the names they import from it, so from myapp import web
+// matches myapp.web.
+func Check(dirpath string, repoAnalysis *imports.RepoCodeAnalysis, config *Config) []*Violation {
+	violations := make([]*Violation, 0)
+	seen := make(map[string]bool, 0)
+
+	for _, fa := range repoAnalysis.FilesAnalysis {
+		importer := moduleNameOf(dirpath, fa.Path)
+		for _, mod := range fa.Modules {
+			names := importedNamesOf(mod)
+			for _, contract := range config.Contracts {
+				imported, ok := contract.breaks(importer, fa.Path, names)
+				if !ok {
+					continue
+				}
+
+				// Every name of a statement is a module, report the statement once
+				v := &Violation{Contract: contract, Importer: importer, Imported: imported, Path: fa.Path,
+					Node: mod.Name}
+				if mod.Statement != nil {
+					v.Node = *mod.Statement
+				}
+				key := fmt.Sprintf("%s:%s:%d:%s", contract.Name, v.Path, v.Node.StartByte, v.Imported)
+				if seen[key] {
+					continue
+				}
+				seen[key] = true
+
+				v.Message = contract.messageOf(importer, imported)
+				violations = append(violations, v)
+			}
+		}
+	}
+
+	sort.SliceStable(violations, func(i, j int) bool {
+		if violations[i].Path != violations[j].Path {
+			return violations[i].Path < violations[j].Path
+		}
+		return violations[i].Node.StartByte < violations[j].Node.StartByte
+	})
+	return violations
+}
+
+// breaks tells whether an import of the file breaks the contract, and returns
+// the imported name matching the contract
+func (c *Contract) breaks(importer string, filepath string, names []string) (string, bool) {
+	imported, ok := matchAny(names, c.Imports)
+	if !ok {
+		return "", false
+	}
+
+	switch c.Type {
+	case CONTRACT_FORBIDDEN:
+		return imported, c.selects(importer, filepath)
+	case CONTRACT_EXCLUSIVE:
+		// Modules import their own submodules
+		if _, internal := matchAny([]string{importer}, c.Imports); internal {
+			return "", false
+		}
+		return imported, !c.selects(importer, filepath)
+	}
+
+	return "", false
+}
+
+// selects tells whether the importing file is selected by the modules or the paths of the contract
+func (c *Contract) selects(importer string, filepath string) bool {
+	if _, ok := matchAny([]string{importer}, c.Modules); ok {
+		return true
+	}
+	for _, pattern := range c.Paths {
+		if matched, _ := doublestar.Match(pattern, filepath); matched {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Contract) messageOf(importer string, imported string) string {
+	if c.Type == CONTRACT_FORBIDDEN {
+		return fmt.Sprintf("%s must not import %s (%s)", importer, imported, c.Name)
+	}
+
+	allowed := append(append([]string{}, c.Modules...), c.Paths...)
+	return fmt.Sprintf("%s may only be imported by %s, not by %s (%s)", imported,
+		strings.Join(allowed, ", "), importer, c.Name)
+}
+
+// matchAny returns the first name which is one of the modules or one of their submodules
+func matchAny(names []string, modules []string) (string, bool) {
+	for _, name := range names {
+		for _, module := range modules {
+			if name == module || strings.HasPrefix(name, module+".") {
+				return name, true
+			}
+		}
+	}
+	return "", false
+}
+
+// importedNamesOf returns the absolute module of an import, and the name it imports
+// from the module. Relative imports which are not resolved have no names.
+func importedNamesOf(mod *imports.ImportedModule) []string {
+	name := mod.AbsoluteName
+	if name == "" {
+		name = mod.Name.V
+	}
+	if name == "" || strings.HasPrefix(name, ".") {
+		return []string{}
+	}
+
+	names := []string{name}
+	if mod.Definition != nil && mod.Definition.V != "*" && !strings.HasSuffix(name, "."+mod.Definition.V) {
+		names = append(names, name+"."+mod.Definition.V)
+	}
+	return names
+}
+
+// moduleNameOf names a file after the packages of the repository, or after its
+// path when it is not part of a package
+func moduleNameOf(dirpath string, filepath string) string {
+	if name := dir.ModuleName(dirpath, filepath); name != "" {
+		return name
+	}
+	return strings.ReplaceAll(strings.TrimSuffix(filepath, path.Ext(filepath)), "/", ".")
+}
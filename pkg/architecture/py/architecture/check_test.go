@@ -0,0 +1,72 @@
+package architecture
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/safedep/codex/pkg/parser/py/imports"
+	"github.com/stretchr/testify/assert"
+)
+
+func checkFiles(t *testing.T, files map[string]string, contracts []*Contract) []string {
+	rootDir := t.TempDir()
+	for relPath, code := range files {
+		fullPath := path.Join(rootDir, relPath)
+		assert.NoError(t, os.MkdirAll(path.Dir(fullPath), os.ModePerm))
+		assert.NoError(t, os.WriteFile(fullPath, []byte(code), 0644))
+	}
+
+	parser, err := imports.NewPyCodeParserFactory().NewCodeParser()
+	assert.NoError(t, err)
+
+	importedModules, err := parser.FindImportedModules(context.Background(), rootDir, true, []string{".py"}, []string{})
+	assert.NoError(t, err)
+
+	messages := []string{}
+	for _, v := range Check(rootDir, importedModules.GetRepoCodeAnalysis(), &Config{Contracts: contracts}) {
+		messages = append(messages, fmt.Sprintf("%s:%d %s", v.Path, v.Node.RowStart+1, v.Imported))
+	}
+	return messages
+}
+
+var testFiles = map[string]string{
+	"myapp/__init__.py":        "",
+	"myapp/web/__init__.py":    "from myapp import domain\n",
+	"myapp/web/views.py":       "import django.http\nfrom .forms import Form\n",
+	"myapp/web/forms.py":       "",
+	"myapp/db/__init__.py":     "import sqlalchemy\nfrom sqlalchemy import orm, Column\n",
+	"myapp/domain/__init__.py": "",
+	"myapp/domain/models.py":   "from myapp.web import views\nfrom ..web.forms import Form\nimport sqlalchemy.orm\n",
+	"apps/admin.py":            "from django.contrib import admin\n",
+}
+
+func TestCheckForbidden(t *testing.T) {
+	assert.Equal(t, []string{
+		"myapp/domain/models.py:1 myapp.web.views",
+		"myapp/domain/models.py:2 myapp.web.forms",
+	}, checkFiles(t, testFiles, []*Contract{{Name: "domain", Type: CONTRACT_FORBIDDEN,
+		Modules: []string{"myapp.domain"}, Imports: []string{"myapp.web"}}}))
+}
+
+func TestCheckExclusive(t *testing.T) {
+	// Both names of the statement are reported once
+	assert.Equal(t, []string{
+		"myapp/domain/models.py:3 sqlalchemy.orm",
+	}, checkFiles(t, testFiles, []*Contract{{Name: "db", Type: CONTRACT_EXCLUSIVE,
+		Modules: []string{"myapp.db"}, Imports: []string{"sqlalchemy"}}}))
+
+	assert.Equal(t, []string{
+		"myapp/web/views.py:1 django.http",
+	}, checkFiles(t, testFiles, []*Contract{{Name: "django", Type: CONTRACT_EXCLUSIVE,
+		Paths: []string{"apps/**"}, Imports: []string{"django"}}}))
+
+	// Modules import their own submodules
+	assert.Equal(t, []string{
+		"myapp/domain/models.py:1 myapp.web.views",
+		"myapp/domain/models.py:2 myapp.web.forms",
+	}, checkFiles(t, testFiles, []*Contract{{Name: "web", Type: CONTRACT_EXCLUSIVE,
+		Modules: []string{"myapp.app"}, Imports: []string{"myapp.web"}}}))
+}
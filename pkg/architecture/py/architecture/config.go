@@ -0,0 +1,105 @@
+/*
+	Check the imports of a Python repository against architecture contracts
+*/
+
+package architecture
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"github.com/bmatcuk/doublestar/v4"
+	"gopkg.in/yaml.v3"
+)
+
+// Name of the contracts file looked up in the project directory
+const DEFAULT_CONFIG_FILE = "architecture.yaml"
+
+type ContractType string
+
+const (
+	// The selected modules must not import the listed modules
+	CONTRACT_FORBIDDEN ContractType = "forbidden"
+
+	// The listed modules may only be imported by the selected modules
+	CONTRACT_EXCLUSIVE ContractType = "exclusive"
+)
+
+// Contract is a rule on the imports between the modules of the repository and the
+// packages they use. Modules are dotted names matching themselves and their submodules,
+// and paths are doublestar patterns relative to the project directory. A file is
+// selected when its module matches one of the modules or its path one of the paths.
+type Contract struct {
+	Name    string       `yaml:"name"`
+	Type    ContractType `yaml:"type"`
+	Modules []string     `yaml:"modules"` // importing modules, e.g. myapp.domain
+	Paths   []string     `yaml:"paths"`   // importing files, e.g. apps/**
+	Imports []string     `yaml:"imports"` // imported modules or packages, e.g. myapp.web or sqlalchemy
+}
+
+// Config is the list of the contracts of a project, e.g.
+//
+//	contracts:
+//	  - name: The domain does not depend on the web layer
+//	    type: forbidden
+//	    modules: [myapp.domain]
+//	    imports: [myapp.web]
+//	  - name: Django is only used by the apps
+//	    type: exclusive
+//	    paths: ["apps/**"]
+//	    imports: [django]
+type Config struct {
+	Contracts []*Contract `yaml:"contracts"`
+}
+
+// LoadConfig reads and validates the contracts of a file
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseConfig(data)
+}
+
+// ParseConfig parses and validates contracts in YAML. Unknown fields are rejected
+// so that misspelled contracts are not silently ignored.
+func ParseConfig(data []byte) (*Config, error) {
+	config := &Config{}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(config); err != nil {
+		return nil, fmt.Errorf("invalid architecture config: %w", err)
+	}
+
+	for i, contract := range config.Contracts {
+		if contract.Name == "" {
+			contract.Name = fmt.Sprintf("contract %d", i+1)
+		}
+		if err := contract.validate(); err != nil {
+			return nil, fmt.Errorf("invalid contract %q: %w", contract.Name, err)
+		}
+	}
+
+	return config, nil
+}
+
+func (c *Contract) validate() error {
+	if c.Type != CONTRACT_FORBIDDEN && c.Type != CONTRACT_EXCLUSIVE {
+		return fmt.Errorf("type must be %s or %s, not %q", CONTRACT_FORBIDDEN, CONTRACT_EXCLUSIVE, c.Type)
+	}
+	if len(c.Imports) == 0 {
+		return fmt.Errorf("imports are missing")
+	}
+	if len(c.Modules) == 0 && len(c.Paths) == 0 {
+		return fmt.Errorf("modules or paths are missing")
+	}
+	for _, pattern := range c.Paths {
+		if !doublestar.ValidatePattern(pattern) {
+			return fmt.Errorf("invalid path pattern %q", pattern)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,40 @@
+package architecture
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseConfig(t *testing.T) {
+	config, err := ParseConfig([]byte(`
+contracts:
+  - name: The domain does not depend on the web layer
+    type: forbidden
+    modules: [myapp.domain]
+    imports: [myapp.web]
+  - type: exclusive
+    paths: ["apps/**"]
+    imports: [django]
+`))
+	assert.NoError(t, err)
+	assert.Equal(t, []*Contract{
+		{Name: "The domain does not depend on the web layer", Type: CONTRACT_FORBIDDEN,
+			Modules: []string{"myapp.domain"}, Imports: []string{"myapp.web"}},
+		{Name: "contract 2", Type: CONTRACT_EXCLUSIVE, Paths: []string{"apps/**"}, Imports: []string{"django"}},
+	}, config.Contracts)
+}
+
+func TestParseConfigInvalid(t *testing.T) {
+	invalid := map[string]string{
+		"unknown type":    "contracts:\n  - type: layers\n    modules: [a]\n    imports: [b]\n",
+		"no imports":      "contracts:\n  - type: forbidden\n    modules: [a]\n",
+		"no importers":    "contracts:\n  - type: exclusive\n    imports: [b]\n",
+		"invalid pattern": "contracts:\n  - type: exclusive\n    paths: [\"apps/[\"]\n    imports: [b]\n",
+		"unknown field":   "contracts:\n  - type: forbidden\n    module: [a]\n    imports: [b]\n",
+	}
+	for name, config := range invalid {
+		_, err := ParseConfig([]byte(config))
+		assert.Error(t, err, name)
+	}
+}
@@ -0,0 +1,223 @@
+/*
+	On-disk cache of analysis results, keyed by content hashes
+*/
+
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/safedep/dry/log"
+)
+
+// DEFAULT_MAX_SIZE is the default limit of the cache size in bytes
+const DEFAULT_MAX_SIZE int64 = 256 * 1024 * 1024
+
+const entryExtension = ".entry"
+
+// DiskCache stores entries as files under a directory. It is safe to use from
+// several goroutines and processes, entries are written atomically.
+type DiskCache struct {
+	dir     string
+	maxSize int64
+}
+
+// DefaultDir returns the codex directory in the user cache directory
+func DefaultDir() (string, error) {
+	userCacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(userCacheDir, "codex"), nil
+}
+
+func NewDiskCache(dir string, maxSize int64) (*DiskCache, error) {
+	if maxSize <= 0 {
+		return nil, fmt.Errorf("cache size limit must be positive, got %d", maxSize)
+	}
+
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		return nil, fmt.Errorf("error while creating cache directory: %w", err)
+	}
+
+	return &DiskCache{dir: dir, maxSize: maxSize}, nil
+}
+
+// Key hashes the parts identifying an entry
+func Key(parts ...[]byte) string {
+	h := sha256.New()
+	for _, part := range parts {
+		// Prefix every part with its length so that parts can not run into each other
+		fmt.Fprintf(h, "%d:", len(part))
+		h.Write(part)
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Get returns the entry of the key, if any
+func (c *DiskCache) Get(key string) ([]byte, bool) {
+	path := c.entryPath(key)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+
+	// The modification time orders the entries when the cache is pruned
+	now := time.Now()
+	_ = os.Chtimes(path, now, now)
+
+	return data, true
+}
+
+// Put stores the entry of the key
+func (c *DiskCache) Put(key string, data []byte) error {
+	path := c.entryPath(key)
+	err := os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		return err
+	}
+
+	// Write to a temporary file first so that readers never see a partial entry
+	tmp, err := os.CreateTemp(filepath.Dir(path), "tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// Prune removes the least recently used entries until the cache fits its size limit
+func (c *DiskCache) Prune() error {
+	type entry struct {
+		path    string
+		size    int64
+		modTime time.Time
+	}
+
+	entries := make([]entry, 0)
+	var total int64
+	err := filepath.WalkDir(c.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || filepath.Ext(path) != entryExtension {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			// The entry may be removed by another process
+			return nil
+		}
+		entries = append(entries, entry{path: path, size: info.Size(), modTime: info.ModTime()})
+		total += info.Size()
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if total <= c.maxSize {
+		return nil
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].modTime.Before(entries[j].modTime)
+	})
+
+	removed := 0
+	for _, e := range entries {
+		if total <= c.maxSize {
+			break
+		}
+		if err := os.Remove(e.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		total -= e.size
+		removed += 1
+	}
+
+	log.Debugf("Pruned %d cache entries from %s", removed, c.dir)
+	return nil
+}
+
+// Clean removes the entries of the cache directory. Only the files written by the
+// cache are removed, with the subdirectories they leave empty, so that cleaning a
+// directory shared with other files never removes those files.
+func Clean(dir string) error {
+	shards, err := os.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, shard := range shards {
+		if !shard.IsDir() || !isShardName(shard.Name()) {
+			continue
+		}
+
+		shardDir := filepath.Join(dir, shard.Name())
+		files, err := os.ReadDir(shardDir)
+		if err != nil {
+			return err
+		}
+		for _, f := range files {
+			if f.IsDir() || !isEntryName(f.Name()) {
+				continue
+			}
+			if err := os.Remove(filepath.Join(shardDir, f.Name())); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return err
+			}
+		}
+
+		// Subdirectories still holding other files are kept
+		_ = os.Remove(shardDir)
+	}
+
+	_ = os.Remove(dir)
+	return nil
+}
+
+// isShardName tells whether a directory name is the first byte of a key in hex
+func isShardName(name string) bool {
+	_, err := hex.DecodeString(name)
+	return len(name) == 2 && err == nil
+}
+
+// isEntryName tells whether a file name is an entry, or the temporary file of an
+// entry being written
+func isEntryName(name string) bool {
+	if filepath.Ext(name) == entryExtension {
+		_, err := hex.DecodeString(strings.TrimSuffix(name, entryExtension))
+		return err == nil
+	}
+	return strings.HasPrefix(name, "tmp-")
+}
+
+// entryPath spreads the entries over subdirectories named after the first byte of the key
+func (c *DiskCache) entryPath(key string) string {
+	return filepath.Join(c.dir, key[:2], key+entryExtension)
+}
@@ -0,0 +1,99 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiskCache(t *testing.T) {
+	c, err := NewDiskCache(t.TempDir(), DEFAULT_MAX_SIZE)
+	assert.NoError(t, err)
+
+	key := Key([]byte("a"), []byte("b"))
+	_, ok := c.Get(key)
+	assert.False(t, ok)
+
+	assert.NoError(t, c.Put(key, []byte("value")))
+	data, ok := c.Get(key)
+	assert.True(t, ok)
+	assert.Equal(t, []byte("value"), data)
+
+	assert.NoError(t, c.Put(key, []byte("other")))
+	data, _ = c.Get(key)
+	assert.Equal(t, []byte("other"), data)
+}
+
+func TestKey(t *testing.T) {
+	assert.Equal(t, Key([]byte("ab"), []byte("c")), Key([]byte("ab"), []byte("c")))
+	assert.NotEqual(t, Key([]byte("ab"), []byte("c")), Key([]byte("a"), []byte("bc")))
+	assert.Equal(t, 64, len(Key([]byte("a"))))
+}
+
+func TestPrune(t *testing.T) {
+	c, err := NewDiskCache(t.TempDir(), 25)
+	assert.NoError(t, err)
+
+	keys := []string{Key([]byte("1")), Key([]byte("2")), Key([]byte("3"))}
+	for i, key := range keys {
+		assert.NoError(t, c.Put(key, []byte("0123456789")))
+
+		// Oldest entry first
+		modTime := time.Now().Add(time.Duration(i-10) * time.Minute)
+		assert.NoError(t, os.Chtimes(c.entryPath(key), modTime, modTime))
+	}
+
+	assert.NoError(t, c.Prune())
+
+	_, err = os.Stat(c.entryPath(keys[0]))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+	for _, key := range keys[1:] {
+		_, err = os.Stat(c.entryPath(key))
+		assert.NoError(t, err)
+	}
+}
+
+func TestClean(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "codex")
+	c, err := NewDiskCache(dir, DEFAULT_MAX_SIZE)
+	assert.NoError(t, err)
+	assert.NoError(t, c.Put(Key([]byte("1")), []byte("value")))
+
+	assert.NoError(t, Clean(dir))
+	_, err = os.Stat(dir)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	_, err = NewDiskCache(dir, 0)
+	assert.Error(t, err)
+	assert.NoError(t, Clean(dir))
+}
+
+func TestCleanSharedDir(t *testing.T) {
+	dir := t.TempDir()
+	c, err := NewDiskCache(dir, DEFAULT_MAX_SIZE)
+	assert.NoError(t, err)
+	key := Key([]byte("1"))
+	assert.NoError(t, c.Put(key, []byte("value")))
+	assert.NoError(t, c.Put(Key([]byte("2")), []byte("value")))
+
+	notes := filepath.Join(dir, "notes.txt")
+	assert.NoError(t, os.WriteFile(notes, []byte("keep"), 0644))
+	shardFile := filepath.Join(dir, key[:2], "keep.txt")
+	assert.NoError(t, os.WriteFile(shardFile, []byte("keep"), 0644))
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "src"), 0755))
+
+	assert.NoError(t, Clean(dir))
+	for _, path := range []string{notes, shardFile, filepath.Join(dir, "src")} {
+		_, err = os.Stat(path)
+		assert.NoError(t, err, path)
+	}
+	_, ok := c.Get(key)
+	assert.False(t, ok)
+
+	entries, err := os.ReadDir(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(entries))
+}
@@ -0,0 +1,293 @@
+package callgraph
+
+import (
+	"path"
+	"strings"
+
+	"github.com/safedep/codex/pkg/parser/py/imports"
+	"github.com/safedep/codex/pkg/utils/py/dir"
+	"github.com/safedep/dry/log"
+)
+
+// Re-exports are followed up to this number of modules
+const MAX_RESOLVE_DEPTH = 10
+
+type moduleInfo struct {
+	name        string
+	isPackage   bool // __init__.py of a package
+	symbols     *imports.FileSymbols
+	definitions map[string]string // top-level functions and classes to their IDs
+}
+
+type classInfo struct {
+	id      string
+	bases   []string          // IDs of the base classes defined in the repository
+	methods map[string]string // method names to their IDs
+}
+
+type builder struct {
+	graph   *Graph
+	modules map[string]*moduleInfo
+	classes map[string]*classInfo
+}
+
+// Build links the calls of the files of a repository to the functions they call.
+// Calls are resolved through the definitions of the file, the methods of the
+// enclosing class for self and cls, and the import bindings of the file, following
+// re-exports of packages. Calls of functions of other packages are linked to
+// NODE_EXTERNAL nodes named after the imported names, e.g. requests.get.
+func Build(rootDir string, repoSymbols *imports.RepoSymbols) *Graph {
+	b := &builder{graph: NewGraph(),
+		modules: make(map[string]*moduleInfo, 0),
+		classes: make(map[string]*classInfo, 0)}
+
+	// Index the definitions of every file, then resolve the base classes and the calls
+	modules := make([]*moduleInfo, 0, len(repoSymbols.Files))
+	for _, symbols := range repoSymbols.Files {
+		modules = append(modules, b.addModule(rootDir, symbols))
+	}
+	for _, mod := range modules {
+		b.resolveBases(mod)
+	}
+	for _, mod := range modules {
+		b.resolveImports(mod)
+		b.resolveCalls(mod)
+	}
+
+	return b.graph
+}
+
+func (b *builder) addModule(rootDir string, symbols *imports.FileSymbols) *moduleInfo {
+	name := dir.ModuleName(rootDir, symbols.Path)
+	if _, exists := b.modules[name]; exists || name == "" {
+		// Scripts of different directories may have the same name
+		name = strings.ReplaceAll(strings.TrimSuffix(symbols.Path, path.Ext(symbols.Path)), "/", ".")
+	}
+
+	mod := &moduleInfo{name: name,
+		isPackage:   path.Base(symbols.Path) == "__init__.py",
+		symbols:     symbols,
+		definitions: make(map[string]string, 0)}
+	b.modules[name] = mod
+
+	b.graph.AddNode(&Node{ID: name, Kind: NODE_MODULE,
+		Key: imports.MethodMapKey{Path: symbols.Path, Name: imports.MODULE_SCOPE}})
+
+	for _, class := range symbols.Classes {
+		id := name + "." + class.Name
+		b.classes[id] = &classInfo{id: id, methods: make(map[string]string, 0)}
+		if !strings.Contains(class.Name, ".") {
+			mod.definitions[class.Name] = id
+		}
+	}
+
+	for _, function := range symbols.Functions {
+		node := b.graph.AddNode(&Node{ID: qualifiedName(name, function.Key),
+			Kind: NODE_FUNCTION,
+			Key:  function.Key,
+			Row:  function.Node.RowStart})
+
+		if function.Key.ClassName != "" {
+			node.Kind = NODE_METHOD
+			if class, ok := b.classes[name+"."+function.Key.ClassName]; ok {
+				class.methods[function.Key.Name] = node.ID
+			}
+		} else if !strings.Contains(function.Key.Name, ".") {
+			mod.definitions[function.Key.Name] = node.ID
+		}
+	}
+
+	return mod
+}
+
+func (b *builder) resolveBases(mod *moduleInfo) {
+	for _, class := range mod.symbols.Classes {
+		info := b.classes[mod.name+"."+class.Name]
+		for _, base := range class.Bases {
+			if id, external, ok := b.resolveInModule(mod, base, 0); ok && !external {
+				if _, isClass := b.classes[id]; isClass {
+					info.bases = append(info.bases, id)
+				}
+			}
+		}
+	}
+}
+
+// resolveImports links the module to the modules of the repository it imports.
+// Importing a module runs its packages too, e.g. app and app.utils for app.utils.helper.
+func (b *builder) resolveImports(mod *moduleInfo) {
+	for _, binding := range mod.symbols.ImportBindings {
+		parts := strings.Split(b.absoluteName(mod, binding.Imported), ".")
+		for i := 1; i <= len(parts); i++ {
+			name := strings.Join(parts[:i], ".")
+			if _, ok := b.modules[name]; !ok || name == mod.name {
+				continue
+			}
+			b.graph.AddImport(&Edge{Caller: mod.name, Callee: name,
+				Path:      mod.symbols.Path,
+				Row:       binding.Node.RowStart,
+				Column:    binding.Node.ColumnStart,
+				ColumnEnd: binding.Node.ColumnEnd,
+				StartByte: binding.Node.StartByte,
+				EndByte:   binding.Node.EndByte})
+		}
+	}
+}
+
+func (b *builder) resolveCalls(mod *moduleInfo) {
+	for _, call := range mod.symbols.Calls {
+		caller := mod.name
+		if call.Caller.Name != imports.MODULE_SCOPE {
+			caller = qualifiedName(mod.name, call.Caller)
+		}
+
+		callee, ok := b.resolveCall(mod, call)
+		if !ok {
+			log.Debugf("Unresolved call of %s in %s", call.Callee, mod.symbols.Path)
+			b.graph.unresolved = append(b.graph.unresolved, call)
+			continue
+		}
+
+		b.graph.AddEdge(&Edge{Caller: caller, Callee: callee,
+			Path:      mod.symbols.Path,
+			Row:       call.Node.RowStart,
+			Column:    call.Node.ColumnStart,
+			ColumnEnd: call.Node.ColumnEnd,
+			StartByte: call.Node.StartByte,
+			EndByte:   call.Node.EndByte})
+	}
+}
+
+// resolveCall returns the ID of the node called by the call site
+func (b *builder) resolveCall(mod *moduleInfo, call *imports.CallSite) (string, bool) {
+	parts := strings.Split(call.Callee, ".")
+
+	// Methods of the enclosing class
+	if (parts[0] == "self" || parts[0] == "cls") && len(parts) == 2 && call.Caller.ClassName != "" {
+		if class, ok := b.classes[mod.name+"."+call.Caller.ClassName]; ok {
+			return b.findMethod(class, parts[1], 0)
+		}
+		return "", false
+	}
+
+	// Functions nested in the caller
+	if len(parts) == 1 && call.Caller.Name != imports.MODULE_SCOPE {
+		nested := qualifiedName(mod.name, call.Caller) + "." + parts[0]
+		if _, ok := b.graph.GetNode(nested); ok {
+			return nested, true
+		}
+	}
+
+	id, external, ok := b.resolveInModule(mod, call.Callee, 0)
+	if !ok {
+		return "", false
+	}
+	if external {
+		b.graph.AddNode(&Node{ID: id, Kind: NODE_EXTERNAL})
+		return id, true
+	}
+
+	// Instantiating a class calls its constructor
+	if class, ok := b.classes[id]; ok {
+		return b.findMethod(class, "__init__", 0)
+	}
+
+	if node, ok := b.graph.GetNode(id); ok && node.Kind != NODE_MODULE {
+		return id, true
+	}
+	return "", false
+}
+
+// resolveInModule resolves a dotted name used in a module to the ID of a function
+// or a class of the repository, or to the qualified name of an external function
+func (b *builder) resolveInModule(mod *moduleInfo, name string, depth int) (string, bool, bool) {
+	first, rest, _ := strings.Cut(name, ".")
+
+	if id, ok := mod.definitions[first]; ok {
+		return b.resolveMember(id, rest)
+	}
+
+	if imported, ok := mod.symbols.Imports[first]; ok {
+		qualified := b.absoluteName(mod, imported)
+		if rest != "" {
+			qualified += "." + rest
+		}
+		return b.resolveQualified(qualified, depth+1)
+	}
+
+	return "", false, false
+}
+
+// resolveQualified resolves a fully qualified name, e.g. pkg.utils.helper
+func (b *builder) resolveQualified(qualified string, depth int) (string, bool, bool) {
+	if depth > MAX_RESOLVE_DEPTH {
+		return "", false, false
+	}
+
+	// The longest module of the repository the name starts with
+	parts := strings.Split(qualified, ".")
+	for i := len(parts); i > 0; i-- {
+		mod, ok := b.modules[strings.Join(parts[:i], ".")]
+		if !ok {
+			continue
+		}
+		if i == len(parts) {
+			// Modules are not callable
+			return "", false, false
+		}
+		return b.resolveInModule(mod, strings.Join(parts[i:], "."), depth)
+	}
+
+	// Names of modules which are not in the repository
+	return qualified, true, true
+}
+
+// resolveMember resolves the attributes of a function or a class, e.g. Class.method
+func (b *builder) resolveMember(id string, rest string) (string, bool, bool) {
+	if rest == "" {
+		return id, false, true
+	}
+
+	class, ok := b.classes[id]
+	if !ok || strings.Contains(rest, ".") {
+		return "", false, false
+	}
+
+	method, ok := b.findMethod(class, rest, 0)
+	return method, false, ok
+}
+
+// findMethod finds a method in the class or in its base classes
+func (b *builder) findMethod(class *classInfo, name string, depth int) (string, bool) {
+	if id, ok := class.methods[name]; ok {
+		return id, true
+	}
+	if depth > MAX_RESOLVE_DEPTH {
+		return "", false
+	}
+
+	for _, base := range class.bases {
+		if id, ok := b.findMethod(b.classes[base], name, depth+1); ok {
+			return id, true
+		}
+	}
+	return "", false
+}
+
+// absoluteName resolves a name imported relatively to the module, e.g. ..utils.helper
+func (b *builder) absoluteName(mod *moduleInfo, name string) string {
+	absolute, err := dir.ResolveRelativeName(mod.name, mod.isPackage, name)
+	if err != nil {
+		// Beyond the top-level package, the name is only known without its dots
+		return strings.TrimLeft(name, ".")
+	}
+	return absolute
+}
+
+// qualifiedName returns the ID of the function defined with the key in the module
+func qualifiedName(module string, key imports.MethodMapKey) string {
+	if key.ClassName != "" {
+		return module + "." + key.ClassName + "." + key.Name
+	}
+	return module + "." + key.Name
+}
@@ -0,0 +1,121 @@
+package callgraph
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/safedep/codex/pkg/parser/py/imports"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestGraph(t *testing.T, files map[string]string) *Graph {
+	rootDir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(rootDir, name)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		assert.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+
+	parser, err := imports.NewPyCodeParserFactory().NewCodeParser()
+	assert.NoError(t, err)
+
+	repoSymbols, err := parser.FindSymbols(context.Background(), rootDir, true, []string{".py"}, []string{})
+	assert.NoError(t, err)
+
+	return Build(rootDir, repoSymbols)
+}
+
+func calleesOf(g *Graph, id string) []string {
+	callees := make([]string, 0)
+	for _, edge := range g.GetCallees(id) {
+		callees = append(callees, edge.Callee)
+	}
+	return callees
+}
+
+func TestBuild(t *testing.T) {
+	g := newTestGraph(t, map[string]string{
+		"app/__init__.py": "from .utils import helper\n",
+		"app/utils.py":    "import requests\n\ndef helper():\n    return requests.get('https://example.com')\n",
+		"app/models.py": `class Base:
+    def save(self):
+        pass
+
+class User(Base):
+    def __init__(self, name):
+        self.name = name
+
+    def rename(self, name):
+        self.name = name
+        self.save()
+`,
+		"app/main.py": `import app.utils
+from app import helper
+from app.models import User as U
+from . import models
+
+def main():
+    def inner():
+        app.utils.helper()
+    inner()
+    helper()
+    user = U("me")
+    user.rename("you")
+    models.User.rename(user, "them")
+    print(user)
+
+main()
+`,
+	})
+
+	assert.Equal(t, []string{"app.main.main"}, calleesOf(g, "app.main"))
+	assert.Equal(t, []string{"app.main.main.inner", "app.models.User.__init__", "app.models.User.rename",
+		"app.utils.helper"}, calleesOf(g, "app.main.main"))
+	assert.Equal(t, []string{"app.utils.helper"}, calleesOf(g, "app.main.main.inner"))
+	assert.Equal(t, []string{"app.models.Base.save"}, calleesOf(g, "app.models.User.rename"))
+	assert.Equal(t, []string{"requests.get"}, calleesOf(g, "app.utils.helper"))
+
+	node, ok := g.GetNode("requests.get")
+	assert.True(t, ok)
+	assert.Equal(t, NODE_EXTERNAL, node.Kind)
+
+	node, ok = g.GetNode("app.models.User.rename")
+	assert.True(t, ok)
+	assert.Equal(t, NODE_METHOD, node.Kind)
+	assert.Equal(t, imports.MethodMapKey{Path: "app/models.py", ClassName: "User", Name: "rename"}, node.Key)
+	assert.Equal(t, uint32(8), node.Row)
+
+	node, ok = g.GetNodeByKey(imports.MethodMapKey{Path: "app/utils.py", Name: "helper"})
+	assert.True(t, ok)
+	assert.Equal(t, "app.utils.helper", node.ID)
+	callers := g.GetCallers(node.ID)
+	assert.Equal(t, 2, len(callers))
+	assert.Equal(t, &Edge{Caller: "app.main.main", Callee: "app.utils.helper", Path: "app/main.py", Row: 9, Column: 4,
+		ColumnEnd: 12, StartByte: 167, EndByte: 175}, callers[0])
+	assert.Equal(t, "app.main.main.inner", callers[1].Caller)
+
+	imported := make([]string, 0)
+	for _, edge := range g.GetImports("app.main") {
+		imported = append(imported, edge.Callee)
+	}
+	assert.Equal(t, []string{"app", "app", "app", "app", "app.models", "app.models", "app.utils"}, imported)
+
+	unresolved := make([]string, 0)
+	for _, call := range g.GetUnresolvedCalls() {
+		unresolved = append(unresolved, call.Callee)
+	}
+	assert.Equal(t, []string{"user.rename", "print"}, unresolved)
+}
+
+func TestBuildScripts(t *testing.T) {
+	g := newTestGraph(t, map[string]string{
+		"scripts/run.py": "def run():\n    pass\n\nrun()\n",
+		"tools/run.py":   "from helpers import setup\n\nsetup()\n",
+		"helpers.py":     "def setup():\n    pass\n",
+	})
+
+	assert.Equal(t, []string{"run.run"}, calleesOf(g, "run"))
+	assert.Equal(t, []string{"helpers.setup"}, calleesOf(g, "tools.run"))
+}
@@ -0,0 +1,175 @@
+/*
+	Build the call graph of a Python repository
+*/
+
+package callgraph
+
+import (
+	"sort"
+
+	"github.com/safedep/codex/pkg/parser/py/imports"
+)
+
+type NodeKind string
+
+const (
+	NODE_FUNCTION NodeKind = "function"
+	NODE_METHOD   NodeKind = "method"
+	NODE_MODULE   NodeKind = "module"   // code run at module level
+	NODE_EXTERNAL NodeKind = "external" // function of another package, e.g. requests.get
+)
+
+// Node is a function of the call graph
+type Node struct {
+	ID   string // qualified name, e.g. pkg.mod.Class.method
+	Kind NodeKind
+	Key  imports.MethodMapKey // definition of the function, empty for external functions
+	Row  uint32               // row of the definition (0 based)
+}
+
+// Edge is a call from a function to another one. Import edges link the module
+// level node of the importing file to the node of the imported module.
+type Edge struct {
+	Caller    string // ID of the calling node
+	Callee    string // ID of the called node
+	Path      string // file of the call
+	Row       uint32 // row of the call (0 based)
+	Column    uint32 // column of the call (0 based, in unicode code points)
+	ColumnEnd uint32 // column after the end of the call (0 based, in unicode code points)
+	StartByte uint32 // offset of the call in the file
+	EndByte   uint32 // offset after the call in the file
+}
+
+// Graph is a call graph. The imports between the modules of the repository are
+// kept apart from the calls, and so are the calls which are not resolved to a
+// node, such as calls of builtins or of methods of local variables.
+type Graph struct {
+	nodes      map[string]*Node
+	keys       map[imports.MethodMapKey]*Node
+	callees    map[string][]*Edge
+	callers    map[string][]*Edge
+	imports    map[string][]*Edge
+	unresolved []*imports.CallSite
+}
+
+func NewGraph() *Graph {
+	return &Graph{nodes: make(map[string]*Node, 0),
+		keys:    make(map[imports.MethodMapKey]*Node, 0),
+		callees: make(map[string][]*Edge, 0),
+		callers: make(map[string][]*Edge, 0),
+		imports: make(map[string][]*Edge, 0)}
+}
+
+// AddNode adds the node unless a node has the same ID, and returns the node of the graph
+func (g *Graph) AddNode(node *Node) *Node {
+	if existing, ok := g.nodes[node.ID]; ok {
+		return existing
+	}
+
+	g.nodes[node.ID] = node
+	if node.Kind != NODE_EXTERNAL {
+		g.keys[node.Key] = node
+	}
+	return node
+}
+
+// AddEdge adds a call between two nodes of the graph
+func (g *Graph) AddEdge(edge *Edge) {
+	for _, e := range g.callees[edge.Caller] {
+		if *e == *edge {
+			return
+		}
+	}
+
+	g.callees[edge.Caller] = append(g.callees[edge.Caller], edge)
+	g.callers[edge.Callee] = append(g.callers[edge.Callee], edge)
+}
+
+// AddImport adds an import of a module of the repository by another one
+func (g *Graph) AddImport(edge *Edge) {
+	for _, e := range g.imports[edge.Caller] {
+		if *e == *edge {
+			return
+		}
+	}
+
+	g.imports[edge.Caller] = append(g.imports[edge.Caller], edge)
+}
+
+// GetImports returns the imports of modules of the repository by the module
+func (g *Graph) GetImports(id string) []*Edge {
+	edges := append([]*Edge{}, g.imports[id]...)
+	sortEdges(edges)
+	return edges
+}
+
+// GetNode returns the node with the ID
+func (g *Graph) GetNode(id string) (*Node, bool) {
+	node, ok := g.nodes[id]
+	return node, ok
+}
+
+// GetNodeByKey returns the node of the function defined with the key
+func (g *Graph) GetNodeByKey(key imports.MethodMapKey) (*Node, bool) {
+	node, ok := g.keys[key]
+	return node, ok
+}
+
+// GetNodes returns the nodes sorted by ID
+func (g *Graph) GetNodes() []*Node {
+	nodes := make([]*Node, 0, len(g.nodes))
+	for _, node := range g.nodes {
+		nodes = append(nodes, node)
+	}
+
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].ID < nodes[j].ID
+	})
+	return nodes
+}
+
+// GetEdges returns every call sorted by caller, callee and location
+func (g *Graph) GetEdges() []*Edge {
+	edges := make([]*Edge, 0)
+	for _, callees := range g.callees {
+		edges = append(edges, callees...)
+	}
+
+	sortEdges(edges)
+	return edges
+}
+
+// GetCallees returns the calls made by the node
+func (g *Graph) GetCallees(id string) []*Edge {
+	edges := append([]*Edge{}, g.callees[id]...)
+	sortEdges(edges)
+	return edges
+}
+
+// GetCallers returns the calls of the node
+func (g *Graph) GetCallers(id string) []*Edge {
+	edges := append([]*Edge{}, g.callers[id]...)
+	sortEdges(edges)
+	return edges
+}
+
+// GetUnresolvedCalls returns the calls which are not resolved to a node
+func (g *Graph) GetUnresolvedCalls() []*imports.CallSite {
+	return g.unresolved
+}
+
+func sortEdges(edges []*Edge) {
+	sort.Slice(edges, func(i, j int) bool {
+		a, b := edges[i], edges[j]
+		if a.Caller != b.Caller {
+			return a.Caller < b.Caller
+		}
+		if a.Callee != b.Callee {
+			return a.Callee < b.Callee
+		}
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		return a.Row < b.Row
+	})
+}
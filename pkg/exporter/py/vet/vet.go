@@ -0,0 +1,109 @@
+/*
+	Export the imported packages as a safedep/vet package manifest
+*/
+
+package vet
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/safedep/codex/pkg/manifest/py/manifest"
+	"github.com/safedep/codex/pkg/parser/py/imports"
+	"github.com/safedep/codex/pkg/resolver/py/dist"
+	"github.com/safedep/vet/pkg/models"
+)
+
+type ExporterConfig struct {
+	// Path of the scanned directory, used as the manifest path
+	Path string
+
+	// Resolver of import names to distributions
+	Resolver *dist.Resolver
+
+	// Declared dependencies, exact pins are used as the version of
+	// distributions which are not installed in the site-packages of the resolver
+	Manifests []*manifest.Manifest
+}
+
+// NewPackageManifest creates a vet package manifest with a direct dependency
+// for every distribution providing an imported third-party package. Vet can
+// run its policies on the manifest like on one read from a lockfile.
+func NewPackageManifest(importedModules *imports.ImportedModules, config ExporterConfig) *models.PackageManifest {
+	pm := &models.PackageManifest{Path: config.Path,
+		DisplayPath: config.Path,
+		Ecosystem:   models.EcosystemPyPI,
+		Packages:    make([]*models.Package, 0)}
+
+	pins := findPinnedVersions(config.Manifests)
+
+	importNames := importedModules.GetPackagesNames()
+	sort.Strings(importNames)
+
+	// Import names provided by the same distribution are a single package
+	added := make(map[string]bool, 0)
+	for _, importName := range importNames {
+		name := manifest.NormalizeName(importName)
+		version := ""
+		if candidate := config.Resolver.Resolve(importName).Best(); candidate != nil {
+			name = manifest.NormalizeName(candidate.Distribution)
+			version = candidate.Version
+		}
+		if version == "" {
+			version = pins[name]
+		}
+
+		if added[name] {
+			continue
+		}
+		added[name] = true
+
+		pm.AddPackage(&models.Package{PackageDetails: models.NewPackageDetail(models.EcosystemPyPI, name, version),
+			Depth:    0,
+			Manifest: pm})
+	}
+
+	return pm
+}
+
+// Write writes the manifest in the JSON dump format read by vet,
+// e.g. vet query --from <directory of the JSON file>
+func Write(w io.Writer, pm *models.PackageManifest) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(pm)
+}
+
+// findPinnedVersions finds the versions of the dependencies declared as name==version
+func findPinnedVersions(manifests []*manifest.Manifest) map[string]string {
+	pins := make(map[string]string, 0)
+	for _, m := range manifests {
+		for _, dep := range m.Dependencies {
+			if version, ok := pinnedVersion(dep.Specifier); ok {
+				pins[manifest.NormalizeName(dep.Name)] = version
+			}
+		}
+	}
+
+	return pins
+}
+
+func pinnedVersion(specifier string) (string, bool) {
+	// Environment markers follow the ;
+	specifier, _, _ = strings.Cut(specifier, ";")
+	specifier = strings.TrimSpace(specifier)
+
+	version, found := strings.CutPrefix(specifier, "==")
+	if !found || strings.HasPrefix(version, "=") {
+		return "", false
+	}
+
+	version = strings.TrimSpace(version)
+	if version == "" || strings.ContainsAny(version, ",*<>!~ ") {
+		return "", false
+	}
+
+	return version, true
+}
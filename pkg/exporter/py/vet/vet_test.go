@@ -0,0 +1,95 @@
+package vet
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/safedep/codex/pkg/manifest/py/manifest"
+	"github.com/safedep/codex/pkg/parser/py/imports"
+	"github.com/safedep/codex/pkg/resolver/py/dist"
+	"github.com/safedep/vet/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestManifest(t *testing.T, files map[string]string) *models.PackageManifest {
+	rootDir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(rootDir, name)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		assert.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+
+	cf := imports.NewPyCodeParserFactory()
+	parser, err := cf.NewCodeParser()
+	assert.NoError(t, err)
+
+	importedModules, err := parser.FindImportedModules(context.Background(), rootDir, true, []string{".py"}, []string{})
+	assert.NoError(t, err)
+
+	manifests, err := manifest.FindManifests(rootDir)
+	assert.NoError(t, err)
+
+	resolver, err := dist.NewResolver(dist.ResolverConfig{})
+	assert.NoError(t, err)
+
+	return NewPackageManifest(importedModules, ExporterConfig{Path: rootDir,
+		Resolver:  resolver,
+		Manifests: manifests})
+}
+
+func TestNewPackageManifest(t *testing.T) {
+	pm := newTestManifest(t, map[string]string{
+		"requirements.txt": "PyYAML==6.0.1\nrequests>=2.0\nboto3\n",
+		"app.py":           "import os\nimport yaml\nimport requests\nfrom yaml import safe_load\n",
+	})
+
+	assert.Equal(t, models.EcosystemPyPI, pm.Ecosystem)
+	assert.Equal(t, 2, pm.GetPackagesCount())
+
+	assert.Equal(t, "requests", pm.Packages[0].GetName())
+	assert.Equal(t, "", pm.Packages[0].GetVersion())
+
+	assert.Equal(t, "pyyaml", pm.Packages[1].GetName())
+	assert.Equal(t, "6.0.1", pm.Packages[1].GetVersion())
+	assert.Equal(t, models.EcosystemPyPI, string(pm.Packages[1].Ecosystem))
+	assert.Equal(t, pm, pm.Packages[1].Manifest)
+	assert.Equal(t, 0, pm.Packages[1].Depth)
+}
+
+func TestWrite(t *testing.T) {
+	pm := newTestManifest(t, map[string]string{"app.py": "import requests\n"})
+
+	var out bytes.Buffer
+	assert.NoError(t, Write(&out, pm))
+
+	// Decoded like the JSON dump reader of vet
+	var decoded models.PackageManifest
+	assert.NoError(t, json.Unmarshal(out.Bytes(), &decoded))
+	assert.Equal(t, models.EcosystemPyPI, decoded.Ecosystem)
+	assert.Equal(t, 1, len(decoded.Packages))
+	assert.Equal(t, "requests", decoded.Packages[0].GetName())
+	assert.Equal(t, "pkg:pypi/requests@", decoded.Packages[0].ShortName())
+}
+
+func TestPinnedVersion(t *testing.T) {
+	cases := map[string]string{
+		"==1.2.3":                           "1.2.3",
+		"== 1.2.3":                          "1.2.3",
+		"==1.2.3; python_version < \"3.8\"": "1.2.3",
+		">=1.2.3":                           "",
+		"==1.*":                             "",
+		"===1.2.3":                          "",
+		"==1.2,!=1.2.1":                     "",
+		"":                                  "",
+	}
+
+	for specifier, expected := range cases {
+		version, ok := pinnedVersion(specifier)
+		assert.Equal(t, expected != "", ok, specifier)
+		assert.Equal(t, expected, version, specifier)
+	}
+}
@@ -0,0 +1,96 @@
+package manifest
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/safedep/codex/pkg/parser/py/imports"
+	"github.com/safedep/codex/pkg/resolver/py/dist"
+)
+
+// UndeclaredDependency is a package imported in the code but missing in the manifests
+type UndeclaredDependency struct {
+	ImportName string
+	Resolution *dist.Resolution
+	Provenance []*imports.ImportProvenance
+}
+
+// DriftReport compares the declared dependencies with the imported packages
+type DriftReport struct {
+	Unused     []*DeclaredDependency   // declared but never imported, with their group
+	Undeclared []*UndeclaredDependency // imported but never declared
+}
+
+// FindDrift finds dependencies which are declared but never imported and
+// packages which are imported but never declared. Dependencies of other groups
+// than the runtime one, e.g. dev groups, extras and test requirements, are often
+// tools which are never imported, they are only reported as unused with allGroups.
+func FindDrift(manifests []*Manifest, importedModules *imports.ImportedModules,
+	resolver *dist.Resolver, allGroups bool) *DriftReport {
+	report := &DriftReport{Unused: make([]*DeclaredDependency, 0),
+		Undeclared: make([]*UndeclaredDependency, 0)}
+
+	declared := make(map[string]bool, 0)
+	for _, m := range manifests {
+		for _, dep := range m.Dependencies {
+			declared[NormalizeName(dep.Name)] = true
+		}
+	}
+
+	importNames := importedModules.GetPackagesNames()
+	sort.Strings(importNames)
+
+	used := make(map[string]bool, 0)
+	for _, importName := range importNames {
+		res := resolver.Resolve(importName)
+
+		// The import name is a candidate as well, e.g. when the resolver does not know a namespace
+		names := []string{NormalizeName(importName)}
+		for _, candidate := range res.Candidates {
+			names = append(names, NormalizeName(candidate.Distribution))
+		}
+
+		isDeclared := false
+		for _, name := range names {
+			if declared[name] {
+				isDeclared = true
+				used[name] = true
+			}
+		}
+
+		if !isDeclared {
+			report.Undeclared = append(report.Undeclared, &UndeclaredDependency{ImportName: importName,
+				Resolution: res,
+				Provenance: importedModules.GetProvenance(importName)})
+		}
+	}
+
+	reported := make(map[DeclaredDependency]bool, 0)
+	for _, m := range manifests {
+		for _, dep := range m.Dependencies {
+			if dep.Group != GROUP_RUNTIME && !allGroups {
+				continue
+			}
+			name := NormalizeName(dep.Name)
+			if used[name] || used[stubsFor(name)] || reported[*dep] {
+				continue
+			}
+			reported[*dep] = true
+			report.Unused = append(report.Unused, dep)
+		}
+	}
+
+	return report
+}
+
+// stubsFor returns the distribution type stubs are provided for,
+// e.g. types-requests and requests-stubs are used when requests is used
+func stubsFor(name string) string {
+	if stubbed, found := strings.CutPrefix(name, "types-"); found {
+		return stubbed
+	}
+	if stubbed, found := strings.CutSuffix(name, "-stubs"); found {
+		return stubbed
+	}
+	return ""
+}
@@ -0,0 +1,52 @@
+package manifest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/safedep/codex/pkg/parser/py/imports"
+	"github.com/safedep/codex/pkg/resolver/py/dist"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindDrift(t *testing.T) {
+	rootDir := t.TempDir()
+	writeFiles(t, rootDir, map[string]string{
+		"requirements.txt":     "PyYAML\nrequests\ntypes-requests\nbeautifulsoup4\n",
+		"requirements-dev.txt": "pytest\n",
+		"app.py":               "import os\nimport yaml\nimport requests\nimport numpy as np\n",
+		"lib/parse.py":         "from sklearn import metrics\n",
+	})
+
+	cf := imports.NewPyCodeParserFactory()
+	parser, err := cf.NewCodeParser()
+	assert.NoError(t, err)
+
+	importedModules, err := parser.FindImportedModules(context.Background(), rootDir, true, []string{".py"}, []string{})
+	assert.NoError(t, err)
+
+	manifests, err := FindManifests(rootDir)
+	assert.NoError(t, err)
+
+	resolver, err := dist.NewResolver(dist.ResolverConfig{})
+	assert.NoError(t, err)
+
+	report := FindDrift(manifests, importedModules, resolver, false)
+
+	assert.Equal(t, 1, len(report.Unused))
+	assert.Equal(t, "beautifulsoup4", report.Unused[0].Name)
+	assert.Equal(t, "requirements.txt", report.Unused[0].Manifest)
+	assert.Equal(t, 4, report.Unused[0].Line)
+
+	assert.Equal(t, 2, len(report.Undeclared))
+	assert.Equal(t, "numpy", report.Undeclared[0].ImportName)
+	assert.Equal(t, "app.py", report.Undeclared[0].Provenance[0].Path)
+	assert.Equal(t, "sklearn", report.Undeclared[1].ImportName)
+	assert.Equal(t, "scikit-learn", report.Undeclared[1].Resolution.Best().Distribution)
+
+	// Dependencies of the other groups are only reported when asked for
+	report = FindDrift(manifests, importedModules, resolver, true)
+	assert.Equal(t, 2, len(report.Unused))
+	assert.Equal(t, "pytest", report.Unused[0].Name)
+	assert.Equal(t, "dev", report.Unused[0].Group)
+}
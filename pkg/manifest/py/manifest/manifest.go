@@ -0,0 +1,215 @@
+/*
+	Parse Python package manifests to find the declared dependencies
+*/
+
+package manifest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/safedep/codex/pkg/utils/py/dir"
+	"github.com/safedep/dry/log"
+)
+
+type ManifestType string
+
+const (
+	MANIFEST_REQUIREMENTS ManifestType = "requirements.txt"
+	MANIFEST_PYPROJECT    ManifestType = "pyproject.toml"
+	MANIFEST_SETUP_CFG    ManifestType = "setup.cfg"
+	MANIFEST_SETUP_PY     ManifestType = "setup.py"
+	MANIFEST_PIPFILE      ManifestType = "Pipfile"
+)
+
+// Group of the dependencies required to run the package
+const GROUP_RUNTIME = ""
+
+// Directories which never contain manifests of the project itself
+var skippedManifestDirs = []string{".git", "node_modules", "venv", ".venv", ".tox", ".nox", "site-packages", "__pycache__"}
+
+var requirementNameRegex = regexp.MustCompile(`^\s*([A-Za-z0-9][A-Za-z0-9._-]*)\s*(\[[^\]]*\])?\s*(.*)$`)
+
+// DeclaredDependency is a dependency declared in a manifest
+type DeclaredDependency struct {
+	Name      string // distribution name as declared, e.g. PyYAML
+	Specifier string // version specifier and markers, e.g. >=6.0; python_version>"3.8"
+	Group     string // extra, dependency group or section, GROUP_RUNTIME for runtime dependencies
+	Manifest  string // manifest path relative to the scanned directory
+	Line      int    // line in the manifest (1 based), 0 when not known
+}
+
+type Manifest struct {
+	Path         string // manifest path relative to the scanned directory
+	Type         ManifestType
+	Dependencies []*DeclaredDependency
+	Scripts      []*ConsoleScript // commands installed by the package
+}
+
+func (m *Manifest) addDependency(dep *DeclaredDependency) {
+	dep.Manifest = m.Path
+	m.Dependencies = append(m.Dependencies, dep)
+}
+
+// FindManifests finds and parses all supported manifests in the directory tree
+func FindManifests(rootDir string) ([]*Manifest, error) {
+	manifests := make([]*Manifest, 0)
+
+	err := filepath.Walk(rootDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() {
+			if path != rootDir && isSkippedManifestDir(info.Name()) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		manifestType, ok := DetectManifestType(path)
+		if !ok {
+			return nil
+		}
+
+		relPath, err := dir.RelativePath(rootDir, path)
+		if err != nil {
+			return err
+		}
+
+		manifest, err := ParseManifest(rootDir, relPath, manifestType)
+		if err != nil {
+			// A broken manifest should not hide the others
+			log.Warnf("Error while parsing manifest %s: %v", path, err)
+			return nil
+		}
+
+		manifests = append(manifests, manifest)
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return manifests, nil
+}
+
+// DetectManifestType returns the type of the manifest from its file name
+func DetectManifestType(path string) (ManifestType, bool) {
+	name := filepath.Base(path)
+	switch {
+	case name == "pyproject.toml":
+		return MANIFEST_PYPROJECT, true
+	case name == "setup.cfg":
+		return MANIFEST_SETUP_CFG, true
+	case name == "setup.py":
+		return MANIFEST_SETUP_PY, true
+	case name == "Pipfile":
+		return MANIFEST_PIPFILE, true
+	case strings.HasPrefix(name, "requirements") && isRequirementsExt(name):
+		return MANIFEST_REQUIREMENTS, true
+	case filepath.Base(filepath.Dir(path)) == "requirements" && isRequirementsExt(name):
+		// Layouts such as requirements/base.txt, requirements/dev.txt
+		return MANIFEST_REQUIREMENTS, true
+	}
+
+	return "", false
+}
+
+// ParseManifest parses a manifest of the given type
+func ParseManifest(rootDir, relPath string, manifestType ManifestType) (*Manifest, error) {
+	content, err := os.ReadFile(filepath.Join(rootDir, relPath))
+	if err != nil {
+		return nil, err
+	}
+
+	manifest := &Manifest{Path: relPath, Type: manifestType}
+	switch manifestType {
+	case MANIFEST_REQUIREMENTS:
+		err = parseRequirements(manifest, content, requirementsGroup(relPath))
+	case MANIFEST_PYPROJECT:
+		err = parsePyProject(manifest, content)
+	case MANIFEST_PIPFILE:
+		err = parsePipfile(manifest, content)
+	case MANIFEST_SETUP_CFG:
+		err = parseSetupCfg(manifest, content)
+	case MANIFEST_SETUP_PY:
+		err = parseSetupPy(manifest, content)
+	default:
+		err = fmt.Errorf("unsupported manifest type %s", manifestType)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return manifest, nil
+}
+
+// GetDeclaredNames returns the sorted unique names of the declared dependencies
+func GetDeclaredNames(manifests []*Manifest) []string {
+	unique := make(map[string]bool, 0)
+	for _, m := range manifests {
+		for _, dep := range m.Dependencies {
+			unique[dep.Name] = true
+		}
+	}
+
+	names := make([]string, 0, len(unique))
+	for name := range unique {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+// NormalizeName normalizes a distribution name as per PEP 503
+func NormalizeName(name string) string {
+	name = strings.ToLower(name)
+	return strings.NewReplacer("_", "-", ".", "-").Replace(collapseSeparators(name))
+}
+
+func collapseSeparators(name string) string {
+	var sb strings.Builder
+	lastSep := false
+	for _, r := range name {
+		isSep := r == '-' || r == '_' || r == '.'
+		if isSep && lastSep {
+			continue
+		}
+		sb.WriteRune(r)
+		lastSep = isSep
+	}
+
+	return sb.String()
+}
+
+// parseRequirement parses a PEP 508 requirement such as requests[socks]>=2.0; python_version>"3"
+func parseRequirement(requirement string) (*DeclaredDependency, bool) {
+	requirement = strings.TrimSpace(requirement)
+	if requirement == "" {
+		return nil, false
+	}
+
+	match := requirementNameRegex.FindStringSubmatch(requirement)
+	if match == nil {
+		return nil, false
+	}
+
+	return &DeclaredDependency{Name: match[1], Specifier: strings.TrimSpace(match[3])}, true
+}
+
+func isSkippedManifestDir(name string) bool {
+	for _, skipped := range skippedManifestDirs {
+		if name == skipped {
+			return true
+		}
+	}
+	return false
+}
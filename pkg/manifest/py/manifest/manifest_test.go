@@ -0,0 +1,139 @@
+package manifest
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeFiles(t *testing.T, rootDir string, files map[string]string) {
+	for name, content := range files {
+		fullPath := filepath.Join(rootDir, name)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(fullPath), os.ModePerm))
+		assert.NoError(t, os.WriteFile(fullPath, []byte(content), 0644))
+	}
+}
+
+func depNames(m *Manifest) []string {
+	names := make([]string, 0)
+	for _, dep := range m.Dependencies {
+		names = append(names, dep.Group+":"+dep.Name)
+	}
+	return names
+}
+
+func TestParseManifests(t *testing.T) {
+	tests := []struct {
+		name     string
+		file     string
+		content  string
+		expected []string
+	}{
+		{"requirements", "requirements.txt", `
+# comment
+requests[socks]>=2.31 ; python_version > "3.8"
+PyYAML==6.0.1 --hash=sha256:abc
+-r requirements-dev.txt
+--index-url https://pypi.org/simple
+-e git+https://github.com/org/repo.git#egg=my_lib
+flask @ https://example.com/flask.whl
+`, []string{":requests", ":PyYAML", ":my_lib", ":flask"}},
+		{"requirements group", "requirements-dev.txt", "pytest\n", []string{"dev:pytest"}},
+		{"requirements dir", "requirements/base.txt", "django\n", []string{":django"}},
+		{"pep 621", "pyproject.toml", `
+[project]
+name = "app"
+dependencies = ["httpx>=0.25", "scikit-learn"]
+
+[project.optional-dependencies]
+docs = ["sphinx"]
+
+[dependency-groups]
+test = ["pytest", {include-group = "docs"}]
+`, []string{":httpx", ":scikit-learn", "docs:sphinx", "test:pytest"}},
+		{"poetry", "pyproject.toml", `
+[tool.poetry.dependencies]
+python = "^3.11"
+requests = "^2.31"
+boto3 = { version = "*", optional = true }
+
+[tool.poetry.dev-dependencies]
+black = "*"
+
+[tool.poetry.group.test.dependencies]
+pytest = "^7"
+`, []string{"optional:boto3", ":requests", "dev:black", "test:pytest"}},
+		{"pipfile", "Pipfile", `
+[packages]
+django = "*"
+
+[dev-packages]
+mypy = ">=1.0"
+`, []string{":django", "dev:mypy"}},
+		{"setup.cfg", "setup.cfg", `
+[metadata]
+name = app
+
+[options]
+packages = find:
+install_requires =
+    click>=8
+    importlib-metadata; python_version<"3.8"
+tests_require = pytest
+
+[options.extras_require]
+yaml = PyYAML
+`, []string{":click", ":importlib-metadata", "test:pytest", "yaml:PyYAML"}},
+		{"setup.py", "setup.py", `
+from setuptools import setup
+
+BASE = ["numpy>=1.20", 'pandas']
+REQUIRES = BASE + ["scipy"]
+
+setup(
+    name="app",
+    install_requires=REQUIRES,
+    extras_require={"plot": ["matplotlib"]},
+    tests_require=read_requirements("requirements-test.txt"),
+)
+`, []string{":numpy", ":pandas", ":scipy", "plot:matplotlib"}},
+	}
+
+	for _, test := range tests {
+		rootDir := t.TempDir()
+		writeFiles(t, rootDir, map[string]string{test.file: test.content})
+
+		manifestType, ok := DetectManifestType(test.file)
+		assert.True(t, ok, test.name)
+
+		m, err := ParseManifest(rootDir, test.file, manifestType)
+		assert.NoError(t, err, test.name)
+		assert.Equal(t, test.expected, depNames(m), test.name)
+	}
+}
+
+func TestFindManifests(t *testing.T) {
+	rootDir := t.TempDir()
+	writeFiles(t, rootDir, map[string]string{
+		"requirements.txt":                   "requests==2.31.0\n",
+		"service/pyproject.toml":             "[project]\ndependencies = [\"fastapi\"]\n",
+		".venv/lib/site-packages/x/setup.py": "setup(install_requires=['never'])",
+		"node_modules/pkg/requirements.txt":  "never\n",
+		"docs/conf.py":                       "",
+	})
+
+	manifests, err := FindManifests(rootDir)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(manifests))
+	assert.Equal(t, []string{"fastapi", "requests"}, GetDeclaredNames(manifests))
+	assert.Equal(t, 1, manifests[0].Dependencies[0].Line)
+}
+
+func TestNormalizeName(t *testing.T) {
+	assert.Equal(t, "pyyaml", NormalizeName("PyYAML"))
+	assert.Equal(t, "ruamel-yaml", NormalizeName("ruamel.yaml"))
+	assert.Equal(t, "typing-extensions", NormalizeName("typing__extensions"))
+	assert.Equal(t, "a-b", NormalizeName("a-_.b"))
+}
@@ -0,0 +1,153 @@
+package manifest
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+type pyProject struct {
+	Project struct {
+		Dependencies         []string            `toml:"dependencies"`
+		OptionalDependencies map[string][]string `toml:"optional-dependencies"`
+		Scripts              map[string]string   `toml:"scripts"`
+		GuiScripts           map[string]string   `toml:"gui-scripts"`
+	} `toml:"project"`
+
+	// PEP 735 dependency groups, entries can also be tables including other groups
+	DependencyGroups map[string][]interface{} `toml:"dependency-groups"`
+
+	Tool struct {
+		Poetry struct {
+			Dependencies    map[string]interface{} `toml:"dependencies"`
+			DevDependencies map[string]interface{} `toml:"dev-dependencies"`
+			Group           map[string]struct {
+				Dependencies map[string]interface{} `toml:"dependencies"`
+			} `toml:"group"`
+			Scripts map[string]interface{} `toml:"scripts"`
+		} `toml:"poetry"`
+	} `toml:"tool"`
+}
+
+type pipfile struct {
+	Packages    map[string]interface{} `toml:"packages"`
+	DevPackages map[string]interface{} `toml:"dev-packages"`
+}
+
+func parsePyProject(manifest *Manifest, content []byte) error {
+	var project pyProject
+	_, err := toml.Decode(string(content), &project)
+	if err != nil {
+		return err
+	}
+
+	// PEP 621
+	addRequirements(manifest, project.Project.Dependencies, GROUP_RUNTIME)
+	for _, extra := range sortedKeys(project.Project.OptionalDependencies) {
+		addRequirements(manifest, project.Project.OptionalDependencies[extra], extra)
+	}
+
+	for _, group := range sortedKeys(project.DependencyGroups) {
+		requirements := make([]string, 0)
+		for _, entry := range project.DependencyGroups[group] {
+			if requirement, ok := entry.(string); ok {
+				requirements = append(requirements, requirement)
+			}
+		}
+		addRequirements(manifest, requirements, group)
+	}
+
+	// Poetry
+	poetry := project.Tool.Poetry
+	addNamedDependencies(manifest, poetry.Dependencies, GROUP_RUNTIME)
+	addNamedDependencies(manifest, poetry.DevDependencies, "dev")
+	for _, group := range sortedKeys(poetry.Group) {
+		addNamedDependencies(manifest, poetry.Group[group].Dependencies, group)
+	}
+
+	// Console scripts
+	for _, scripts := range []map[string]string{project.Project.Scripts, project.Project.GuiScripts} {
+		for _, name := range sortedKeys(scripts) {
+			if script, ok := parseScriptReference(name, scripts[name]); ok {
+				manifest.addScript(script)
+			}
+		}
+	}
+	for _, name := range sortedKeys(poetry.Scripts) {
+		// Scripts are references or tables such as { reference = "mypkg.cli:main", type = "console" }
+		reference, ok := poetry.Scripts[name].(string)
+		if table, isTable := poetry.Scripts[name].(map[string]interface{}); isTable {
+			reference, ok = table["reference"].(string)
+		}
+		if !ok {
+			continue
+		}
+		if script, ok := parseScriptReference(name, reference); ok {
+			manifest.addScript(script)
+		}
+	}
+
+	return nil
+}
+
+func parsePipfile(manifest *Manifest, content []byte) error {
+	var pf pipfile
+	_, err := toml.Decode(string(content), &pf)
+	if err != nil {
+		return err
+	}
+
+	addNamedDependencies(manifest, pf.Packages, GROUP_RUNTIME)
+	addNamedDependencies(manifest, pf.DevPackages, "dev")
+	return nil
+}
+
+func addRequirements(manifest *Manifest, requirements []string, group string) {
+	for _, requirement := range requirements {
+		dep, ok := parseRequirement(requirement)
+		if !ok {
+			continue
+		}
+		dep.Group = group
+		manifest.addDependency(dep)
+	}
+}
+
+// addNamedDependencies adds Poetry and Pipfile style dependencies where the key is the
+// name and the value is either a version string or a table with the version
+func addNamedDependencies(manifest *Manifest, deps map[string]interface{}, group string) {
+	for _, name := range sortedKeys(deps) {
+		// Poetry declares the supported python versions as a dependency
+		if strings.EqualFold(name, "python") {
+			continue
+		}
+
+		dep := &DeclaredDependency{Name: name, Group: group}
+		switch value := deps[name].(type) {
+		case string:
+			if value != "*" {
+				dep.Specifier = value
+			}
+		case map[string]interface{}:
+			if version, ok := value["version"].(string); ok && version != "*" {
+				dep.Specifier = version
+			}
+			if optional, ok := value["optional"].(bool); ok && optional && group == GROUP_RUNTIME {
+				dep.Group = "optional"
+			}
+		}
+
+		manifest.addDependency(dep)
+	}
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,99 @@
+package manifest
+
+import (
+	"bufio"
+	"bytes"
+	"path/filepath"
+	"strings"
+)
+
+// Requirements files which declare runtime dependencies
+var runtimeRequirementsNames = []string{"", "base", "common", "main", "prod", "production", "runtime"}
+
+func isRequirementsExt(name string) bool {
+	ext := filepath.Ext(name)
+	return ext == ".txt" || ext == ".in"
+}
+
+// requirementsGroup derives the group from the file name, e.g. requirements-dev.txt
+// and requirements/dev.txt are dev
+func requirementsGroup(relPath string) string {
+	name := filepath.Base(relPath)
+	name = strings.TrimSuffix(name, filepath.Ext(name))
+	name = strings.TrimPrefix(name, "requirements")
+	name = strings.TrimLeft(name, "-_.")
+
+	for _, runtimeName := range runtimeRequirementsNames {
+		if name == runtimeName {
+			return GROUP_RUNTIME
+		}
+	}
+	return name
+}
+
+func parseRequirements(manifest *Manifest, content []byte, group string) error {
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	lineNumber := 0
+	continued := ""
+	for scanner.Scan() {
+		lineNumber += 1
+		line := continued + scanner.Text()
+		continued = ""
+
+		// Lines ending with a backslash continue on the next line
+		if strings.HasSuffix(line, "\\") {
+			continued = strings.TrimSuffix(line, "\\")
+			continue
+		}
+
+		// Comments start with # preceded by whitespace
+		if idx := strings.Index(line, " #"); idx >= 0 {
+			line = line[:idx]
+		}
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		dep, ok := parseRequirementLine(line)
+		if !ok {
+			continue
+		}
+
+		dep.Group = group
+		dep.Line = lineNumber
+		manifest.addDependency(dep)
+	}
+
+	return scanner.Err()
+}
+
+func parseRequirementLine(line string) (*DeclaredDependency, bool) {
+	// Editable installs and URLs declare their name with #egg=
+	if strings.HasPrefix(line, "-e ") || strings.HasPrefix(line, "--editable") || strings.Contains(line, "://") {
+		_, egg, found := strings.Cut(line, "#egg=")
+		if !found {
+			// PEP 508 direct reference, e.g. name @ https://...
+			name, _, isDirect := strings.Cut(line, "@")
+			if isDirect && !strings.Contains(name, "://") {
+				return parseRequirement(name)
+			}
+			return nil, false
+		}
+		egg, _, _ = strings.Cut(egg, "&")
+		return parseRequirement(egg)
+	}
+
+	// Options such as -r, -c, --index-url, --hash do not declare a dependency.
+	// Files included with -r are found and parsed on their own.
+	if strings.HasPrefix(line, "-") {
+		return nil, false
+	}
+
+	// Drop per requirement options, e.g. --hash=sha256:...
+	if idx := strings.Index(line, " --"); idx >= 0 {
+		line = line[:idx]
+	}
+
+	return parseRequirement(line)
+}
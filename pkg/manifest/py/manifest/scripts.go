@@ -0,0 +1,64 @@
+package manifest
+
+import (
+	"strings"
+)
+
+// Entry point groups of the commands installed by a package
+var scriptGroups = []string{"console_scripts", "gui_scripts"}
+
+// ConsoleScript is a command installed by a package, e.g. mytool = mypkg.cli:main
+type ConsoleScript struct {
+	Name     string // name of the command
+	Module   string // module of the function, e.g. mypkg.cli
+	Function string // function run by the command, e.g. main or App.run
+	Manifest string // manifest path relative to the scanned directory
+	Line     int    // line in the manifest (1 based), 0 when not known
+}
+
+func (m *Manifest) addScript(script *ConsoleScript) {
+	script.Manifest = m.Path
+	m.Scripts = append(m.Scripts, script)
+}
+
+// GetConsoleScripts returns the commands declared by every manifest
+func GetConsoleScripts(manifests []*Manifest) []*ConsoleScript {
+	scripts := make([]*ConsoleScript, 0)
+	for _, m := range manifests {
+		scripts = append(scripts, m.Scripts...)
+	}
+	return scripts
+}
+
+// parseScriptReference parses the object reference of an entry point such as
+// mypkg.cli:main [extra]
+func parseScriptReference(name, reference string) (*ConsoleScript, bool) {
+	// Extras of the entry point are not part of the reference
+	reference, _, _ = strings.Cut(reference, "[")
+	module, function, found := strings.Cut(strings.TrimSpace(reference), ":")
+	module = strings.TrimSpace(module)
+	function = strings.TrimSpace(function)
+	if name == "" || module == "" || !found || function == "" {
+		return nil, false
+	}
+
+	return &ConsoleScript{Name: name, Module: module, Function: function}, true
+}
+
+// parseScriptLine parses an entry point line such as mytool = mypkg.cli:main
+func parseScriptLine(line string) (*ConsoleScript, bool) {
+	name, reference, found := strings.Cut(line, "=")
+	if !found {
+		return nil, false
+	}
+	return parseScriptReference(strings.TrimSpace(name), reference)
+}
+
+func isScriptGroup(group string) bool {
+	for _, g := range scriptGroups {
+		if group == g {
+			return true
+		}
+	}
+	return false
+}
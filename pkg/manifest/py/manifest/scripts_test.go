@@ -0,0 +1,46 @@
+package manifest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConsoleScripts(t *testing.T) {
+	rootDir := t.TempDir()
+	writeFiles(t, rootDir, map[string]string{
+		"pyproject.toml": `
+[project]
+name = "app"
+
+[project.scripts]
+app = "app.cli:main"
+
+[tool.poetry.scripts]
+worker = { reference = "app.worker:Worker.run", type = "console" }
+`,
+		"setup.cfg": `
+[options.entry_points]
+console_scripts =
+    app-admin = app.admin:main [extra]
+`,
+		"setup.py": `
+from setuptools import setup
+
+SCRIPTS = ["app-sync = app.sync:run"]
+
+setup(name="app", entry_points={"console_scripts": SCRIPTS, "pytest11": ["plugin = app.plugin"]})
+`,
+	})
+
+	manifests, err := FindManifests(rootDir)
+	assert.NoError(t, err)
+
+	scripts := GetConsoleScripts(manifests)
+	assert.Equal(t, []*ConsoleScript{
+		{Name: "app", Module: "app.cli", Function: "main", Manifest: "pyproject.toml"},
+		{Name: "worker", Module: "app.worker", Function: "Worker.run", Manifest: "pyproject.toml"},
+		{Name: "app-admin", Module: "app.admin", Function: "main", Manifest: "setup.cfg", Line: 4},
+		{Name: "app-sync", Module: "app.sync", Function: "run", Manifest: "setup.py", Line: 4},
+	}, scripts)
+}
@@ -0,0 +1,274 @@
+package manifest
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"strings"
+
+	"github.com/safedep/codex/pkg/utils/py/strlit"
+	tree_sitter "github.com/smacker/go-tree-sitter"
+	"github.com/smacker/go-tree-sitter/python"
+)
+
+// Keyword arguments of setup() and keys of setup.cfg [options] declaring dependencies
+var setupRequiresGroups = map[string]string{
+	"install_requires": GROUP_RUNTIME,
+	"tests_require":    "test",
+	"setup_requires":   "build",
+}
+
+const setupExtrasRequire = "extras_require"
+
+const setupEntryPoints = "entry_points"
+
+func parseSetupCfg(manifest *Manifest, content []byte) error {
+	section := ""
+	key := ""
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber += 1
+		rawLine := scanner.Text()
+		line := strings.TrimSpace(rawLine)
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = strings.TrimSpace(line[1 : len(line)-1])
+			key = ""
+			continue
+		}
+
+		// Values of a key continue on indented lines
+		value := line
+		if rawLine[0] != ' ' && rawLine[0] != '\t' {
+			name, rest, found := strings.Cut(line, "=")
+			if !found {
+				key = ""
+				continue
+			}
+			key = strings.TrimSpace(name)
+			value = strings.TrimSpace(rest)
+		}
+
+		// [options.entry_points] holds one entry point per line
+		if section == "options.entry_points" && isScriptGroup(key) {
+			if script, ok := parseScriptLine(value); ok {
+				script.Line = lineNumber
+				manifest.addScript(script)
+			}
+			continue
+		}
+
+		group, ok := setupCfgGroup(section, key)
+		if !ok {
+			continue
+		}
+
+		// Directives such as file: requirements.txt refer to other manifests
+		if strings.HasPrefix(value, "file:") || strings.HasPrefix(value, "attr:") {
+			continue
+		}
+
+		// Each line holds one requirement, anything after ; is an environment marker
+		dep, ok := parseRequirement(value)
+		if !ok {
+			continue
+		}
+		dep.Group = group
+		dep.Line = lineNumber
+		manifest.addDependency(dep)
+	}
+
+	return scanner.Err()
+}
+
+func setupCfgGroup(section, key string) (string, bool) {
+	switch section {
+	case "options":
+		group, ok := setupRequiresGroups[key]
+		return group, ok
+	case "options.extras_require":
+		return key, key != ""
+	}
+
+	return "", false
+}
+
+// parseSetupPy finds the dependencies passed as literals to setup() in setup.py
+func parseSetupPy(manifest *Manifest, content []byte) error {
+	parser := tree_sitter.NewParser()
+	parser.SetLanguage(python.GetLanguage())
+	tree, err := parser.ParseCtx(context.Background(), nil, content)
+	if err != nil {
+		return err
+	}
+
+	sp := &setupPyParser{manifest: manifest, code: content,
+		assignments: make(map[string]*tree_sitter.Node, 0)}
+	sp.findAssignments(tree.RootNode())
+	sp.findSetupCalls(tree.RootNode())
+	return nil
+}
+
+type setupPyParser struct {
+	manifest    *Manifest
+	code        []byte
+	assignments map[string]*tree_sitter.Node // module level NAME = value
+}
+
+func (sp *setupPyParser) findAssignments(root *tree_sitter.Node) {
+	for i := 0; i < int(root.NamedChildCount()); i++ {
+		stmt := root.NamedChild(i)
+		if stmt.Type() != "expression_statement" || stmt.NamedChildCount() == 0 {
+			continue
+		}
+
+		assignment := stmt.NamedChild(0)
+		if assignment.Type() != "assignment" {
+			continue
+		}
+
+		left := assignment.ChildByFieldName("left")
+		right := assignment.ChildByFieldName("right")
+		if left != nil && right != nil && left.Type() == "identifier" {
+			sp.assignments[left.Content(sp.code)] = right
+		}
+	}
+}
+
+func (sp *setupPyParser) findSetupCalls(node *tree_sitter.Node) {
+	if node.Type() == "call" && sp.isSetupFunction(node.ChildByFieldName("function")) {
+		args := node.ChildByFieldName("arguments")
+		for i := 0; args != nil && i < int(args.NamedChildCount()); i++ {
+			arg := args.NamedChild(i)
+			if arg.Type() != "keyword_argument" {
+				continue
+			}
+
+			name := arg.ChildByFieldName("name").Content(sp.code)
+			value := arg.ChildByFieldName("value")
+			if group, ok := setupRequiresGroups[name]; ok {
+				sp.addRequirements(value, group, 0)
+			} else if name == setupExtrasRequire {
+				sp.addExtras(value, 0)
+			} else if name == setupEntryPoints {
+				sp.addEntryPoints(value, 0)
+			}
+		}
+	}
+
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		sp.findSetupCalls(node.NamedChild(i))
+	}
+}
+
+func (sp *setupPyParser) isSetupFunction(function *tree_sitter.Node) bool {
+	if function == nil {
+		return false
+	}
+
+	name := function.Content(sp.code)
+	return name == "setup" || strings.HasSuffix(name, ".setup")
+}
+
+// Maximum number of variable indirections followed while resolving a value
+const maxSetupPyIndirections = 8
+
+// resolve follows a variable to its module level value
+func (sp *setupPyParser) resolve(value *tree_sitter.Node, depth int) *tree_sitter.Node {
+	for value != nil && value.Type() == "identifier" && depth < maxSetupPyIndirections {
+		value = sp.assignments[value.Content(sp.code)]
+		depth += 1
+	}
+
+	return value
+}
+
+func (sp *setupPyParser) addRequirements(value *tree_sitter.Node, group string, depth int) {
+	value = sp.resolve(value, depth)
+	if value == nil || depth > maxSetupPyIndirections {
+		return
+	}
+
+	switch value.Type() {
+	case "list", "tuple":
+		for i := 0; i < int(value.NamedChildCount()); i++ {
+			element := value.NamedChild(i)
+			requirement, ok := strlit.Unquote(element.Content(sp.code))
+			if !ok {
+				continue
+			}
+
+			dep, ok := parseRequirement(requirement)
+			if !ok {
+				continue
+			}
+			dep.Group = group
+			dep.Line = int(element.StartPoint().Row) + 1
+			sp.manifest.addDependency(dep)
+		}
+	case "binary_operator":
+		// REQUIRES + EXTRA_REQUIRES
+		sp.addRequirements(value.ChildByFieldName("left"), group, depth+1)
+		sp.addRequirements(value.ChildByFieldName("right"), group, depth+1)
+	}
+}
+
+func (sp *setupPyParser) addExtras(value *tree_sitter.Node, depth int) {
+	value = sp.resolve(value, depth)
+	if value == nil || value.Type() != "dictionary" {
+		return
+	}
+
+	for i := 0; i < int(value.NamedChildCount()); i++ {
+		pair := value.NamedChild(i)
+		if pair.Type() != "pair" {
+			continue
+		}
+
+		extra, ok := strlit.Unquote(pair.ChildByFieldName("key").Content(sp.code))
+		if !ok {
+			continue
+		}
+		sp.addRequirements(pair.ChildByFieldName("value"), extra, depth+1)
+	}
+}
+
+// addEntryPoints finds the console scripts of entry_points={"console_scripts": [...]}
+func (sp *setupPyParser) addEntryPoints(value *tree_sitter.Node, depth int) {
+	value = sp.resolve(value, depth)
+	if value == nil || value.Type() != "dictionary" {
+		return
+	}
+
+	for i := 0; i < int(value.NamedChildCount()); i++ {
+		pair := value.NamedChild(i)
+		if pair.Type() != "pair" {
+			continue
+		}
+
+		group, ok := strlit.Unquote(pair.ChildByFieldName("key").Content(sp.code))
+		if !ok || !isScriptGroup(group) {
+			continue
+		}
+
+		scripts := sp.resolve(pair.ChildByFieldName("value"), depth+1)
+		if scripts == nil || (scripts.Type() != "list" && scripts.Type() != "tuple") {
+			continue
+		}
+		for j := 0; j < int(scripts.NamedChildCount()); j++ {
+			element := scripts.NamedChild(j)
+			line, ok := strlit.Unquote(element.Content(sp.code))
+			if !ok {
+				continue
+			}
+			if script, ok := parseScriptLine(line); ok {
+				script.Line = int(element.StartPoint().Row) + 1
+				sp.manifest.addScript(script)
+			}
+		}
+	}
+}
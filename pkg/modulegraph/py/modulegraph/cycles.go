@@ -0,0 +1,157 @@
+package modulegraph
+
+import (
+	"sort"
+)
+
+// Cycle is a cycle of imports between modules of the repository
+type Cycle struct {
+	Modules  []string // modules of the strongly connected component of the cycle, sorted
+	Path     []*Edge  // shortest cycle of the component, the last edge imports the first module
+	Deferred bool     // an edge of the cycle only imports inside functions or TYPE_CHECKING blocks
+}
+
+// FindCycles returns the import cycles between the modules of the repository,
+// one per strongly connected component of the graph. Packages are leaves, so
+// they are never part of a cycle.
+// Components with a cycle of imports run when their modules are imported report
+// the shortest of those cycles. Other components only cycle through deferred
+// imports, which are harmless at runtime, and report their shortest cycle as deferred.
+func FindCycles(g *Graph) []*Cycle {
+	cycles := make([]*Cycle, 0)
+	for _, component := range g.stronglyConnectedComponents(allEdges) {
+		// A component may hold several cycles of eager imports
+		eagerComponents := g.stronglyConnectedComponents(func(e *Edge) bool {
+			return !e.IsDeferred() && component[e.From] && component[e.To]
+		})
+		for _, eager := range eagerComponents {
+			cycles = append(cycles, &Cycle{Modules: sortedKeys(eager),
+				Path: g.shortestCycle(eager, eagerEdges)})
+		}
+
+		if len(eagerComponents) == 0 {
+			cycles = append(cycles, &Cycle{Modules: sortedKeys(component),
+				Path:     g.shortestCycle(component, allEdges),
+				Deferred: true})
+		}
+	}
+
+	sort.SliceStable(cycles, func(i, j int) bool {
+		return cycles[i].Modules[0] < cycles[j].Modules[0]
+	})
+	return cycles
+}
+
+func allEdges(e *Edge) bool {
+	return true
+}
+
+func eagerEdges(e *Edge) bool {
+	return !e.IsDeferred()
+}
+
+// stronglyConnectedComponents returns the components of more than one module,
+// following the edges accepted by the filter (Tarjan's algorithm)
+func (g *Graph) stronglyConnectedComponents(follow func(e *Edge) bool) []map[string]bool {
+	index := make(map[string]int, 0)
+	lowLink := make(map[string]int, 0)
+	onStack := make(map[string]bool, 0)
+	stack := make([]string, 0)
+	components := make([]map[string]bool, 0)
+
+	var connect func(id string)
+	connect = func(id string) {
+		index[id] = len(index)
+		lowLink[id] = index[id]
+		stack = append(stack, id)
+		onStack[id] = true
+
+		for _, edge := range g.GetImports(id) {
+			if !follow(edge) {
+				continue
+			}
+			if _, visited := index[edge.To]; !visited {
+				connect(edge.To)
+				lowLink[id] = min(lowLink[id], lowLink[edge.To])
+			} else if onStack[edge.To] {
+				lowLink[id] = min(lowLink[id], index[edge.To])
+			}
+		}
+
+		// The node is the root of a component, pop the component from the stack
+		if lowLink[id] == index[id] {
+			component := make(map[string]bool, 0)
+			for {
+				top := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[top] = false
+				component[top] = true
+				if top == id {
+					break
+				}
+			}
+			if len(component) > 1 {
+				components = append(components, component)
+			}
+		}
+	}
+
+	for _, node := range g.GetNodes() {
+		if _, visited := index[node.ID]; !visited && node.Kind == NODE_MODULE {
+			connect(node.ID)
+		}
+	}
+
+	return components
+}
+
+// shortestCycle returns the shortest cycle of the component following the edges
+// accepted by the filter. Ties are broken by the order of the module names.
+func (g *Graph) shortestCycle(component map[string]bool, follow func(e *Edge) bool) []*Edge {
+	var shortest []*Edge
+	for _, start := range sortedKeys(component) {
+		// Breadth first search of the shortest path back to the start
+		parents := map[string]*Edge{}
+		queue := []string{start}
+		var last *Edge
+		for len(queue) > 0 && last == nil {
+			id := queue[0]
+			queue = queue[1:]
+			for _, edge := range g.GetImports(id) {
+				if !component[edge.To] || !follow(edge) {
+					continue
+				}
+				if edge.To == start {
+					last = edge
+					break
+				}
+				if _, seen := parents[edge.To]; !seen {
+					parents[edge.To] = edge
+					queue = append(queue, edge.To)
+				}
+			}
+		}
+		if last == nil {
+			continue
+		}
+
+		path := []*Edge{last}
+		for id := last.From; id != start; id = parents[id].From {
+			path = append([]*Edge{parents[id]}, path...)
+		}
+		if shortest == nil || len(path) < len(shortest) {
+			shortest = path
+		}
+	}
+
+	return shortest
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
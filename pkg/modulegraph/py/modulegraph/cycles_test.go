@@ -0,0 +1,80 @@
+package modulegraph
+
+import (
+	"testing"
+
+	"github.com/safedep/codex/pkg/parser/py/imports"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestGraph(edges map[string][]string, lazy map[string]bool) *Graph {
+	g := NewGraph()
+	for from, tos := range edges {
+		g.AddNode(&Node{ID: from, Kind: NODE_MODULE, Path: from + ".py"})
+		for row, to := range tos {
+			site := &ImportSite{Path: from + ".py", RowStart: uint32(row), RowEnd: uint32(row)}
+			if lazy[from+" "+to] {
+				site.Guards = []imports.ImportGuard{imports.IMPORT_GUARD_LAZY}
+			}
+			g.AddImport(from, to, site)
+		}
+	}
+	return g
+}
+
+func cyclePath(cycle *Cycle) []string {
+	path := []string{}
+	for _, edge := range cycle.Path {
+		path = append(path, edge.From+" -> "+edge.To)
+	}
+	return path
+}
+
+func TestFindCycles(t *testing.T) {
+	g := newTestGraph(map[string][]string{
+		"a": {"b", "requests"},
+		"b": {"c"},
+		"c": {"a", "d"},
+		"d": {"c"},
+		"e": {"f"},
+		"f": {"e"},
+		"g": {"a"},
+	}, map[string]bool{"f e": true})
+	g.AddNode(&Node{ID: "requests", Kind: NODE_PACKAGE})
+
+	cycles := FindCycles(g)
+	assert.Len(t, cycles, 2)
+
+	// The shortest cycle of the component
+	assert.Equal(t, []string{"a", "b", "c", "d"}, cycles[0].Modules)
+	assert.Equal(t, []string{"c -> d", "d -> c"}, cyclePath(cycles[0]))
+	assert.False(t, cycles[0].Deferred)
+
+	assert.Equal(t, []string{"e", "f"}, cycles[1].Modules)
+	assert.Equal(t, []string{"e -> f", "f -> e"}, cyclePath(cycles[1]))
+	assert.True(t, cycles[1].Deferred)
+}
+
+func TestFindCyclesEagerInDeferredComponent(t *testing.T) {
+	// a and b import each other at runtime, c is only linked by a lazy import
+	g := newTestGraph(map[string][]string{
+		"a": {"b"},
+		"b": {"a", "c"},
+		"c": {"a"},
+	}, map[string]bool{"c a": true})
+
+	cycles := FindCycles(g)
+	assert.Len(t, cycles, 1)
+	assert.Equal(t, []string{"a", "b"}, cycles[0].Modules)
+	assert.Equal(t, []string{"a -> b", "b -> a"}, cyclePath(cycles[0]))
+	assert.False(t, cycles[0].Deferred)
+}
+
+func TestFindCyclesNone(t *testing.T) {
+	g := newTestGraph(map[string][]string{
+		"a": {"b", "c"},
+		"b": {"c"},
+	}, map[string]bool{})
+
+	assert.Empty(t, FindCycles(g))
+}
@@ -0,0 +1,163 @@
+package modulegraph
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+type Format string
+
+const (
+	FORMAT_DOT     Format = "dot"
+	FORMAT_GRAPHML Format = "graphml"
+	FORMAT_MERMAID Format = "mermaid"
+)
+
+// Write writes the graph in a graph description language. Packages are drawn
+// apart from the modules, and deferred imports with dashed edges.
+func Write(w io.Writer, format Format, g *Graph) error {
+	switch format {
+	case FORMAT_DOT:
+		return writeDot(w, g)
+	case FORMAT_GRAPHML:
+		return writeGraphML(w, g)
+	case FORMAT_MERMAID:
+		return writeMermaid(w, g)
+	}
+
+	return fmt.Errorf("graph can not be written as %q", format)
+}
+
+func writeDot(w io.Writer, g *Graph) error {
+	var b strings.Builder
+	b.WriteString("digraph modules {\n")
+	b.WriteString("  rankdir=LR;\n")
+	b.WriteString("  node [shape=box];\n")
+	for _, node := range g.GetNodes() {
+		if node.Kind == NODE_PACKAGE {
+			fmt.Fprintf(&b, "  %s [shape=ellipse, style=filled, fillcolor=lightgrey];\n", dotID(node.ID))
+		} else {
+			fmt.Fprintf(&b, "  %s;\n", dotID(node.ID))
+		}
+	}
+	for _, edge := range g.GetEdges() {
+		if edge.IsDeferred() {
+			fmt.Fprintf(&b, "  %s -> %s [style=dashed];\n", dotID(edge.From), dotID(edge.To))
+		} else {
+			fmt.Fprintf(&b, "  %s -> %s;\n", dotID(edge.From), dotID(edge.To))
+		}
+	}
+	b.WriteString("}\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// dotID quotes a name as a DOT identifier
+func dotID(id string) string {
+	return `"` + strings.ReplaceAll(id, `"`, `\"`) + `"`
+}
+
+type graphML struct {
+	XMLName xml.Name     `xml:"graphml"`
+	Xmlns   string       `xml:"xmlns,attr"`
+	Keys    []graphMLKey `xml:"key"`
+	Graph   graphMLGraph `xml:"graph"`
+}
+
+type graphMLKey struct {
+	ID      string `xml:"id,attr"`
+	For     string `xml:"for,attr"`
+	Name    string `xml:"attr.name,attr"`
+	Type    string `xml:"attr.type,attr"`
+	Default string `xml:"default,omitempty"`
+}
+
+type graphMLGraph struct {
+	ID          string        `xml:"id,attr"`
+	EdgeDefault string        `xml:"edgedefault,attr"`
+	Nodes       []graphMLNode `xml:"node"`
+	Edges       []graphMLEdge `xml:"edge"`
+}
+
+type graphMLData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+type graphMLNode struct {
+	ID   string        `xml:"id,attr"`
+	Data []graphMLData `xml:"data"`
+}
+
+type graphMLEdge struct {
+	Source string        `xml:"source,attr"`
+	Target string        `xml:"target,attr"`
+	Data   []graphMLData `xml:"data"`
+}
+
+func writeGraphML(w io.Writer, g *Graph) error {
+	doc := graphML{Xmlns: "http://graphml.graphdrawing.org/xmlns",
+		Keys: []graphMLKey{
+			{ID: "kind", For: "node", Name: "kind", Type: "string"},
+			{ID: "path", For: "node", Name: "path", Type: "string"},
+			{ID: "imports", For: "edge", Name: "imports", Type: "int"},
+			{ID: "deferred", For: "edge", Name: "deferred", Type: "boolean", Default: "false"},
+		},
+		Graph: graphMLGraph{ID: "modules", EdgeDefault: "directed"}}
+
+	for _, node := range g.GetNodes() {
+		n := graphMLNode{ID: node.ID, Data: []graphMLData{{Key: "kind", Value: string(node.Kind)}}}
+		if node.Path != "" {
+			n.Data = append(n.Data, graphMLData{Key: "path", Value: node.Path})
+		}
+		doc.Graph.Nodes = append(doc.Graph.Nodes, n)
+	}
+	for _, edge := range g.GetEdges() {
+		doc.Graph.Edges = append(doc.Graph.Edges, graphMLEdge{Source: edge.From, Target: edge.To,
+			Data: []graphMLData{
+				{Key: "imports", Value: fmt.Sprint(len(edge.Imports))},
+				{Key: "deferred", Value: fmt.Sprint(edge.IsDeferred())},
+			}})
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func writeMermaid(w io.Writer, g *Graph) error {
+	var b strings.Builder
+	b.WriteString("flowchart LR\n")
+
+	// Mermaid IDs can not contain dots, nodes are numbered and labelled with their names
+	ids := make(map[string]string, 0)
+	for i, node := range g.GetNodes() {
+		ids[node.ID] = fmt.Sprintf("n%d", i)
+		label := strings.ReplaceAll(node.ID, `"`, "#quot;")
+		if node.Kind == NODE_PACKAGE {
+			fmt.Fprintf(&b, "  %s([\"%s\"])\n", ids[node.ID], label)
+		} else {
+			fmt.Fprintf(&b, "  %s[\"%s\"]\n", ids[node.ID], label)
+		}
+	}
+	for _, edge := range g.GetEdges() {
+		arrow := "-->"
+		if edge.IsDeferred() {
+			arrow = "-.->"
+		}
+		fmt.Fprintf(&b, "  %s %s %s\n", ids[edge.From], arrow, ids[edge.To])
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
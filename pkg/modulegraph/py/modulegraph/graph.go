@@ -0,0 +1,202 @@
+/*
+	Build the graph of the imports between the modules of a Python repository
+*/
+
+package modulegraph
+
+import (
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/safedep/codex/pkg/parser/py/imports"
+	"github.com/safedep/codex/pkg/utils/py/dir"
+)
+
+type NodeKind string
+
+const (
+	NODE_MODULE  NodeKind = "module"  // module of the repository
+	NODE_PACKAGE NodeKind = "package" // top-level third-party package, a leaf of the graph
+)
+
+// Node is a module of the repository or a third-party package
+type Node struct {
+	ID   string // dotted name, e.g. myapp.views or requests
+	Kind NodeKind
+	Path string // file of the module, empty for packages
+}
+
+// ImportSite is an import statement of an edge
+type ImportSite struct {
+	Path        string // file of the import
+	RowStart    uint32 // first row of the import statement (0 based)
+	RowEnd      uint32 // last row of the import statement (0 based)
+	ColumnStart uint32 // column of the start of the import statement (0 based, in unicode code points)
+	ColumnEnd   uint32 // column after the end of the import statement (0 based, in unicode code points)
+	StartByte   uint32 // offset of the import statement in the file
+	EndByte     uint32 // offset after the import statement in the file
+	Statement   string
+	Guards      []imports.ImportGuard // conditions under which the import runs
+}
+
+// Edge links a module to a module or a package it imports, with every statement
+// importing it in the order of the code
+type Edge struct {
+	From    string
+	To      string
+	Imports []*ImportSite
+}
+
+// IsDeferred tells whether every import of the edge is inside a function or a
+// TYPE_CHECKING block, so that importing the module does not import the other one
+func (e *Edge) IsDeferred() bool {
+	for _, site := range e.Imports {
+		deferred := false
+		for _, guard := range site.Guards {
+			if guard == imports.IMPORT_GUARD_LAZY || guard == imports.IMPORT_GUARD_TYPE_CHECKING {
+				deferred = true
+			}
+		}
+		if !deferred {
+			return false
+		}
+	}
+	return len(e.Imports) > 0
+}
+
+// Graph is the graph of the imports of the modules of a repository
+type Graph struct {
+	nodes map[string]*Node
+	edges map[string]map[string]*Edge
+}
+
+func NewGraph() *Graph {
+	return &Graph{nodes: make(map[string]*Node, 0),
+		edges: make(map[string]map[string]*Edge, 0)}
+}
+
+// AddNode adds the node unless a node has the same ID, and returns the node of the graph
+func (g *Graph) AddNode(node *Node) *Node {
+	if existing, ok := g.nodes[node.ID]; ok {
+		return existing
+	}
+
+	g.nodes[node.ID] = node
+	return node
+}
+
+// AddImport adds an import statement of a module to the edge of the imported node.
+// Imports of a module by itself are ignored.
+func (g *Graph) AddImport(from string, to string, site *ImportSite) {
+	if from == to {
+		return
+	}
+
+	if g.edges[from] == nil {
+		g.edges[from] = make(map[string]*Edge, 0)
+	}
+	edge, ok := g.edges[from][to]
+	if !ok {
+		edge = &Edge{From: from, To: to, Imports: make([]*ImportSite, 0)}
+		g.edges[from][to] = edge
+	}
+
+	// Every name imported by a statement is a module, keep the statement once
+	for _, existing := range edge.Imports {
+		if existing.Path == site.Path && existing.RowStart == site.RowStart {
+			return
+		}
+	}
+	edge.Imports = append(edge.Imports, site)
+}
+
+func (g *Graph) GetNode(id string) (*Node, bool) {
+	node, ok := g.nodes[id]
+	return node, ok
+}
+
+// GetNodes returns the nodes sorted by their IDs
+func (g *Graph) GetNodes() []*Node {
+	nodes := make([]*Node, 0, len(g.nodes))
+	for _, node := range g.nodes {
+		nodes = append(nodes, node)
+	}
+
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].ID < nodes[j].ID
+	})
+	return nodes
+}
+
+// GetEdges returns the edges sorted by the IDs of their nodes
+func (g *Graph) GetEdges() []*Edge {
+	edges := make([]*Edge, 0)
+	for _, node := range g.GetNodes() {
+		edges = append(edges, g.GetImports(node.ID)...)
+	}
+	return edges
+}
+
+// GetImports returns the edges of the nodes imported by the node, sorted by the imported IDs
+func (g *Graph) GetImports(id string) []*Edge {
+	edges := make([]*Edge, 0, len(g.edges[id]))
+	for _, edge := range g.edges[id] {
+		edges = append(edges, edge)
+	}
+
+	sort.Slice(edges, func(i, j int) bool {
+		return edges[i].To < edges[j].To
+	})
+	return edges
+}
+
+// Build links every module of the analyzed files to the modules of the repository
+// and the third-party packages it imports. Standard library modules are left out.
+func Build(dirpath string, repoAnalysis *imports.RepoCodeAnalysis) *Graph {
+	g := NewGraph()
+
+	// Name the modules after the packages of the repository
+	modulesByPath := make(map[string]string, 0)
+	for _, fa := range repoAnalysis.FilesAnalysis {
+		name := dir.ModuleName(dirpath, fa.Path)
+		if _, exists := g.nodes[name]; exists || name == "" {
+			// Scripts of different directories may have the same name
+			name = strings.ReplaceAll(strings.TrimSuffix(fa.Path, path.Ext(fa.Path)), "/", ".")
+		}
+		g.AddNode(&Node{ID: name, Kind: NODE_MODULE, Path: fa.Path})
+		modulesByPath[fa.Path] = name
+	}
+
+	for _, fa := range repoAnalysis.FilesAnalysis {
+		from := modulesByPath[fa.Path]
+		for _, mod := range fa.Modules {
+			node := &mod.Name
+			if mod.Statement != nil {
+				node = mod.Statement
+			}
+			site := &ImportSite{Path: fa.Path, RowStart: node.RowStart, RowEnd: node.RowEnd,
+				ColumnStart: node.ColumnStart,
+				ColumnEnd:   node.ColumnEnd,
+				StartByte:   node.StartByte,
+				EndByte:     node.EndByte,
+				Guards:      mod.Guards}
+			if mod.Statement != nil {
+				site.Statement = mod.Statement.V
+			}
+
+			switch mod.Classification {
+			case imports.MODULE_CLASS_FIRST_PARTY:
+				// Modules which are not scanned are not known
+				if to, ok := modulesByPath[mod.ModulePath]; ok {
+					g.AddImport(from, to, site)
+				}
+			case imports.MODULE_CLASS_THIRD_PARTY:
+				pkg := g.AddNode(&Node{ID: dir.SplitAndGetLeftMost(mod.Name.V, "."), Kind: NODE_PACKAGE})
+				g.AddImport(from, pkg.ID, site)
+			}
+		}
+	}
+
+	return g
+}
@@ -0,0 +1,133 @@
+package modulegraph
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/safedep/codex/pkg/parser/py/imports"
+	"github.com/stretchr/testify/assert"
+)
+
+func buildGraph(t *testing.T, files map[string]string) *Graph {
+	rootDir := t.TempDir()
+	for relPath, code := range files {
+		fullPath := path.Join(rootDir, relPath)
+		assert.NoError(t, os.MkdirAll(path.Dir(fullPath), os.ModePerm))
+		assert.NoError(t, os.WriteFile(fullPath, []byte(code), 0644))
+	}
+
+	parser, err := imports.NewPyCodeParserFactory().NewCodeParser()
+	assert.NoError(t, err)
+
+	importedModules, err := parser.FindImportedModules(context.Background(), rootDir, true, []string{".py"}, []string{})
+	assert.NoError(t, err)
+
+	return Build(rootDir, importedModules.GetRepoCodeAnalysis())
+}
+
+var testFiles = map[string]string{
+	"myapp/__init__.py":       "import requests\n",
+	"myapp/models.py":         "import os\nfrom myapp.services import fetch\n",
+	"myapp/services.py":       "from . import views\nfrom typing import TYPE_CHECKING\nif TYPE_CHECKING:\n    from .models import User\n",
+	"myapp/views/__init__.py": "from ..services import fetch, store\nimport django.http\n\ndef index():\n    from myapp import models\n",
+}
+
+func TestBuild(t *testing.T) {
+	g := buildGraph(t, testFiles)
+
+	ids := []string{}
+	for _, node := range g.GetNodes() {
+		ids = append(ids, node.ID+" "+string(node.Kind)+" "+node.Path)
+	}
+	assert.Equal(t, []string{
+		"django package ",
+		"myapp module myapp/__init__.py",
+		"myapp.models module myapp/models.py",
+		"myapp.services module myapp/services.py",
+		"myapp.views module myapp/views/__init__.py",
+		"requests package ",
+	}, ids)
+
+	edges := []string{}
+	for _, edge := range g.GetEdges() {
+		edges = append(edges, edge.From+" -> "+edge.To)
+	}
+	assert.Equal(t, []string{
+		"myapp -> requests",
+		"myapp.models -> myapp.services",
+		"myapp.services -> myapp.models",
+		"myapp.services -> myapp.views",
+		"myapp.views -> django",
+		"myapp.views -> myapp.models",
+		"myapp.views -> myapp.services",
+	}, edges)
+
+	// Both names of a statement are one import of the edge
+	views := g.GetImports("myapp.views")
+	assert.Len(t, views, 3)
+	assert.Equal(t, []*ImportSite{{Path: "myapp/views/__init__.py", RowStart: 0, RowEnd: 0, ColumnEnd: 35, EndByte: 35,
+		Statement: "from ..services import fetch, store", Guards: []imports.ImportGuard{}}}, views[2].Imports)
+
+	assert.False(t, views[0].IsDeferred())
+	assert.True(t, views[1].IsDeferred())
+	assert.True(t, g.GetImports("myapp.services")[0].IsDeferred())
+	assert.False(t, g.GetImports("myapp.services")[1].IsDeferred())
+}
+
+func TestAddImport(t *testing.T) {
+	g := NewGraph()
+	g.AddNode(&Node{ID: "a", Kind: NODE_MODULE, Path: "a.py"})
+	assert.Equal(t, "a.py", g.AddNode(&Node{ID: "a", Kind: NODE_MODULE, Path: "b/a.py"}).Path)
+
+	g.AddImport("a", "a", &ImportSite{Path: "a.py"})
+	assert.Empty(t, g.GetImports("a"))
+
+	g.AddImport("a", "b", &ImportSite{Path: "a.py", RowStart: 1})
+	g.AddImport("a", "b", &ImportSite{Path: "a.py", RowStart: 1})
+	g.AddImport("a", "b", &ImportSite{Path: "a.py", RowStart: 3})
+	assert.Len(t, g.GetImports("a"), 1)
+	assert.Len(t, g.GetImports("a")[0].Imports, 2)
+}
+
+func TestWrite(t *testing.T) {
+	g := NewGraph()
+	g.AddNode(&Node{ID: "app", Kind: NODE_MODULE, Path: "app.py"})
+	g.AddNode(&Node{ID: "app.util", Kind: NODE_MODULE, Path: "app/util.py"})
+	g.AddNode(&Node{ID: "requests", Kind: NODE_PACKAGE})
+	g.AddImport("app", "app.util", &ImportSite{Path: "app.py", Guards: []imports.ImportGuard{imports.IMPORT_GUARD_LAZY}})
+	g.AddImport("app", "requests", &ImportSite{Path: "app.py", RowStart: 1})
+
+	var out bytes.Buffer
+	assert.NoError(t, Write(&out, FORMAT_DOT, g))
+	assert.Equal(t, `digraph modules {
+  rankdir=LR;
+  node [shape=box];
+  "app";
+  "app.util";
+  "requests" [shape=ellipse, style=filled, fillcolor=lightgrey];
+  "app" -> "app.util" [style=dashed];
+  "app" -> "requests";
+}
+`, out.String())
+
+	out.Reset()
+	assert.NoError(t, Write(&out, FORMAT_MERMAID, g))
+	assert.Equal(t, `flowchart LR
+  n0["app"]
+  n1["app.util"]
+  n2(["requests"])
+  n0 -.-> n1
+  n0 --> n2
+`, out.String())
+
+	out.Reset()
+	assert.NoError(t, Write(&out, FORMAT_GRAPHML, g))
+	assert.Contains(t, out.String(), `<edge source="app" target="app.util">`)
+	assert.Contains(t, out.String(), `<data key="deferred">true</data>`)
+	assert.Contains(t, out.String(), `<data key="path">app/util.py</data>`)
+
+	assert.Error(t, Write(&out, Format("svg"), g))
+}
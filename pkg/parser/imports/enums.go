@@ -0,0 +1,33 @@
+package imports
+
+// ModuleClassification tells where an imported module comes from
+type ModuleClassification string
+
+const (
+	MODULE_CLASS_STDLIB      ModuleClassification = "stdlib"
+	MODULE_CLASS_FIRST_PARTY ModuleClassification = "first-party"
+	MODULE_CLASS_THIRD_PARTY ModuleClassification = "third-party"
+	MODULE_CLASS_UNKNOWN     ModuleClassification = "unknown"
+)
+
+// ImportGuard is a condition under which an import runs
+type ImportGuard string
+
+const (
+	IMPORT_GUARD_OPTIONAL      ImportGuard = "optional"       // try/except ImportError fallback
+	IMPORT_GUARD_TYPE_CHECKING ImportGuard = "type-checking"  // if TYPE_CHECKING:
+	IMPORT_GUARD_VERSION       ImportGuard = "version-gated"  // if sys.version_info >= ...
+	IMPORT_GUARD_PLATFORM      ImportGuard = "platform-gated" // if sys.platform == ...
+	IMPORT_GUARD_LAZY          ImportGuard = "lazy"           // imported inside a function
+)
+
+// DependencyScope tells what a dependency is needed for, decided by the
+// location of the files importing it
+type DependencyScope string
+
+const (
+	SCOPE_RUNTIME DependencyScope = "runtime"
+	SCOPE_BUILD   DependencyScope = "build" // setup.py and other packaging scripts
+	SCOPE_TEST    DependencyScope = "test"  // tests, test_*.py, conftest.py
+	SCOPE_DEV     DependencyScope = "dev"   // docs, examples, scripts and tooling
+)
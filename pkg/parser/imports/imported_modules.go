@@ -0,0 +1,240 @@
+package imports
+
+import "sort"
+
+// ImportProvenance records a single place in the code base where a
+// top-level package was imported
+type ImportProvenance struct {
+	Path        string          // file path relative to the scanned directory
+	RowStart    uint32          // first row of the import statement (0 based)
+	RowEnd      uint32          // last row of the import statement (0 based)
+	ColumnStart uint32          // column of the start of the import statement (0 based, in unicode code points)
+	ColumnEnd   uint32          // column after the end of the import statement (0 based, in unicode code points)
+	StartByte   uint32          // offset of the import statement in the file
+	EndByte     uint32          // offset after the import statement in the file
+	Statement   string          // original import statement
+	Guards      []ImportGuard   // conditions under which the import runs
+	Scope       DependencyScope // scope of the importing file
+	Unused      bool            // none of the names bound by the statement are referenced
+}
+
+// ImportedModules holds the top-level packages imported by a repository
+type ImportedModules struct {
+	pkgNames     map[string]bool
+	provenance   map[string][]*ImportProvenance
+	findings     []*Finding
+	hardPkgs     map[string]bool
+	usedPkgs     map[string]bool
+	scopes       map[string]DependencyScope
+	repoAnalysis *RepoCodeAnalysis
+}
+
+func NewImportedModules() *ImportedModules {
+	return &ImportedModules{pkgNames: make(map[string]bool, 0),
+		provenance: make(map[string][]*ImportProvenance, 0),
+		hardPkgs:   make(map[string]bool, 0),
+		usedPkgs:   make(map[string]bool, 0),
+		scopes:     make(map[string]DependencyScope, 0)}
+}
+
+// AddDependency records an import of a top-level package by a file. Unused imports
+// only bind names which are never referenced.
+func (dd *ImportedModules) AddDependency(pkg string, path string, scope DependencyScope,
+	mod *ImportedModule, unused bool) {
+	dd.pkgNames[pkg] = true
+	if !mod.IsOptional() {
+		dd.hardPkgs[pkg] = true
+	}
+	if !unused {
+		dd.usedPkgs[pkg] = true
+	}
+	if current, ok := dd.scopes[pkg]; ok {
+		dd.scopes[pkg] = MoreImportantScope(current, scope)
+	} else {
+		dd.scopes[pkg] = scope
+	}
+
+	node := &mod.Name
+	if mod.Statement != nil {
+		node = mod.Statement
+	}
+	prov := &ImportProvenance{Path: path,
+		RowStart:    node.RowStart,
+		RowEnd:      node.RowEnd,
+		ColumnStart: node.ColumnStart,
+		ColumnEnd:   node.ColumnEnd,
+		StartByte:   node.StartByte,
+		EndByte:     node.EndByte,
+		Guards:      mod.Guards,
+		Scope:       scope,
+		Unused:      unused}
+	if mod.Statement != nil {
+		prov.Statement = mod.Statement.V
+	}
+
+	// The same statement can be reported more than once, record it only once
+	for _, p := range dd.provenance[pkg] {
+		if p.Path == prov.Path && p.RowStart == prov.RowStart &&
+			p.RowEnd == prov.RowEnd && p.Statement == prov.Statement {
+			return
+		}
+	}
+	dd.provenance[pkg] = append(dd.provenance[pkg], prov)
+}
+
+// SetRepoCodeAnalysis sets the analysis of every file the modules were found in,
+// and adds the findings of the files
+func (dd *ImportedModules) SetRepoCodeAnalysis(repoAnalysis *RepoCodeAnalysis) {
+	dd.repoAnalysis = repoAnalysis
+	for _, fa := range repoAnalysis.FilesAnalysis {
+		dd.findings = append(dd.findings, fa.Findings...)
+	}
+}
+
+// GetHardDependencies returns the packages imported at least once without any
+// guard other than being local to a function
+func (dd *ImportedModules) GetHardDependencies() []string {
+	pkgs := make([]string, 0)
+	for pkg := range dd.hardPkgs {
+		pkgs = append(pkgs, pkg)
+	}
+
+	sort.Strings(pkgs)
+	return pkgs
+}
+
+// GetOptionalDependencies returns the packages which are only imported under guards,
+// e.g. in try/except ImportError fallbacks or if TYPE_CHECKING blocks
+func (dd *ImportedModules) GetOptionalDependencies() []string {
+	pkgs := make([]string, 0)
+	for pkg := range dd.pkgNames {
+		if !dd.hardPkgs[pkg] {
+			pkgs = append(pkgs, pkg)
+		}
+	}
+
+	sort.Strings(pkgs)
+	return pkgs
+}
+
+// GetUnusedDependencies returns the packages whose every import statement only
+// binds names which are never referenced, so the dependency can be removed
+// together with its dead imports
+func (dd *ImportedModules) GetUnusedDependencies() []string {
+	pkgs := make([]string, 0)
+	for pkg := range dd.pkgNames {
+		if !dd.usedPkgs[pkg] {
+			pkgs = append(pkgs, pkg)
+		}
+	}
+
+	sort.Strings(pkgs)
+	return pkgs
+}
+
+// GetScope returns the most important scope of the files importing the package,
+// e.g. runtime for a package imported by runtime code and tests
+func (dd *ImportedModules) GetScope(pkg string) DependencyScope {
+	return dd.scopes[pkg]
+}
+
+// GetDependenciesOfScope returns the packages whose scope is the given scope
+func (dd *ImportedModules) GetDependenciesOfScope(scope DependencyScope) []string {
+	pkgs := make([]string, 0)
+	for pkg, s := range dd.scopes {
+		if s == scope {
+			pkgs = append(pkgs, pkg)
+		}
+	}
+
+	sort.Strings(pkgs)
+	return pkgs
+}
+
+// GetProvenance returns every place where the top-level package was imported,
+// ordered by file path and row
+func (dd *ImportedModules) GetProvenance(pkg string) []*ImportProvenance {
+	provs := make([]*ImportProvenance, len(dd.provenance[pkg]))
+	copy(provs, dd.provenance[pkg])
+
+	sort.SliceStable(provs, func(i, j int) bool {
+		if provs[i].Path != provs[j].Path {
+			return provs[i].Path < provs[j].Path
+		}
+		return provs[i].RowStart < provs[j].RowStart
+	})
+
+	return provs
+}
+
+// GetRepoCodeAnalysis returns the analysis of every file the modules were found in
+func (dd *ImportedModules) GetRepoCodeAnalysis() *RepoCodeAnalysis {
+	return dd.repoAnalysis
+}
+
+// GetParseErrors returns the files which could not be analyzed
+func (dd *ImportedModules) GetParseErrors() []*ParseError {
+	if dd.repoAnalysis == nil {
+		return nil
+	}
+	return dd.repoAnalysis.Errors
+}
+
+// GetFindings returns the issues found while analyzing the code, e.g. unresolved dynamic imports
+func (dd *ImportedModules) GetFindings() []*Finding {
+	return dd.findings
+}
+
+// GetAllProvenance returns the provenance of every top-level package
+func (dd *ImportedModules) GetAllProvenance() map[string][]*ImportProvenance {
+	provs := make(map[string][]*ImportProvenance, len(dd.pkgNames))
+	for pkg := range dd.pkgNames {
+		provs[pkg] = dd.GetProvenance(pkg)
+	}
+
+	return provs
+}
+
+func (dd *ImportedModules) GetPackagesNames() []string {
+	pkgs := make([]string, 0)
+	for pkg, _ := range dd.pkgNames {
+		pkgs = append(pkgs, pkg)
+	}
+
+	sort.Strings(pkgs)
+	return pkgs
+}
+
+// ExportedModules holds the modules a repository makes importable
+type ExportedModules struct {
+	pkgNames map[string]string
+}
+
+func NewExportedModules() *ExportedModules {
+	return &ExportedModules{pkgNames: make(map[string]string, 0)}
+}
+
+// AddModule records a module exported by the package and its path relative to the scanned directory
+func (dd *ExportedModules) AddModule(pkg string, path string) {
+	dd.pkgNames[pkg] = path
+}
+
+// GetModulePaths returns the exported modules along with their paths relative to the scanned directory
+func (dd *ExportedModules) GetModulePaths() map[string]string {
+	paths := make(map[string]string, len(dd.pkgNames))
+	for pkg, path := range dd.pkgNames {
+		paths[pkg] = path
+	}
+
+	return paths
+}
+
+func (dd *ExportedModules) GetExportedModules() []string {
+	pkgs := make([]string, 0)
+	for pkg, _ := range dd.pkgNames {
+		pkgs = append(pkgs, pkg)
+	}
+
+	sort.Strings(pkgs)
+	return pkgs
+}
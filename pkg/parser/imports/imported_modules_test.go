@@ -0,0 +1,31 @@
+package imports
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImportedModules(t *testing.T) {
+	deps := NewImportedModules()
+
+	requests := &ImportedModule{Name: TypedValue{V: "requests", RowStart: 2, RowEnd: 2}}
+	optional := &ImportedModule{Name: TypedValue{V: "ujson"}, Guards: []ImportGuard{IMPORT_GUARD_OPTIONAL}}
+
+	deps.AddDependency("requests", "tests/test_api.py", SCOPE_TEST, requests, false)
+	deps.AddDependency("requests", "app/api.py", SCOPE_RUNTIME, requests, false)
+	deps.AddDependency("ujson", "app/api.py", SCOPE_RUNTIME, optional, true)
+
+	assert.Equal(t, []string{"requests", "ujson"}, deps.GetPackagesNames())
+	assert.Equal(t, []string{"requests"}, deps.GetHardDependencies())
+	assert.Equal(t, []string{"ujson"}, deps.GetOptionalDependencies())
+	assert.Equal(t, []string{"ujson"}, deps.GetUnusedDependencies())
+	assert.Equal(t, SCOPE_RUNTIME, deps.GetScope("requests"))
+	assert.Len(t, deps.GetProvenance("requests"), 2)
+}
+
+func TestMoreImportantScope(t *testing.T) {
+	assert.Equal(t, SCOPE_RUNTIME, MoreImportantScope(SCOPE_DEV, SCOPE_RUNTIME))
+	assert.Equal(t, SCOPE_BUILD, MoreImportantScope(SCOPE_TEST, SCOPE_BUILD))
+	assert.Equal(t, SCOPE_TEST, MoreImportantScope(SCOPE_TEST, SCOPE_DEV))
+}
@@ -0,0 +1,92 @@
+/*
+	Results of the analysis of the imports of a repository, shared by the parsers
+	of every language
+*/
+
+package imports
+
+// TypedValue is a node of the code with its text and position
+type TypedValue struct {
+	T           string
+	V           string
+	RowStart    uint32
+	RowEnd      uint32
+	ColumnStart uint32 // column of the first character (0 based, in unicode code points)
+	ColumnEnd   uint32 // column after the last character (0 based, in unicode code points)
+	StartByte   uint32 // offset of the first byte in the file
+	EndByte     uint32 // offset after the last byte in the file
+}
+
+// ImportedModule is a module, or a name of a module, imported by a file
+type ImportedModule struct {
+	Name           TypedValue
+	Alias          *TypedValue
+	Definition     *TypedValue // it can be module, and other definitions
+	Statement      *TypedValue // complete import statement which imported the module
+	Classification ModuleClassification
+	Dynamic        bool          // imported at runtime, e.g. importlib.import_module("foo")
+	Loader         string        // function which imported a dynamic module
+	Guards         []ImportGuard // conditions under which the import runs, empty when it always runs
+	AbsoluteName   string        // absolute module of first-party imports, e.g. pkg.services for .services in pkg/views.py
+	ModulePath     string        // file of the repository defining the module, empty when it is not scanned
+}
+
+// IsOptional returns true when the import may not run at all at runtime, e.g. when
+// it is a fallback, only used for type checking or limited to some platform or version.
+// Function local imports are lazy but still required when the function runs.
+func (m *ImportedModule) IsOptional() bool {
+	for _, guard := range m.Guards {
+		if guard != IMPORT_GUARD_LAZY {
+			return true
+		}
+	}
+	return false
+}
+
+// FileCodeAnalysis holds the imports and the findings of a file
+type FileCodeAnalysis struct {
+	Path          string
+	Scope         DependencyScope // decided by the location of the file
+	Modules       []*ImportedModule
+	Findings      []*Finding
+	UnusedImports []*UnusedImport
+}
+
+// ParseError is a file which could not be analyzed
+type ParseError struct {
+	Path string
+	Err  error
+}
+
+// RepoCodeAnalysis holds the analysis of every file of a repository
+type RepoCodeAnalysis struct {
+	Path          string
+	FilesAnalysis []*FileCodeAnalysis
+	Errors        []*ParseError // files skipped when not failing on the first error
+}
+
+// FindingType is the kind of a finding
+type FindingType string
+
+const (
+	FINDING_UNRESOLVED_DYNAMIC_IMPORT  FindingType = "unresolved-dynamic-import"
+	FINDING_UNUSED_IMPORT              FindingType = "unused-import"
+	FINDING_UNRESOLVED_RELATIVE_IMPORT FindingType = "unresolved-relative-import"
+)
+
+// Finding is an issue found while analyzing the code
+type Finding struct {
+	Type    FindingType
+	Message string
+	Path    string     // file path of the code
+	Node    TypedValue // code the finding is about
+}
+
+// UnusedImport is a name bound by an import statement and never referenced in the file
+type UnusedImport struct {
+	Name          string     // bound name, e.g. np for import numpy as np
+	Imported      string     // imported name, e.g. numpy
+	Statement     TypedValue // import statement
+	Binding       TypedValue // imported name with its alias, e.g. numpy as np
+	DeadStatement bool       // none of the names bound by the statement are referenced
+}
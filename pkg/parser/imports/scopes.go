@@ -0,0 +1,53 @@
+package imports
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ScopeRule assigns a scope to the files matching a doublestar pattern
+// relative to the scanned directory
+type ScopeRule struct {
+	Pattern string
+	Scope   DependencyScope
+}
+
+// Scopes ordered from the most to the least important. A package imported
+// in files of several scopes has the most important of them.
+var scopesByImportance = []DependencyScope{SCOPE_RUNTIME, SCOPE_BUILD, SCOPE_TEST, SCOPE_DEV}
+
+// ParseScope validates the name of a scope
+func ParseScope(name string) (DependencyScope, error) {
+	for _, scope := range scopesByImportance {
+		if string(scope) == name {
+			return scope, nil
+		}
+	}
+
+	return "", fmt.Errorf("unknown scope %q", name)
+}
+
+// ParseScopeRule parses a rule written as <pattern>=<scope>, e.g. tools/**=dev
+func ParseScopeRule(rule string) (ScopeRule, error) {
+	pattern, name, found := strings.Cut(rule, "=")
+	if !found {
+		return ScopeRule{}, fmt.Errorf("scope rule %q is not <pattern>=<scope>", rule)
+	}
+
+	scope, err := ParseScope(strings.TrimSpace(name))
+	if err != nil {
+		return ScopeRule{}, err
+	}
+
+	return ScopeRule{Pattern: strings.TrimSpace(pattern), Scope: scope}, nil
+}
+
+// MoreImportantScope returns the most important of two scopes, runtime first
+func MoreImportantScope(a, b DependencyScope) DependencyScope {
+	for _, scope := range scopesByImportance {
+		if scope == a || scope == b {
+			return scope
+		}
+	}
+	return a
+}
@@ -0,0 +1,196 @@
+package imports
+
+import (
+	"context"
+	"encoding/json"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	shared "github.com/safedep/codex/pkg/parser/imports"
+	"github.com/safedep/dry/log"
+)
+
+// Name of the manifest of the npm packages
+const PACKAGE_JSON = "package.json"
+
+// Entry point of the packages without main nor exports
+const DEFAULT_MAIN = "index.js"
+
+// EXPORT_CONDITIONS are the conditions of the exports read first, in order of
+// preference. Other conditions are read in lexical order.
+var EXPORT_CONDITIONS = []string{"import", "require", "node", "default"}
+
+// PackageJson is the part of a package.json manifest naming the package and its entry points
+type PackageJson struct {
+	Name    string      `json:"name"`
+	Main    string      `json:"main"`
+	Exports interface{} `json:"exports"`
+
+	Path string `json:"-"` // manifest file relative to the scanned directory
+}
+
+// FindExportedModules finds the modules exported by the packages of the directory,
+// from the name and the exports of every package.json outside of node_modules
+func (cpf *CodeParser) FindExportedModules(ctx context.Context,
+	dirpath string) (*shared.ExportedModules, error) {
+	packages, err := FindPackages(dirpath)
+	if err != nil {
+		return nil, err
+	}
+
+	exportedModules := shared.NewExportedModules()
+	for _, pkg := range packages {
+		for name, path := range pkg.ExportedModules() {
+			exportedModules.AddModule(name, path)
+		}
+	}
+	return exportedModules, nil
+}
+
+// FindPackages reads every package.json of the directory, skipping installed
+// packages and hidden directories. Invalid manifests are logged and skipped.
+func FindPackages(dirpath string) ([]*PackageJson, error) {
+	packages := make([]*PackageJson, 0)
+	err := filepath.WalkDir(dirpath, func(fullPath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() {
+			name := d.Name()
+			if fullPath != dirpath && (strings.HasPrefix(name, ".") || isExcludedDir(name)) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.Name() != PACKAGE_JSON {
+			return nil
+		}
+
+		relPath, err := filepath.Rel(dirpath, fullPath)
+		if err != nil {
+			return err
+		}
+		pkg, err := readPackageJson(fullPath)
+		if err != nil {
+			log.Warnf("Error while reading %s %v", relPath, err)
+			return nil
+		}
+		pkg.Path = filepath.ToSlash(relPath)
+		packages = append(packages, pkg)
+		return nil
+	})
+
+	return packages, err
+}
+
+func readPackageJson(path string) (*PackageJson, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	pkg := &PackageJson{}
+	if err := json.Unmarshal(data, pkg); err != nil {
+		return nil, err
+	}
+	return pkg, nil
+}
+
+// isExcludedDir tells whether a directory holds installed packages
+func isExcludedDir(name string) bool {
+	for _, d := range PACKAGE_DIRS {
+		if name == d {
+			return true
+		}
+	}
+	return false
+}
+
+// ExportedModules returns the modules importable from the package with their files
+// relative to the scanned directory, e.g. lodash and lodash/fp. Subpaths of the
+// exports are modules of their own, and the package exports its main file when it
+// has no exports. Subpaths exported as null are private.
+func (p *PackageJson) ExportedModules() map[string]string {
+	modules := make(map[string]string, 0)
+	if p.Name == "" {
+		return modules
+	}
+
+	pkgDir := path.Dir(p.Path)
+	add := func(subpath string, target string) {
+		name := p.Name
+		if subpath != "." {
+			name += strings.TrimPrefix(subpath, ".")
+		}
+		modules[name] = path.Join(pkgDir, target)
+	}
+
+	exports, ok := p.Exports.(map[string]interface{})
+	switch {
+	case p.Exports == nil:
+		main := p.Main
+		if main == "" {
+			main = DEFAULT_MAIN
+		}
+		add(".", main)
+	case ok && hasSubpaths(exports):
+		for subpath, value := range exports {
+			if target, ok := targetOf(value); ok && strings.HasPrefix(subpath, ".") {
+				add(subpath, target)
+			}
+		}
+	default:
+		// The exports are the conditions of the main entry point, or its file
+		if target, ok := targetOf(p.Exports); ok {
+			add(".", target)
+		}
+	}
+
+	return modules
+}
+
+// hasSubpaths tells whether the keys of the exports are subpaths, e.g. ./fp,
+// rather than conditions, e.g. import
+func hasSubpaths(exports map[string]interface{}) bool {
+	for key := range exports {
+		if strings.HasPrefix(key, ".") {
+			return true
+		}
+	}
+	return false
+}
+
+// targetOf returns the file of an export, following its conditions and fallbacks
+func targetOf(value interface{}) (string, bool) {
+	switch v := value.(type) {
+	case string:
+		return v, true
+	case []interface{}:
+		for _, fallback := range v {
+			if target, ok := targetOf(fallback); ok {
+				return target, true
+			}
+		}
+	case map[string]interface{}:
+		conditions := make([]string, 0, len(v))
+		for condition := range v {
+			conditions = append(conditions, condition)
+		}
+		sort.Strings(conditions)
+
+		for _, condition := range append(append([]string{}, EXPORT_CONDITIONS...), conditions...) {
+			if nested, ok := v[condition]; ok {
+				if target, ok := targetOf(nested); ok {
+					return target, true
+				}
+			}
+		}
+	}
+
+	return "", false
+}
@@ -0,0 +1,57 @@
+package imports
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPackageJsonExportedModules(t *testing.T) {
+	cases := []struct {
+		manifest string
+		expected map[string]string
+	}{
+		{`{"name": "plain"}`, map[string]string{"plain": "index.js"}},
+		{`{"name": "main", "main": "./lib/main.js"}`, map[string]string{"main": "lib/main.js"}},
+		{`{"name": "single", "exports": "./dist/index.mjs"}`, map[string]string{"single": "dist/index.mjs"}},
+		{`{"name": "conditions", "exports": {"types": "./index.d.ts", "require": "./index.cjs", "import": "./index.mjs"}}`,
+			map[string]string{"conditions": "index.mjs"}},
+		{`{"name": "lodash", "exports": {".": "./lodash.js", "./fp": {"default": "./fp.js"}, "./internal/*": null,
+			"./package.json": "./package.json"}}`,
+			map[string]string{"lodash": "lodash.js", "lodash/fp": "fp.js", "lodash/package.json": "package.json"}},
+		{`{"name": "@org/pkg", "exports": {"./sub": [{"worker": "./sub.worker.js"}, "./sub.js"]}}`,
+			map[string]string{"@org/pkg/sub": "sub.worker.js"}},
+		{`{"private": true}`, map[string]string{}},
+	}
+
+	for _, c := range cases {
+		pkg := &PackageJson{Path: "package.json"}
+		assert.NoError(t, json.Unmarshal([]byte(c.manifest), pkg))
+		assert.Equal(t, c.expected, pkg.ExportedModules(), c.manifest)
+	}
+}
+
+func TestFindExportedModules(t *testing.T) {
+	rootDir := writeTestFiles(t, map[string]string{
+		"package.json":                      `{"name": "monorepo", "private": true, "workspaces": ["packages/*"]}`,
+		"packages/ui/package.json":          `{"name": "@acme/ui", "exports": {".": "./src/index.ts", "./button": "./src/button.tsx"}}`,
+		"packages/broken/package.json":      `{"name": `,
+		"node_modules/react/package.json":   `{"name": "react"}`,
+		".git/package.json":                 `{"name": "hidden"}`,
+		"packages/ui/src/index.ts":          "",
+		"packages/ui/node_modules/x/p.json": "",
+	})
+
+	parser, err := NewJsCodeParserFactory().NewCodeParser()
+	assert.NoError(t, err)
+
+	exportedModules, err := parser.FindExportedModules(context.Background(), rootDir)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"monorepo":        "index.js",
+		"@acme/ui":        "packages/ui/src/index.ts",
+		"@acme/ui/button": "packages/ui/src/button.tsx",
+	}, exportedModules.GetModulePaths())
+}
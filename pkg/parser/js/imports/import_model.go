@@ -0,0 +1,331 @@
+package imports
+
+import (
+	"fmt"
+	"unicode/utf8"
+
+	shared "github.com/safedep/codex/pkg/parser/imports"
+	"github.com/safedep/codex/pkg/utils/ts"
+	tree_sitter "github.com/smacker/go-tree-sitter"
+)
+
+// Functions importing a module at runtime
+const (
+	LOADER_REQUIRE = "require" // CommonJS require("x")
+	LOADER_IMPORT  = "import"  // dynamic import("x")
+)
+
+// Node types of the functions, whose imports only run when they are called
+var functionTypes = map[string]bool{
+	"function_declaration":           true,
+	"function":                       true,
+	"function_expression":            true,
+	"arrow_function":                 true,
+	"method_definition":              true,
+	"generator_function":             true,
+	"generator_function_declaration": true,
+}
+
+// ParsedCode is the syntax tree of a JavaScript or TypeScript file
+type ParsedCode struct {
+	codeTree *tree_sitter.Tree
+	code     []byte // Original Code Content
+	path     string // file path of the file
+	findings []*shared.Finding
+}
+
+// ExtractModules returns one module per name imported by the import and export
+// statements of the code, followed by the modules of the require() and import()
+// calls. Modules are named by their specifier, e.g. lodash/fp or ./utils.
+func (s *ParsedCode) ExtractModules() ([]*shared.ImportedModule, error) {
+	modules := make([]*shared.ImportedModule, 0)
+	calls := make([]*tree_sitter.Node, 0)
+	s.findings = make([]*shared.Finding, 0)
+
+	var walk func(node *tree_sitter.Node)
+	walk = func(node *tree_sitter.Node) {
+		switch node.Type() {
+		case "import_statement", "export_statement":
+			modules = append(modules, s.statementModulesOf(node)...)
+		case "call_expression":
+			calls = append(calls, node)
+		}
+		for i := 0; i < int(node.NamedChildCount()); i++ {
+			walk(node.NamedChild(i))
+		}
+	}
+	walk(s.codeTree.RootNode())
+
+	for _, call := range calls {
+		if mod := s.callModuleOf(call); mod != nil {
+			modules = append(modules, mod)
+		}
+	}
+
+	return modules, nil
+}
+
+// GetFindings returns the issues found by ExtractModules, e.g. require() called with a variable
+func (s *ParsedCode) GetFindings() []*shared.Finding {
+	return s.findings
+}
+
+// statementModulesOf returns the modules of an import statement, or of an export
+// statement re-exporting another module. Type-only imports are guarded by
+// IMPORT_GUARD_TYPE_CHECKING as they are erased by the compiler.
+func (s *ParsedCode) statementModulesOf(stmt *tree_sitter.Node) []*shared.ImportedModule {
+	modules := make([]*shared.ImportedModule, 0)
+
+	// import fs = require("fs") has its source in the require clause
+	source := stmt.ChildByFieldName("source")
+	requireClause := ts.FindFirstChildOfType(stmt, "import_require_clause", 1)
+	if source == nil && requireClause != nil {
+		source = requireClause.ChildByFieldName("source")
+	}
+	if source == nil {
+		return modules
+	}
+	name, ok := s.stringValueOf(source)
+	if !ok {
+		return modules
+	}
+
+	statement := s.typedValueOf(stmt)
+	guards := s.findImportGuards(stmt)
+	typeOnly := hasKeyword(stmt, "type")
+	newModule := func(definition *shared.TypedValue, alias *shared.TypedValue, typeOnlyName bool) {
+		mod := &shared.ImportedModule{Name: s.typedValueOf(source),
+			Definition: definition,
+			Alias:      alias,
+			Statement:  &statement,
+			Guards:     guards}
+		mod.Name.V = name
+		if typeOnly || typeOnlyName {
+			mod.Guards = append(append([]shared.ImportGuard{}, guards...), shared.IMPORT_GUARD_TYPE_CHECKING)
+		}
+		modules = append(modules, mod)
+	}
+
+	for i := 0; i < int(stmt.NamedChildCount()); i++ {
+		child := stmt.NamedChild(i)
+		switch child.Type() {
+		case "import_clause":
+			s.clauseModulesOf(child, newModule)
+		case "import_require_clause":
+			newModule(nil, s.valueOfFirstChild(child, "identifier"), false)
+		case "namespace_export":
+			// export * as ns from "x"
+			definition := s.valueAt(child, "*")
+			newModule(&definition, s.valueOfFirstChild(child, "identifier"), false)
+		case "export_clause":
+			for _, specifier := range childrenOfType(child, "export_specifier") {
+				s.specifierModuleOf(specifier, newModule)
+			}
+		}
+	}
+
+	if len(modules) == 0 {
+		if stmt.Type() == "export_statement" {
+			// export * from "x"
+			definition := s.valueAt(stmt, "*")
+			newModule(&definition, nil, false)
+		} else {
+			// import "x" for its side effects
+			newModule(nil, nil, false)
+		}
+	}
+
+	return modules
+}
+
+// clauseModulesOf adds the modules of the names imported by an import clause,
+// e.g. the default import d, the namespace * as ns and the named imports { a as b }
+func (s *ParsedCode) clauseModulesOf(clause *tree_sitter.Node,
+	newModule func(*shared.TypedValue, *shared.TypedValue, bool)) {
+	for i := 0; i < int(clause.NamedChildCount()); i++ {
+		child := clause.NamedChild(i)
+		switch child.Type() {
+		case "identifier":
+			definition := s.valueAt(child, "default")
+			alias := s.typedValueOf(child)
+			newModule(&definition, &alias, false)
+		case "namespace_import":
+			definition := s.valueAt(child, "*")
+			newModule(&definition, s.valueOfFirstChild(child, "identifier"), false)
+		case "named_imports":
+			for _, specifier := range childrenOfType(child, "import_specifier") {
+				s.specifierModuleOf(specifier, newModule)
+			}
+		}
+	}
+}
+
+// specifierModuleOf adds the module of a name imported or re-exported by name, e.g. a as b
+func (s *ParsedCode) specifierModuleOf(specifier *tree_sitter.Node,
+	newModule func(*shared.TypedValue, *shared.TypedValue, bool)) {
+	nameNode := specifier.ChildByFieldName("name")
+	if nameNode == nil {
+		return
+	}
+
+	definition := s.typedValueOf(nameNode)
+	var alias *shared.TypedValue
+	if aliasNode := specifier.ChildByFieldName("alias"); aliasNode != nil {
+		value := s.typedValueOf(aliasNode)
+		alias = &value
+	}
+	newModule(&definition, alias, hasKeyword(specifier, "type"))
+}
+
+// callModuleOf returns the module of a require() or import() call, nil for other
+// calls. Calls whose specifier is not a string literal are reported as findings.
+func (s *ParsedCode) callModuleOf(call *tree_sitter.Node) *shared.ImportedModule {
+	function := call.ChildByFieldName("function")
+	arguments := call.ChildByFieldName("arguments")
+	if function == nil || arguments == nil {
+		return nil
+	}
+
+	loader := ""
+	switch {
+	case function.Type() == "import":
+		loader = LOADER_IMPORT
+	case function.Type() == "identifier" && function.Content(s.code) == LOADER_REQUIRE:
+		loader = LOADER_REQUIRE
+	default:
+		return nil
+	}
+	if arguments.NamedChildCount() == 0 {
+		return nil
+	}
+
+	statement := s.typedValueOf(call)
+	argument := arguments.NamedChild(0)
+	name, ok := s.stringValueOf(argument)
+	if !ok {
+		s.findings = append(s.findings, &shared.Finding{Type: shared.FINDING_UNRESOLVED_DYNAMIC_IMPORT,
+			Message: fmt.Sprintf("unresolved dynamic import: %s called with %s",
+				loader, argument.Content(s.code)),
+			Path: s.path,
+			Node: statement})
+		return nil
+	}
+
+	mod := &shared.ImportedModule{Name: s.typedValueOf(argument),
+		Statement: &statement,
+		Dynamic:   loader == LOADER_IMPORT,
+		Loader:    loader,
+		Guards:    s.findImportGuards(call)}
+	mod.Name.V = name
+	return mod
+}
+
+// findImportGuards returns the conditions under which an import runs: inside a
+// function it is lazy, and inside the block of a try statement it is optional as
+// its failure can be caught, e.g. try { require("x") } catch {}
+func (s *ParsedCode) findImportGuards(node *tree_sitter.Node) []shared.ImportGuard {
+	guards := make([]shared.ImportGuard, 0)
+	addGuard := func(guard shared.ImportGuard) {
+		for _, g := range guards {
+			if g == guard {
+				return
+			}
+		}
+		guards = append(guards, guard)
+	}
+
+	child := node
+	for parent := node.Parent(); parent != nil; child, parent = parent, parent.Parent() {
+		switch {
+		case functionTypes[parent.Type()]:
+			addGuard(shared.IMPORT_GUARD_LAZY)
+		case parent.Type() == "try_statement":
+			if body := parent.ChildByFieldName("body"); body != nil && body.Equal(child) {
+				addGuard(shared.IMPORT_GUARD_OPTIONAL)
+			}
+		}
+	}
+
+	return guards
+}
+
+// stringValueOf returns the value of a string literal, or of a template literal
+// without substitutions
+func (s *ParsedCode) stringValueOf(node *tree_sitter.Node) (string, bool) {
+	switch node.Type() {
+	case "string":
+	case "template_string":
+		if ts.FindFirstChildOfType(node, "template_substitution", 1) != nil {
+			return "", false
+		}
+	default:
+		return "", false
+	}
+
+	value := node.Content(s.code)
+	if len(value) < 2 {
+		return "", false
+	}
+	return value[1 : len(value)-1], true
+}
+
+// hasKeyword tells whether the keyword is one of the anonymous children of the node,
+// e.g. type of import type { T } from "x"
+func hasKeyword(node *tree_sitter.Node, keyword string) bool {
+	for i := 0; i < int(node.ChildCount()); i++ {
+		child := node.Child(i)
+		if !child.IsNamed() && child.Type() == keyword {
+			return true
+		}
+	}
+	return false
+}
+
+// childrenOfType returns the named children of the node of the type
+func childrenOfType(node *tree_sitter.Node, childType string) []*tree_sitter.Node {
+	children := make([]*tree_sitter.Node, 0)
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		if child := node.NamedChild(i); child.Type() == childType {
+			children = append(children, child)
+		}
+	}
+	return children
+}
+
+// valueOfFirstChild returns the first child of the type, nil when there is none
+func (s *ParsedCode) valueOfFirstChild(node *tree_sitter.Node, childType string) *shared.TypedValue {
+	child := ts.FindFirstChildOfType(node, childType, 1)
+	if child == nil {
+		return nil
+	}
+
+	value := s.typedValueOf(child)
+	return &value
+}
+
+// valueAt returns a value located at the node, e.g. default for a default import
+func (s *ParsedCode) valueAt(node *tree_sitter.Node, v string) shared.TypedValue {
+	value := s.typedValueOf(node)
+	value.T = "identifier"
+	value.V = v
+	return value
+}
+
+func (s *ParsedCode) typedValueOf(node *tree_sitter.Node) shared.TypedValue {
+	return shared.TypedValue{T: node.Type(), V: node.Content(s.code),
+		RowStart:    node.StartPoint().Row,
+		RowEnd:      node.EndPoint().Row,
+		ColumnStart: s.columnOf(node.StartByte(), node.StartPoint().Column),
+		ColumnEnd:   s.columnOf(node.EndByte(), node.EndPoint().Column),
+		StartByte:   node.StartByte(),
+		EndByte:     node.EndByte()}
+}
+
+// columnOf converts the column of tree-sitter, in bytes from the start of the row,
+// to unicode code points
+func (s *ParsedCode) columnOf(offset uint32, byteColumn uint32) uint32 {
+	if offset > uint32(len(s.code)) || byteColumn > offset {
+		return byteColumn
+	}
+	return uint32(utf8.RuneCount(s.code[offset-byteColumn : offset]))
+}
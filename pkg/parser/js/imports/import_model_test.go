@@ -0,0 +1,136 @@
+package imports
+
+import (
+	"context"
+	"testing"
+
+	shared "github.com/safedep/codex/pkg/parser/imports"
+	"github.com/stretchr/testify/assert"
+)
+
+func extractModules(t *testing.T, path string, code string) ([]*shared.ImportedModule, *ParsedCode) {
+	parser, err := NewJsCodeParserFactory().NewCodeParser()
+	assert.NoError(t, err)
+	parsedCode, err := parser.ParseCode(context.Background(), []byte(code), path)
+	assert.NoError(t, err)
+
+	modules, err := parsedCode.ExtractModules()
+	assert.NoError(t, err)
+	return modules, parsedCode
+}
+
+// describe names a module as name:definition as alias
+func describe(mod *shared.ImportedModule) string {
+	desc := mod.Name.V
+	if mod.Definition != nil {
+		desc += ":" + mod.Definition.V
+	}
+	if mod.Alias != nil {
+		desc += " as " + mod.Alias.V
+	}
+	return desc
+}
+
+func TestExtractModulesTypeScript(t *testing.T) {
+	modules, parsedCode := extractModules(t, "app.ts", `import def, { a as b, type T } from "@org/pkg/sub";
+import * as ns from 'lodash/fp';
+import type { X } from "types-only";
+import 'side-effect';
+import fs = require("fs");
+export * from "./local";
+export { c as d } from "re-export";
+export * as e from "ns-export";
+export const f = 1;
+const x = require("cjs");
+const y = await import("dyn");
+const z = import(`+"`tpl`"+`);
+require(name);
+`)
+
+	descs := make([]string, 0, len(modules))
+	for _, mod := range modules {
+		descs = append(descs, describe(mod))
+	}
+	assert.Equal(t, []string{
+		"@org/pkg/sub:default as def",
+		"@org/pkg/sub:a as b",
+		"@org/pkg/sub:T",
+		"lodash/fp:* as ns",
+		"types-only:X",
+		"side-effect",
+		"fs as fs",
+		"./local:*",
+		"re-export:c as d",
+		"ns-export:* as e",
+		"cjs",
+		"dyn",
+		"tpl",
+	}, descs)
+
+	// Type-only imports are erased by the compiler
+	assert.Equal(t, []shared.ImportGuard{}, modules[0].Guards)
+	assert.Equal(t, []shared.ImportGuard{shared.IMPORT_GUARD_TYPE_CHECKING}, modules[2].Guards)
+	assert.Equal(t, []shared.ImportGuard{shared.IMPORT_GUARD_TYPE_CHECKING}, modules[4].Guards)
+
+	assert.Equal(t, shared.TypedValue{T: "string", V: "@org/pkg/sub", ColumnStart: 36, ColumnEnd: 50,
+		StartByte: 36, EndByte: 50}, modules[0].Name)
+	assert.Equal(t, `import def, { a as b, type T } from "@org/pkg/sub";`, modules[0].Statement.V)
+	assert.False(t, modules[0].Dynamic)
+
+	assert.Equal(t, LOADER_REQUIRE, modules[10].Loader)
+	assert.False(t, modules[10].Dynamic)
+	assert.Equal(t, `require("cjs")`, modules[10].Statement.V)
+	assert.Equal(t, LOADER_IMPORT, modules[11].Loader)
+	assert.True(t, modules[11].Dynamic)
+
+	assert.Equal(t, 1, len(parsedCode.GetFindings()))
+	finding := parsedCode.GetFindings()[0]
+	assert.Equal(t, shared.FINDING_UNRESOLVED_DYNAMIC_IMPORT, finding.Type)
+	assert.Equal(t, "unresolved dynamic import: require called with name", finding.Message)
+	assert.Equal(t, uint32(12), finding.Node.RowStart)
+}
+
+func TestExtractModulesGuards(t *testing.T) {
+	modules, _ := extractModules(t, "app.js", `import React from "react";
+function load() { return require("lazy"); }
+const arrow = () => import("arrow");
+class C { m() { require("method") } }
+try { require("optional") } catch (e) { require("fallback") }
+const el = <div>{require("jsx")}</div>;
+`)
+
+	guards := make(map[string][]shared.ImportGuard, 0)
+	for _, mod := range modules {
+		guards[mod.Name.V] = mod.Guards
+	}
+	assert.Equal(t, map[string][]shared.ImportGuard{
+		"react":    {},
+		"lazy":     {shared.IMPORT_GUARD_LAZY},
+		"arrow":    {shared.IMPORT_GUARD_LAZY},
+		"method":   {shared.IMPORT_GUARD_LAZY},
+		"optional": {shared.IMPORT_GUARD_OPTIONAL},
+		"fallback": {},
+		"jsx":      {},
+	}, guards)
+}
+
+func TestExtractModulesTsx(t *testing.T) {
+	modules, _ := extractModules(t, "App.tsx", `import type { FC } from "react";
+import { Button } from "@mui/material";
+export const App: FC = () => <Button>{"é"}</Button>;
+`)
+
+	assert.Equal(t, 2, len(modules))
+	assert.Equal(t, "react", modules[0].Name.V)
+	assert.True(t, modules[0].IsOptional())
+	assert.Equal(t, "@mui/material", modules[1].Name.V)
+	assert.False(t, modules[1].IsOptional())
+}
+
+func TestParseCodeUnsupportedExtension(t *testing.T) {
+	parser, err := NewJsCodeParserFactory().NewCodeParser()
+	assert.NoError(t, err)
+
+	_, err = parser.ParseCode(context.Background(), []byte("import os\n"), "app.py")
+	assert.ErrorContains(t, err, "unsupported file extension")
+}
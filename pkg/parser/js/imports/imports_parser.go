@@ -0,0 +1,285 @@
+/*
+	Find the modules imported by the JavaScript and TypeScript files of a repository
+*/
+
+package imports
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	shared "github.com/safedep/codex/pkg/parser/imports"
+	"github.com/safedep/codex/pkg/utils/pathfilter"
+	"github.com/safedep/codex/pkg/utils/workerpool"
+	"github.com/safedep/dry/log"
+	tree_sitter "github.com/smacker/go-tree-sitter"
+	"github.com/smacker/go-tree-sitter/javascript"
+	"github.com/smacker/go-tree-sitter/typescript/tsx"
+	"github.com/smacker/go-tree-sitter/typescript/typescript"
+)
+
+// EXTENSIONS are the extensions of the JavaScript and TypeScript files
+var EXTENSIONS = []string{".js", ".jsx", ".mjs", ".cjs", ".ts", ".tsx", ".mts", ".cts"}
+
+// DEFAULT_INCLUDES are the patterns of the files scanned when none are given
+var DEFAULT_INCLUDES = []string{"**/*.{js,jsx,mjs,cjs,ts,tsx,mts,cts}"}
+
+// PACKAGE_DIRS are the directories of the installed packages, skipped unless the
+// ignore files are not honored
+var PACKAGE_DIRS = []string{"node_modules", "bower_components", "jspm_packages"}
+
+type JsCodeParserFactory struct {
+}
+
+type CodeParser struct {
+	concurrency int // number of files parsed in parallel
+
+	// Patterns and ignore files deciding which files are scanned
+	pathFilterConfig pathfilter.Config
+
+	scopeRules []shared.ScopeRule       // user rules classifying files into scopes
+	scopes     []shared.DependencyScope // scopes of the reported dependencies, all when empty
+}
+
+func NewJsCodeParserFactory() *JsCodeParserFactory {
+	return &JsCodeParserFactory{}
+}
+
+func (cpf *JsCodeParserFactory) NewCodeParser() (*CodeParser, error) {
+	codeParser := &CodeParser{concurrency: runtime.NumCPU(),
+		pathFilterConfig: pathfilter.Config{HonorIgnores: true}}
+	return codeParser, nil
+}
+
+// SetPathFilter sets the patterns and ignore files deciding which files are scanned.
+// By default, ignore files are honored, and pathfilter.DEFAULT_EXCLUDES and
+// PACKAGE_DIRS are skipped.
+func (cpf *CodeParser) SetPathFilter(config pathfilter.Config) {
+	cpf.pathFilterConfig = config
+}
+
+// SetConcurrency sets the number of files parsed in parallel, by default the number of CPUs
+func (cpf *CodeParser) SetConcurrency(concurrency int) error {
+	if concurrency < 1 {
+		return fmt.Errorf("concurrency must be at least 1, got %d", concurrency)
+	}
+
+	cpf.concurrency = concurrency
+	return nil
+}
+
+// FindImportedModules analyzes the code repository in the specified directory and
+// returns its direct dependencies, named after their npm packages. Packages defined
+// by a package.json of the repository are first-party, e.g. the packages of a workspace.
+func (cpf *CodeParser) FindImportedModules(ctx context.Context,
+	dirpath string, failOnFirstError bool,
+	includeExtensions, excludeDirs []string) (*shared.ImportedModules, error) {
+	packages, err := FindPackages(dirpath)
+	if err != nil {
+		return nil, err
+	}
+	localPackages := make(map[string]bool, 0)
+	for _, pkg := range packages {
+		if pkg.Name != "" {
+			localPackages[pkg.Name] = true
+		}
+	}
+
+	repoAnalysis, err := cpf.findModulesRecursive(ctx, dirpath, failOnFirstError, includeExtensions, excludeDirs)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, fa := range repoAnalysis.FilesAnalysis {
+		for _, mod := range fa.Modules {
+			mod.Classification = classifyModule(mod.Name.V, localPackages)
+		}
+	}
+
+	return cpf.findUniqueModules(repoAnalysis), nil
+}
+
+// findUniqueModules finds the third-party packages imported by the analyzed files
+func (cpf *CodeParser) findUniqueModules(repoAnalysis *shared.RepoCodeAnalysis) *shared.ImportedModules {
+	dd := shared.NewImportedModules()
+	dd.SetRepoCodeAnalysis(repoAnalysis)
+
+	for _, fa := range repoAnalysis.FilesAnalysis {
+		// Only the dependencies of the selected scopes are reported.
+		if !cpf.isScopeIncluded(fa.Scope) {
+			continue
+		}
+
+		for _, mod := range fa.Modules {
+			if mod.Classification != shared.MODULE_CLASS_THIRD_PARTY {
+				continue
+			}
+			dd.AddDependency(PackageName(mod.Name.V), fa.Path, fa.Scope, mod, false)
+		}
+	}
+
+	return dd
+}
+
+// findModulesRecursive analyzes the files of a directory in parallel. The results
+// are in the order of the directory walk.
+func (cpf *CodeParser) findModulesRecursive(ctx context.Context,
+	rootDir string, failOnFirstError bool, includeExtensions, excludeDirs []string) (*shared.RepoCodeAnalysis, error) {
+	repoAnalysis := &shared.RepoCodeAnalysis{Path: rootDir}
+
+	relPaths, err := cpf.findFiles(ctx, rootDir, includeExtensions, excludeDirs)
+	if err != nil {
+		return nil, err
+	}
+
+	// ParseCode creates a parser for every file, the workers share the code parser
+	results, err := workerpool.Run(ctx, cpf.concurrency, relPaths, failOnFirstError,
+		func() *CodeParser { return cpf },
+		func(ctx context.Context, worker *CodeParser, relPath string) (*shared.FileCodeAnalysis, error) {
+			return worker.findModulesInFile(ctx, rootDir, relPath)
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, result := range results {
+		if result.Err != nil {
+			repoAnalysis.Errors = append(repoAnalysis.Errors, &shared.ParseError{Path: result.RelPath,
+				Err: result.Err})
+			continue
+		}
+
+		result.Value.Scope = cpf.classifyScope(result.RelPath)
+		repoAnalysis.FilesAnalysis = append(repoAnalysis.FilesAnalysis, result.Value)
+	}
+
+	return repoAnalysis, nil
+}
+
+// findFiles walks the directory tree and returns the paths of the files to analyze
+// relative to the root directory, in lexical order. The excluded directories are
+// glob patterns relative to the root directory, added to those of the path filter.
+func (cpf *CodeParser) findFiles(ctx context.Context,
+	rootDir string, includeExtensions, excludeDirs []string) ([]string, error) {
+	config := cpf.pathFilterConfig
+	config.Exclude = append(append([]string{}, excludeDirs...), config.Exclude...)
+	if config.HonorIgnores {
+		for _, name := range PACKAGE_DIRS {
+			config.Exclude = append(config.Exclude, "**/"+name)
+		}
+	}
+	filter, err := pathfilter.NewFilter(rootDir, config)
+	if err != nil {
+		return nil, err
+	}
+
+	relPaths := make([]string, 0)
+	err = filepath.Walk(rootDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		// Stop walking when the scan is cancelled.
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		relPath, err := filepath.Rel(rootDir, path)
+		if err != nil {
+			return err
+		}
+		relPath = filepath.ToSlash(relPath)
+
+		if info.IsDir() && filter.ExcludeDir(relPath) {
+			log.Debugf("Skipping directory .. %s", path)
+			return filepath.SkipDir
+		}
+
+		if !info.IsDir() && hasExtension(path, includeExtensions) && filter.IncludeFile(relPath) {
+			relPaths = append(relPaths, relPath)
+		}
+
+		return nil
+	})
+
+	return relPaths, err
+}
+
+func (cpf *CodeParser) findModulesInFile(ctx context.Context,
+	rootDir string, relFilePath string) (*shared.FileCodeAnalysis, error) {
+	parsedCode, err := cpf.ParseFile(ctx, rootDir, relFilePath)
+	if err != nil {
+		return nil, err
+	}
+
+	modules, err := parsedCode.ExtractModules()
+	if err != nil {
+		return nil, err
+	}
+
+	return &shared.FileCodeAnalysis{Path: relFilePath, Modules: modules,
+		Findings: parsedCode.GetFindings()}, nil
+}
+
+// ParseFile reads and parses a file with the grammar of its extension
+func (cpf *CodeParser) ParseFile(ctx context.Context, rootDir string, relFilePath string) (*ParsedCode, error) {
+	code, err := os.ReadFile(path.Join(rootDir, relFilePath))
+	if err != nil {
+		log.Debugf("Error reading file: %v", err)
+		return nil, err
+	}
+
+	return cpf.ParseCode(ctx, code, relFilePath)
+}
+
+// ParseCode parses code with the grammar of the extension of its path. A parser
+// is created for every file, so files can be parsed in parallel.
+func (cpf *CodeParser) ParseCode(ctx context.Context, code []byte, sourcePath string) (*ParsedCode, error) {
+	lang := languageOf(sourcePath)
+	if lang == nil {
+		return nil, fmt.Errorf("unsupported file extension %q", path.Ext(sourcePath))
+	}
+
+	parser := tree_sitter.NewParser()
+	parser.SetLanguage(lang)
+
+	tree, err := parser.ParseCtx(ctx, nil, code)
+	if err != nil {
+		log.Debugf("Error while parsing code %v", err)
+		return nil, err
+	}
+	if tree.RootNode() == nil {
+		return nil, fmt.Errorf("Error parsing code. Found nil root node")
+	}
+
+	return &ParsedCode{codeTree: tree, code: code, path: sourcePath}, nil
+}
+
+// languageOf returns the grammar of a file: TypeScript for .ts, .mts and .cts,
+// TSX for .tsx, and JavaScript with JSX for the other extensions
+func languageOf(sourcePath string) *tree_sitter.Language {
+	switch strings.ToLower(path.Ext(sourcePath)) {
+	case ".ts", ".mts", ".cts":
+		return typescript.GetLanguage()
+	case ".tsx":
+		return tsx.GetLanguage()
+	case ".js", ".jsx", ".mjs", ".cjs":
+		return javascript.GetLanguage()
+	}
+	return nil
+}
+
+func hasExtension(filePath string, extensions []string) bool {
+	ext := filepath.Ext(filePath)
+	for _, e := range extensions {
+		if ext == e {
+			return true
+		}
+	}
+	return false
+}
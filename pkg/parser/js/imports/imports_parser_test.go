@@ -0,0 +1,133 @@
+package imports
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"testing"
+
+	shared "github.com/safedep/codex/pkg/parser/imports"
+	"github.com/stretchr/testify/assert"
+)
+
+func writeTestFiles(t *testing.T, files map[string]string) string {
+	rootDir := t.TempDir()
+	for relPath, code := range files {
+		fullPath := path.Join(rootDir, relPath)
+		assert.NoError(t, os.MkdirAll(path.Dir(fullPath), os.ModePerm))
+		assert.NoError(t, os.WriteFile(fullPath, []byte(code), 0644))
+	}
+	return rootDir
+}
+
+func TestFindImportedModules(t *testing.T) {
+	rootDir := writeTestFiles(t, map[string]string{
+		"package.json":                      `{"name": "web", "dependencies": {"react": "^18.0.0"}}`,
+		"packages/ui/package.json":          `{"name": "@acme/ui"}`,
+		"src/index.tsx":                     "import React from 'react';\nimport { Button } from '@acme/ui';\nimport { get } from 'lodash/fp';\nimport path from 'node:path';\n",
+		"src/api.js":                        "const axios = require('axios');\nconst { z } = require('@scope/zod/v4');\nrequire(process.env.PLUGIN);\n",
+		"src/types.ts":                      "import type { Schema } from 'yup';\nimport './styles.css';\n",
+		"src/__tests__/index.test.tsx":      "import { render } from '@testing-library/react';\nimport React from 'react';\n",
+		"webpack.config.js":                 "module.exports = { plugins: [require('html-webpack-plugin')] };\n",
+		"node_modules/react/index.js":       "require('loose-envify');\n",
+		"src/legacy.py":                     "import os\n",
+		"src/broken.ts":                     "import {",
+		"packages/ui/src/button.stories.ts": "import { Meta } from '@storybook/react';\n",
+	})
+
+	parser, err := NewJsCodeParserFactory().NewCodeParser()
+	assert.NoError(t, err)
+
+	importedModules, err := parser.FindImportedModules(context.Background(), rootDir, false, EXTENSIONS, []string{})
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"@scope/zod", "@storybook/react", "@testing-library/react", "axios",
+		"html-webpack-plugin", "lodash", "react", "yup"}, importedModules.GetPackagesNames())
+	assert.Equal(t, []string{"yup"}, importedModules.GetOptionalDependencies())
+	assert.Equal(t, shared.SCOPE_RUNTIME, importedModules.GetScope("react"))
+	assert.Equal(t, shared.SCOPE_TEST, importedModules.GetScope("@testing-library/react"))
+	assert.Equal(t, shared.SCOPE_BUILD, importedModules.GetScope("html-webpack-plugin"))
+	assert.Equal(t, shared.SCOPE_DEV, importedModules.GetScope("@storybook/react"))
+
+	provenance := importedModules.GetProvenance("react")
+	assert.Equal(t, 2, len(provenance))
+	assert.Equal(t, "src/__tests__/index.test.tsx", provenance[0].Path)
+	assert.Equal(t, "src/index.tsx", provenance[1].Path)
+	assert.Equal(t, "import React from 'react';", provenance[1].Statement)
+
+	findings := importedModules.GetFindings()
+	assert.Equal(t, 1, len(findings))
+	assert.Equal(t, "src/api.js", findings[0].Path)
+
+	// Tree-sitter recovers from syntax errors, every file is analyzed
+	assert.Equal(t, 0, len(importedModules.GetParseErrors()))
+	assert.Equal(t, 7, len(importedModules.GetRepoCodeAnalysis().FilesAnalysis))
+}
+
+func TestFindImportedModulesScopes(t *testing.T) {
+	rootDir := writeTestFiles(t, map[string]string{
+		"src/app.ts":      "import express from 'express';\n",
+		"src/app.spec.ts": "import request from 'supertest';\n",
+		"tools/gen.ts":    "import { Project } from 'ts-morph';\n",
+	})
+
+	parser, err := NewJsCodeParserFactory().NewCodeParser()
+	assert.NoError(t, err)
+	assert.NoError(t, parser.SetScopeRules([]shared.ScopeRule{{Pattern: "tools/**", Scope: shared.SCOPE_DEV}}))
+	parser.SetScopes([]shared.DependencyScope{shared.SCOPE_RUNTIME, shared.SCOPE_DEV})
+
+	importedModules, err := parser.FindImportedModules(context.Background(), rootDir, true, EXTENSIONS, []string{})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"express", "ts-morph"}, importedModules.GetPackagesNames())
+	assert.Equal(t, shared.SCOPE_DEV, importedModules.GetScope("ts-morph"))
+}
+
+func TestFindImportedModulesFailOnFirstError(t *testing.T) {
+	rootDir := writeTestFiles(t, map[string]string{
+		"src/app.js": "import express from 'express';\n",
+	})
+	// A dangling link can not be read
+	assert.NoError(t, os.Symlink(path.Join(rootDir, "missing.js"), path.Join(rootDir, "src/broken.js")))
+
+	parser, err := NewJsCodeParserFactory().NewCodeParser()
+	assert.NoError(t, err)
+
+	_, err = parser.FindImportedModules(context.Background(), rootDir, true, EXTENSIONS, []string{})
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	importedModules, err := parser.FindImportedModules(context.Background(), rootDir, false, EXTENSIONS, []string{})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"express"}, importedModules.GetPackagesNames())
+	assert.Equal(t, 1, len(importedModules.GetParseErrors()))
+	assert.Equal(t, "src/broken.js", importedModules.GetParseErrors()[0].Path)
+}
+
+func TestFindImportedModulesConcurrency(t *testing.T) {
+	files := make(map[string]string, 0)
+	for i := 0; i < 50; i++ {
+		files[fmt.Sprintf("pkg%d/mod%d.js", i%7, i)] = fmt.Sprintf("import lib from 'lib%d';\nrequire('shared');\n", i%11)
+	}
+	rootDir := writeTestFiles(t, files)
+
+	var expected *shared.RepoCodeAnalysis
+	for _, concurrency := range []int{1, 2, 8, 64} {
+		parser, err := NewJsCodeParserFactory().NewCodeParser()
+		assert.NoError(t, err)
+		assert.NoError(t, parser.SetConcurrency(concurrency))
+
+		importedModules, err := parser.FindImportedModules(context.Background(), rootDir, true, EXTENSIONS, []string{})
+		assert.NoError(t, err)
+		assert.Equal(t, 12, len(importedModules.GetPackagesNames()))
+
+		if expected == nil {
+			expected = importedModules.GetRepoCodeAnalysis()
+			continue
+		}
+		assert.Equal(t, expected, importedModules.GetRepoCodeAnalysis())
+	}
+
+	parser, err := NewJsCodeParserFactory().NewCodeParser()
+	assert.NoError(t, err)
+	assert.Error(t, parser.SetConcurrency(0))
+}
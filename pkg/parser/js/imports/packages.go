@@ -0,0 +1,107 @@
+package imports
+
+import (
+	"strings"
+
+	shared "github.com/safedep/codex/pkg/parser/imports"
+)
+
+// Prefix of the built-in modules of Node.js, e.g. node:fs
+const NODE_BUILTIN_PREFIX = "node:"
+
+// NODE_BUILTIN_MODULES are the built-in modules of Node.js importable without
+// the node: prefix, as listed by require("module").builtinModules
+var NODE_BUILTIN_MODULES = map[string]bool{
+	"assert": true, "async_hooks": true, "buffer": true, "child_process": true, "cluster": true,
+	"console": true, "constants": true, "crypto": true, "dgram": true, "diagnostics_channel": true,
+	"dns": true, "domain": true, "events": true, "fs": true, "http": true, "http2": true,
+	"https": true, "inspector": true, "module": true, "net": true, "os": true, "path": true,
+	"perf_hooks": true, "process": true, "punycode": true, "querystring": true, "readline": true,
+	"repl": true, "stream": true, "string_decoder": true, "sys": true, "timers": true, "tls": true,
+	"trace_events": true, "tty": true, "url": true, "util": true, "v8": true, "vm": true,
+	"wasi": true, "worker_threads": true, "zlib": true,
+}
+
+// Maximum length of the name of an npm package
+const MAX_PACKAGE_NAME_LENGTH = 214
+
+// PackageName returns the npm package of a module specifier, e.g. lodash for lodash/fp
+// and @org/pkg for @org/pkg/sub. It is empty for relative and absolute paths, subpath
+// imports such as #internal, URLs and other specifiers which are not package names.
+func PackageName(specifier string) string {
+	parts := strings.Split(specifier, "/")
+	name := parts[0]
+	if strings.HasPrefix(name, "@") {
+		if len(parts) < 2 || !isPackageName(strings.TrimPrefix(name, "@")) {
+			return ""
+		}
+		name = name + "/" + parts[1]
+		if !isPackageName(parts[1]) {
+			return ""
+		}
+		return name
+	}
+
+	if !isPackageName(name) {
+		return ""
+	}
+	return name
+}
+
+// IsBuiltin tells whether the specifier is a built-in module of Node.js, e.g. fs,
+// fs/promises or node:test
+func IsBuiltin(specifier string) bool {
+	if strings.HasPrefix(specifier, NODE_BUILTIN_PREFIX) {
+		return true
+	}
+	return NODE_BUILTIN_MODULES[strings.Split(specifier, "/")[0]]
+}
+
+// isRelative tells whether the specifier is a path, e.g. ./utils or /src/app
+func isRelative(specifier string) bool {
+	return specifier == "." || specifier == ".." || strings.HasPrefix(specifier, "./") ||
+		strings.HasPrefix(specifier, "../") || strings.HasPrefix(specifier, "/")
+}
+
+// isPackageName checks the name of a package, or of its scope, against the rules
+// of npm. Uppercase letters are allowed for the packages published before they
+// were forbidden, e.g. JSONStream.
+func isPackageName(name string) bool {
+	if name == "" || len(name) > MAX_PACKAGE_NAME_LENGTH || name[0] == '.' || name[0] == '_' {
+		return false
+	}
+
+	for _, r := range name {
+		isLetter := (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9')
+		if !isLetter && r != '-' && r != '.' && r != '_' {
+			return false
+		}
+	}
+	return true
+}
+
+// classifyModule classifies a module specifier. Packages of the repository are
+// first-party, as are its files and the subpath imports of its package.json.
+func classifyModule(specifier string, localPackages map[string]bool) shared.ModuleClassification {
+	if specifier == "" {
+		return shared.MODULE_CLASS_UNKNOWN
+	}
+
+	if isRelative(specifier) || strings.HasPrefix(specifier, "#") {
+		return shared.MODULE_CLASS_FIRST_PARTY
+	}
+
+	if IsBuiltin(specifier) {
+		return shared.MODULE_CLASS_STDLIB
+	}
+
+	pkg := PackageName(specifier)
+	if pkg == "" {
+		return shared.MODULE_CLASS_UNKNOWN
+	}
+	if localPackages[pkg] {
+		return shared.MODULE_CLASS_FIRST_PARTY
+	}
+
+	return shared.MODULE_CLASS_THIRD_PARTY
+}
@@ -0,0 +1,59 @@
+package imports
+
+import (
+	"testing"
+
+	shared "github.com/safedep/codex/pkg/parser/imports"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPackageName(t *testing.T) {
+	cases := map[string]string{
+		"lodash":              "lodash",
+		"lodash/fp":           "lodash",
+		"@org/pkg":            "@org/pkg",
+		"@org/pkg/sub/path":   "@org/pkg",
+		"@org":                "",
+		"@/components/Button": "",
+		"~/utils":             "",
+		"./utils":             "",
+		"../lib/index.js":     "",
+		"/abs/path":           "",
+		"#internal/db":        "",
+		"https://esm.sh/x":    "",
+		"JSONStream":          "JSONStream",
+		"fs/promises":         "fs",
+		".hidden":             "",
+	}
+
+	for specifier, expected := range cases {
+		assert.Equal(t, expected, PackageName(specifier), specifier)
+	}
+}
+
+func TestIsBuiltin(t *testing.T) {
+	assert.True(t, IsBuiltin("fs"))
+	assert.True(t, IsBuiltin("fs/promises"))
+	assert.True(t, IsBuiltin("node:test"))
+	assert.False(t, IsBuiltin("lodash"))
+	assert.False(t, IsBuiltin("./fs"))
+}
+
+func TestClassifyModule(t *testing.T) {
+	localPackages := map[string]bool{"@acme/ui": true}
+	cases := map[string]shared.ModuleClassification{
+		"react":           shared.MODULE_CLASS_THIRD_PARTY,
+		"@mui/material/x": shared.MODULE_CLASS_THIRD_PARTY,
+		"@acme/ui/button": shared.MODULE_CLASS_FIRST_PARTY,
+		"./utils":         shared.MODULE_CLASS_FIRST_PARTY,
+		"#config":         shared.MODULE_CLASS_FIRST_PARTY,
+		"path":            shared.MODULE_CLASS_STDLIB,
+		"node:fs":         shared.MODULE_CLASS_STDLIB,
+		"~/components":    shared.MODULE_CLASS_UNKNOWN,
+		"":                shared.MODULE_CLASS_UNKNOWN,
+	}
+
+	for specifier, expected := range cases {
+		assert.Equal(t, expected, classifyModule(specifier, localPackages), specifier)
+	}
+}
@@ -0,0 +1,80 @@
+package imports
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/bmatcuk/doublestar/v4"
+	shared "github.com/safedep/codex/pkg/parser/imports"
+)
+
+// DEFAULT_SCOPE_RULES classify files by their location, the first matching rule wins.
+// Files matching no rule are runtime code.
+var DEFAULT_SCOPE_RULES = []shared.ScopeRule{
+	{Pattern: "**/__tests__/**", Scope: shared.SCOPE_TEST},
+	{Pattern: "**/__mocks__/**", Scope: shared.SCOPE_TEST},
+	{Pattern: "**/tests/**", Scope: shared.SCOPE_TEST},
+	{Pattern: "**/test/**", Scope: shared.SCOPE_TEST},
+	{Pattern: "**/e2e/**", Scope: shared.SCOPE_TEST},
+	{Pattern: "**/*.test.*", Scope: shared.SCOPE_TEST},
+	{Pattern: "**/*.spec.*", Scope: shared.SCOPE_TEST},
+	{Pattern: "**/*.config.*", Scope: shared.SCOPE_BUILD},
+	{Pattern: "**/Gruntfile.*", Scope: shared.SCOPE_BUILD},
+	{Pattern: "**/gulpfile.*", Scope: shared.SCOPE_BUILD},
+	{Pattern: "**/*.stories.*", Scope: shared.SCOPE_DEV},
+	{Pattern: "**/.storybook/**", Scope: shared.SCOPE_DEV},
+	{Pattern: "**/docs/**", Scope: shared.SCOPE_DEV},
+	{Pattern: "**/examples/**", Scope: shared.SCOPE_DEV},
+	{Pattern: "**/scripts/**", Scope: shared.SCOPE_DEV},
+	{Pattern: "**/benchmarks/**", Scope: shared.SCOPE_DEV},
+}
+
+// SetScopeRules sets rules classifying files into scopes. They are applied
+// before DEFAULT_SCOPE_RULES.
+func (cpf *CodeParser) SetScopeRules(rules []shared.ScopeRule) error {
+	for _, rule := range rules {
+		if !doublestar.ValidatePattern(rule.Pattern) {
+			return fmt.Errorf("invalid glob pattern %q", rule.Pattern)
+		}
+		if _, err := shared.ParseScope(string(rule.Scope)); err != nil {
+			return err
+		}
+	}
+
+	cpf.scopeRules = rules
+	return nil
+}
+
+// SetScopes limits the dependencies to the packages imported by files of the scopes.
+// Dependencies of every scope are found when empty.
+func (cpf *CodeParser) SetScopes(scopes []shared.DependencyScope) {
+	cpf.scopes = scopes
+}
+
+// classifyScope returns the scope of a file relative to the scanned directory
+func (cpf *CodeParser) classifyScope(relPath string) shared.DependencyScope {
+	relPath = filepath.ToSlash(relPath)
+	for _, rules := range [][]shared.ScopeRule{cpf.scopeRules, DEFAULT_SCOPE_RULES} {
+		for _, rule := range rules {
+			if matched, _ := doublestar.Match(rule.Pattern, relPath); matched {
+				return rule.Scope
+			}
+		}
+	}
+
+	return shared.SCOPE_RUNTIME
+}
+
+// isScopeIncluded checks if the dependencies of the scope are reported
+func (cpf *CodeParser) isScopeIncluded(scope shared.DependencyScope) bool {
+	if len(cpf.scopes) == 0 {
+		return true
+	}
+
+	for _, s := range cpf.scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,215 @@
+package imports
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/safedep/codex/pkg/utils/py/strlit"
+	tree_sitter "github.com/smacker/go-tree-sitter"
+)
+
+const CALL_QUERY = `
+(call
+	function: (_) @function
+	arguments: (argument_list) @arguments) @call
+`
+
+const (
+	LOADER_IMPORT_MODULE           = "importlib.import_module"
+	LOADER_BUILTIN_IMPORT          = "__import__"
+	LOADER_IMPORTLIB_IMPORT        = "importlib.__import__"
+	LOADER_FIND_SPEC               = "importlib.util.find_spec"
+	LOADER_SPEC_FROM_FILE_LOCATION = "importlib.util.spec_from_file_location"
+	LOADER_FIND_LOADER             = "importlib.find_loader"
+	LOADER_PKGUTIL_FIND_LOADER     = "pkgutil.find_loader"
+	LOADER_PKGUTIL_GET_LOADER      = "pkgutil.get_loader"
+	LOADER_PKGUTIL_RESOLVE_NAME    = "pkgutil.resolve_name"
+)
+
+// dynamicLoader describes the arguments of a function importing a module at runtime
+type dynamicLoader struct {
+	nameArgument    int    // position of the module name argument
+	packageArgument int    // position of the package argument anchoring relative names, -1 if none
+	packageKeyword  string // keyword of the package argument
+}
+
+var dynamicLoaders = map[string]dynamicLoader{
+	LOADER_IMPORT_MODULE:           {nameArgument: 0, packageArgument: 1, packageKeyword: "package"},
+	LOADER_BUILTIN_IMPORT:          {nameArgument: 0, packageArgument: -1},
+	LOADER_IMPORTLIB_IMPORT:        {nameArgument: 0, packageArgument: -1},
+	LOADER_FIND_SPEC:               {nameArgument: 0, packageArgument: 1, packageKeyword: "package"},
+	LOADER_SPEC_FROM_FILE_LOCATION: {nameArgument: 0, packageArgument: -1},
+	LOADER_FIND_LOADER:             {nameArgument: 0, packageArgument: -1},
+	LOADER_PKGUTIL_FIND_LOADER:     {nameArgument: 0, packageArgument: -1},
+	LOADER_PKGUTIL_GET_LOADER:      {nameArgument: 0, packageArgument: -1},
+	LOADER_PKGUTIL_RESOLVE_NAME:    {nameArgument: 0, packageArgument: -1},
+}
+
+// extractDynamicModules finds modules imported at runtime through importlib, __import__
+// and pkgutil. Calls whose module name can not be resolved statically are reported as findings.
+func (s *ParsedCode) extractDynamicModules() ([]*ImportedModule, []*Finding, error) {
+	modules := make([]*ImportedModule, 0)
+	findings := make([]*Finding, 0)
+
+	q, err := tree_sitter.NewQuery([]byte(CALL_QUERY), s.lang)
+	if err != nil {
+		return modules, findings, err
+	}
+	defer q.Close()
+
+	bindings := s.findImportBindings()
+	constants := s.findStringConstants()
+
+	qc := tree_sitter.NewQueryCursor()
+	defer qc.Close()
+	qc.Exec(q, s.codeTree.RootNode())
+	for {
+		m, ok := qc.NextMatch()
+		if !ok {
+			break
+		}
+		m = qc.FilterPredicates(m, s.code)
+
+		var callNode, functionNode, argumentsNode *tree_sitter.Node
+		for _, c := range m.Captures {
+			switch q.CaptureNameForId(c.Index) {
+			case "call":
+				callNode = c.Node
+			case "function":
+				functionNode = c.Node
+			case "arguments":
+				argumentsNode = c.Node
+			}
+		}
+
+		loaderName := qualifyName(functionNode.Content(s.code), bindings)
+		loader, ok := dynamicLoaders[loaderName]
+		if !ok {
+			continue
+		}
+
+		statement := s.typedValueOf(callNode)
+		nameNode := findArgument(argumentsNode, loader.nameArgument, "name", s.code)
+		if nameNode == nil {
+			continue
+		}
+
+		name, resolved := s.resolveString(nameNode, constants)
+		if !resolved {
+			findings = append(findings, &Finding{Type: FINDING_UNRESOLVED_DYNAMIC_IMPORT,
+				Message: fmt.Sprintf("unresolved dynamic import: %s called with %s",
+					loaderName, nameNode.Content(s.code)),
+				Path: s.path,
+				Node: *statement})
+			continue
+		}
+
+		if loaderName == LOADER_PKGUTIL_RESOLVE_NAME {
+			// pkg.module:object.attr names the module before the colon
+			name, _, _ = strings.Cut(name, ":")
+		}
+
+		// Relative names are anchored at the package argument when it is known
+		if strings.HasPrefix(name, ".") && loader.packageArgument >= 0 {
+			packageNode := findArgument(argumentsNode, loader.packageArgument, loader.packageKeyword, s.code)
+			if packageNode != nil {
+				if pkg, ok := s.resolveString(packageNode, constants); ok {
+					name = pkg + name
+				}
+			}
+		}
+
+		nameValue := s.typedValueOf(nameNode)
+		nameValue.V = name
+		modules = append(modules, &ImportedModule{Name: *nameValue,
+			Statement: statement,
+			Dynamic:   true,
+			Loader:    loaderName,
+			Guards:    s.findImportGuards(callNode)})
+	}
+
+	return modules, findings, nil
+}
+
+// findArgument returns the positional argument at the position or the keyword argument
+func findArgument(arguments *tree_sitter.Node, position int, keyword string, code []byte) *tree_sitter.Node {
+	positional := 0
+	for i := 0; i < int(arguments.NamedChildCount()); i++ {
+		arg := arguments.NamedChild(i)
+		switch arg.Type() {
+		case "keyword_argument":
+			if keyword != "" && arg.ChildByFieldName("name").Content(code) == keyword {
+				return arg.ChildByFieldName("value")
+			}
+		case "comment", "list_splat", "dictionary_splat":
+			continue
+		default:
+			if positional == position {
+				return arg
+			}
+			positional += 1
+		}
+	}
+
+	return nil
+}
+
+// resolveString evaluates a string literal or a module level string constant
+func (s *ParsedCode) resolveString(node *tree_sitter.Node, constants map[string]string) (string, bool) {
+	switch node.Type() {
+	case "string":
+		return strlit.Unquote(node.Content(s.code))
+	case "identifier":
+		value, ok := constants[node.Content(s.code)]
+		return value, ok
+	}
+
+	return "", false
+}
+
+// findStringConstants finds module level assignments of string literals, e.g. BACKEND = "foo.bar"
+func (s *ParsedCode) findStringConstants() map[string]string {
+	constants := make(map[string]string, 0)
+	reassigned := make(map[string]bool, 0)
+
+	root := s.codeTree.RootNode()
+	for i := 0; i < int(root.NamedChildCount()); i++ {
+		stmt := root.NamedChild(i)
+		if stmt.Type() != "expression_statement" || stmt.NamedChildCount() == 0 {
+			continue
+		}
+
+		assignment := stmt.NamedChild(0)
+		if assignment.Type() != "assignment" {
+			continue
+		}
+
+		left := assignment.ChildByFieldName("left")
+		right := assignment.ChildByFieldName("right")
+		if left == nil || right == nil || left.Type() != "identifier" {
+			continue
+		}
+
+		name := left.Content(s.code)
+		value, ok := s.resolveString(right, constants)
+		if !ok || reassigned[name] {
+			// A name assigned more than once is not a constant
+			delete(constants, name)
+			reassigned[name] = true
+			continue
+		}
+		if _, exists := constants[name]; exists {
+			delete(constants, name)
+			reassigned[name] = true
+			continue
+		}
+		constants[name] = value
+	}
+
+	return constants
+}
+
+func (s *ParsedCode) typedValueOf(node *tree_sitter.Node) *TypedValue {
+	value := typedValueAt(node.Type(), node.Content(s.code), s.rangeOf(node))
+	return &value
+}
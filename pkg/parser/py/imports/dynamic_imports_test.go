@@ -0,0 +1,106 @@
+package imports
+
+import (
+	"context"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const PY_DYNAMIC_IMPORTS_CODE = `import importlib
+import importlib.util
+import pkgutil
+from importlib import import_module as load
+
+BACKEND = "backends.redis"
+
+yaml = importlib.import_module("yaml")
+sub = importlib.import_module(".handlers", package="myapp")
+backend = load(BACKEND)
+pg = __import__('psycopg2.extras')
+loader = pkgutil.find_loader("ujson")
+obj = pkgutil.resolve_name("lxml.etree:fromstring")
+spec = importlib.util.spec_from_file_location("plugin", "/opt/plugin.py")
+
+def load_plugin(name):
+    return importlib.import_module(f"plugins.{name}")
+
+other = __import__(name)
+`
+
+func TestExtractDynamicModules(t *testing.T) {
+	tempFile := createTempPythonFile(t, PY_DYNAMIC_IMPORTS_CODE)
+	defer os.Remove(tempFile)
+
+	cpf := &MockCodeParserFactory{}
+	codeParser, err := cpf.NewCodeParser()
+	if err != nil {
+		t.Fatalf("Error creating CodeParser: %v", err)
+	}
+
+	ctx := context.TODO()
+	rootDir, relFilePath := path.Split(tempFile)
+	parsedCode, err := codeParser.ParseFile(ctx, rootDir, relFilePath)
+	if err != nil {
+		t.Fatalf("Error parsing file: %v", err)
+	}
+
+	modules, err := parsedCode.ExtractModules()
+	assert.NoError(t, err)
+
+	dynamicModules := map[string]*ImportedModule{}
+	for _, mod := range modules {
+		if mod.Dynamic {
+			dynamicModules[mod.Name.V] = mod
+		}
+	}
+
+	expected := map[string]string{
+		"yaml":            LOADER_IMPORT_MODULE,
+		"myapp.handlers":  LOADER_IMPORT_MODULE,
+		"backends.redis":  LOADER_IMPORT_MODULE,
+		"psycopg2.extras": LOADER_BUILTIN_IMPORT,
+		"ujson":           LOADER_PKGUTIL_FIND_LOADER,
+		"lxml.etree":      LOADER_PKGUTIL_RESOLVE_NAME,
+		"plugin":          LOADER_SPEC_FROM_FILE_LOCATION,
+	}
+	assert.Equal(t, len(expected), len(dynamicModules))
+	for name, loader := range expected {
+		mod, ok := dynamicModules[name]
+		if assert.True(t, ok, name) {
+			assert.Equal(t, loader, mod.Loader, name)
+		}
+	}
+
+	assert.Equal(t, uint32(7), dynamicModules["yaml"].Name.RowStart)
+	assert.Equal(t, `importlib.import_module("yaml")`, dynamicModules["yaml"].Statement.V)
+
+	findings := parsedCode.GetFindings()
+	assert.Equal(t, 2, len(findings))
+	for _, finding := range findings {
+		assert.Equal(t, FINDING_UNRESOLVED_DYNAMIC_IMPORT, finding.Type)
+		assert.Equal(t, relFilePath, finding.Path)
+	}
+	assert.Equal(t, uint32(16), findings[0].Node.RowStart)
+	assert.Contains(t, findings[0].Message, `f"plugins.{name}"`)
+}
+
+func TestFindImportedModulesDynamic(t *testing.T) {
+	rootDir := t.TempDir()
+	code := "import importlib\nmod = importlib.import_module('requests')\nplugin = importlib.import_module(NAME)\n"
+	assert.NoError(t, os.WriteFile(path.Join(rootDir, "app.py"), []byte(code), 0644))
+
+	cpf := &MockCodeParserFactory{}
+	codeParser, err := cpf.NewCodeParser()
+	if err != nil {
+		t.Fatalf("Error creating CodeParser: %v", err)
+	}
+
+	rootPkgs, err := codeParser.FindImportedModules(context.TODO(), rootDir, true, []string{".py"}, []string{})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"requests"}, rootPkgs.GetPackagesNames())
+	assert.Equal(t, 1, len(rootPkgs.GetFindings()))
+	assert.Equal(t, "app.py", rootPkgs.GetFindings()[0].Path)
+}
@@ -0,0 +1,75 @@
+package imports
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+
+	"github.com/safedep/codex/pkg/cache"
+	"github.com/safedep/dry/log"
+	tree_sitter "github.com/smacker/go-tree-sitter"
+)
+
+// ANALYSIS_VERSION is bumped whenever the extraction of modules from a file
+// changes, so that cached analyses of older versions are not used
+const ANALYSIS_VERSION = "6"
+
+// SetCache enables the cache of file analyses. Files whose content, grammar
+// and queries are unchanged since a previous scan are not parsed again.
+func (cpf *CodeParser) SetCache(c *cache.DiskCache) {
+	cpf.cache = c
+}
+
+// fileCacheKey identifies the analysis of a file content by the current parser.
+// The imports of __init__.py files are not reported as unused, so the analysis
+// also depends on whether the file is a package.
+func (cpf *CodeParser) fileCacheKey(code []byte, relFilePath string) string {
+	return cache.Key([]byte(ANALYSIS_VERSION), []byte(grammarVersion(cpf.lang)),
+		[]byte(IMPORT_QUERY), []byte(CALL_QUERY), []byte(strconv.FormatBool(isPackageFile(relFilePath))), code)
+}
+
+// getCachedAnalysis returns the cached analysis of the code, moved to the file path
+func (cpf *CodeParser) getCachedAnalysis(key string, relFilePath string) (*FileCodeAnalysis, bool) {
+	data, ok := cpf.cache.Get(key)
+	if !ok {
+		return nil, false
+	}
+
+	var fca FileCodeAnalysis
+	if err := json.Unmarshal(data, &fca); err != nil {
+		log.Debugf("Ignoring invalid cache entry of %s %v", relFilePath, err)
+		return nil, false
+	}
+
+	// Files with the same content share the entry
+	fca.Path = relFilePath
+	for _, f := range fca.Findings {
+		f.Path = relFilePath
+	}
+
+	return &fca, true
+}
+
+func (cpf *CodeParser) putCachedAnalysis(key string, fca *FileCodeAnalysis) {
+	data, err := json.Marshal(fca)
+	if err != nil {
+		log.Debugf("Error while encoding cache entry of %s %v", fca.Path, err)
+		return
+	}
+
+	if err := cpf.cache.Put(key, data); err != nil {
+		log.Debugf("Error while writing cache entry of %s %v", fca.Path, err)
+	}
+}
+
+// grammarVersion fingerprints the grammar by its symbols, which change with every grammar release
+func grammarVersion(lang *tree_sitter.Language) string {
+	h := sha256.New()
+	for i := uint32(0); i < lang.SymbolCount(); i++ {
+		h.Write([]byte(lang.SymbolName(tree_sitter.Symbol(i))))
+		h.Write([]byte{0})
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
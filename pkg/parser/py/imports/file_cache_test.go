@@ -0,0 +1,83 @@
+package imports
+
+import (
+	"context"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/safedep/codex/pkg/cache"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindImportedModulesCached(t *testing.T) {
+	rootDir := writeTestFiles(t, map[string]string{
+		"a.py": "import requests\nrequests.get(importlib.import_module(name))\n",
+		"b.py": "import requests\nrequests.get(importlib.import_module(name))\n",
+		"c.py": "import yaml\nyaml.load()\n",
+	})
+
+	diskCache, err := cache.NewDiskCache(t.TempDir(), cache.DEFAULT_MAX_SIZE)
+	assert.NoError(t, err)
+
+	scan := func() *ImportedModules {
+		parser, err := NewPyCodeParserFactory().NewCodeParser()
+		assert.NoError(t, err)
+		parser.SetCache(diskCache)
+
+		importedModules, err := parser.FindImportedModules(context.Background(), rootDir, true, []string{".py"}, []string{})
+		assert.NoError(t, err)
+		return importedModules
+	}
+
+	uncached := scan()
+	cached := scan()
+	assert.Equal(t, uncached.GetPackagesNames(), cached.GetPackagesNames())
+	assert.Equal(t, uncached.GetAllProvenance(), cached.GetAllProvenance())
+
+	// Files with the same content share the entry but keep their own path
+	findings := cached.GetFindings()
+	assert.Equal(t, 2, len(findings))
+	assert.Equal(t, "a.py", findings[0].Path)
+	assert.Equal(t, "b.py", findings[1].Path)
+
+	// A changed file is parsed again
+	assert.NoError(t, os.WriteFile(path.Join(rootDir, "c.py"), []byte("import numpy\n"), 0644))
+	assert.Equal(t, []string{"numpy", "requests"}, scan().GetPackagesNames())
+}
+
+func TestFindImportedModulesCachedPackage(t *testing.T) {
+	rootDir := writeTestFiles(t, map[string]string{
+		"pkg/__init__.py": "from .models import User\n",
+		"pkg/aaa.py":      "from .models import User\n",
+		"pkg/models.py":   "",
+	})
+
+	diskCache, err := cache.NewDiskCache(t.TempDir(), cache.DEFAULT_MAX_SIZE)
+	assert.NoError(t, err)
+
+	parser, err := NewPyCodeParserFactory().NewCodeParser()
+	assert.NoError(t, err)
+	parser.SetCache(diskCache)
+
+	// The imports of the package are not unused, those of the module with the same content are
+	for i := 0; i < 2; i++ {
+		importedModules, err := parser.FindImportedModules(context.Background(), rootDir, true, []string{".py"}, []string{})
+		assert.NoError(t, err)
+
+		findings := importedModules.GetFindings()
+		assert.Equal(t, 1, len(findings))
+		assert.Equal(t, "pkg/aaa.py", findings[0].Path)
+		assert.Equal(t, "User (.models.User) is imported but never used", findings[0].Message)
+	}
+}
+
+func TestFileCacheKey(t *testing.T) {
+	parser, err := NewPyCodeParserFactory().NewCodeParser()
+	assert.NoError(t, err)
+
+	key := parser.fileCacheKey([]byte("import os\n"), "pkg/a.py")
+	assert.Equal(t, key, parser.fileCacheKey([]byte("import os\n"), "lib/b.py"))
+	assert.NotEqual(t, key, parser.fileCacheKey([]byte("import sys\n"), "pkg/a.py"))
+	assert.NotEqual(t, key, parser.fileCacheKey([]byte("import os\n"), "pkg/__init__.py"))
+}
@@ -0,0 +1,178 @@
+package imports
+
+import (
+	"strings"
+
+	"github.com/safedep/codex/pkg/utils/ts"
+	tree_sitter "github.com/smacker/go-tree-sitter"
+)
+
+// Ancestors of an import which decide whether the import always runs
+var guardNodeTypes = []string{"function_definition", "try_statement", "if_statement", "with_statement"}
+
+// Exceptions which make a failing import optional when caught
+var importErrorNames = []string{"ImportError", "ModuleNotFoundError", "Exception", "BaseException"}
+
+var typeCheckingConditions = []string{"TYPE_CHECKING"}
+
+var versionConditions = []string{"sys.version_info", "sys.version", "version_info", "PY2", "PY3", "PY_VERSION"}
+
+var platformConditions = []string{"sys.platform", "os.name", "platform.system", "platform.machine",
+	"sys.implementation", "platform.python_implementation", "IS_WINDOWS", "WINDOWS", "IS_MAC", "IS_LINUX"}
+
+// findImportGuards returns the guards of the code which imports a module, nearest guard first
+func (s *ParsedCode) findImportGuards(node *tree_sitter.Node) []ImportGuard {
+	guards := make([]ImportGuard, 0)
+	addGuard := func(guard ImportGuard) {
+		for _, g := range guards {
+			if g == guard {
+				return
+			}
+		}
+		guards = append(guards, guard)
+	}
+
+	for _, ancestor := range ts.FindAllAncestorsOfTypes(node, guardNodeTypes) {
+		switch ancestor.Type() {
+		case "function_definition":
+			addGuard(IMPORT_GUARD_LAZY)
+		case "try_statement":
+			if s.catchesImportError(ancestor, node) {
+				addGuard(IMPORT_GUARD_OPTIONAL)
+			}
+		case "with_statement":
+			// with contextlib.suppress(ImportError): import foo
+			body := ancestor.ChildByFieldName("body")
+			if isWithin(node, body) && s.suppressesImportError(ancestor) {
+				addGuard(IMPORT_GUARD_OPTIONAL)
+			}
+		case "if_statement":
+			for _, guard := range s.conditionGuards(ancestor, node) {
+				addGuard(guard)
+			}
+		}
+	}
+
+	return guards
+}
+
+// catchesImportError checks if the node is in the body or handlers of a try statement
+// which handles a failing import
+func (s *ParsedCode) catchesImportError(tryNode, node *tree_sitter.Node) bool {
+	inBodyOrHandler := false
+	catches := false
+	for i := 0; i < int(tryNode.NamedChildCount()); i++ {
+		child := tryNode.NamedChild(i)
+		switch child.Type() {
+		case "block":
+			inBodyOrHandler = inBodyOrHandler || isWithin(node, child)
+		case "except_clause":
+			inBodyOrHandler = inBodyOrHandler || isWithin(node, child)
+			catches = catches || s.isImportErrorHandler(child)
+		}
+	}
+
+	return inBodyOrHandler && catches
+}
+
+func (s *ParsedCode) isImportErrorHandler(exceptClause *tree_sitter.Node) bool {
+	// A bare except: has the block as its only named child
+	if exceptClause.NamedChildCount() <= 1 {
+		return true
+	}
+
+	caught := exceptClause.NamedChild(0).Content(s.code)
+	return containsAnyName(caught, importErrorNames)
+}
+
+func (s *ParsedCode) suppressesImportError(withNode *tree_sitter.Node) bool {
+	for i := 0; i < int(withNode.NamedChildCount()); i++ {
+		child := withNode.NamedChild(i)
+		if child.Type() != "with_clause" {
+			continue
+		}
+		clause := child.Content(s.code)
+		if strings.Contains(clause, "suppress") && containsAnyName(clause, importErrorNames) {
+			return true
+		}
+	}
+	return false
+}
+
+// conditionGuards returns the guards of the if statement conditions deciding whether the node runs
+func (s *ParsedCode) conditionGuards(ifNode, node *tree_sitter.Node) []ImportGuard {
+	guards := make([]ImportGuard, 0)
+
+	// Conditions of every branch up to the branch containing the node decide if it runs
+	conditions := []string{s.getContentIfNotNil(ifNode.ChildByFieldName("condition"))}
+	inConsequence := isWithin(node, ifNode.ChildByFieldName("consequence"))
+	branchCondition := conditions[0]
+	if !inConsequence {
+		branchCondition = ""
+		for i := 0; i < int(ifNode.NamedChildCount()); i++ {
+			child := ifNode.NamedChild(i)
+			if child.Type() != "elif_clause" {
+				continue
+			}
+			condition := s.getContentIfNotNil(child.ChildByFieldName("condition"))
+			conditions = append(conditions, condition)
+			if isWithin(node, child) {
+				branchCondition = condition
+				break
+			}
+		}
+	}
+
+	// Only the branch taken while type checking is limited to type checking,
+	// else branches of if TYPE_CHECKING run at runtime
+	if containsAnyName(branchCondition, typeCheckingConditions) && !strings.HasPrefix(branchCondition, "not ") {
+		guards = append(guards, IMPORT_GUARD_TYPE_CHECKING)
+	}
+
+	allConditions := strings.Join(conditions, " ")
+	if containsAnyName(allConditions, versionConditions) {
+		guards = append(guards, IMPORT_GUARD_VERSION)
+	}
+	if containsAnyName(allConditions, platformConditions) {
+		guards = append(guards, IMPORT_GUARD_PLATFORM)
+	}
+
+	return guards
+}
+
+// isWithin checks if the node is inside the container node
+func isWithin(node, container *tree_sitter.Node) bool {
+	if node == nil || container == nil {
+		return false
+	}
+	return node.StartByte() >= container.StartByte() && node.EndByte() <= container.EndByte()
+}
+
+// containsAnyName checks if the code refers to any of the names, ignoring
+// matches which are only part of a longer name
+func containsAnyName(code string, names []string) bool {
+	isNameChar := func(b byte) bool {
+		return b == '_' || (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z') || (b >= '0' && b <= '9')
+	}
+
+	for _, name := range names {
+		offset := 0
+		for {
+			idx := strings.Index(code[offset:], name)
+			if idx < 0 {
+				break
+			}
+			start := offset + idx
+			end := start + len(name)
+			startsName := start == 0 || (!isNameChar(code[start-1]) && code[start-1] != '.') ||
+				(code[start-1] == '.' && !strings.Contains(name, "."))
+			endsName := end == len(code) || !isNameChar(code[end])
+			if startsName && endsName {
+				return true
+			}
+			offset = end
+		}
+	}
+
+	return false
+}
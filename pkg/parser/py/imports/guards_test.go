@@ -0,0 +1,120 @@
+package imports
+
+import (
+	"context"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const PY_GUARDED_IMPORTS_CODE = `import os
+from typing import TYPE_CHECKING
+from contextlib import suppress
+
+try:
+    import ujson as json
+except ImportError:
+    import json
+
+if TYPE_CHECKING:
+    from mypy_boto3_s3 import S3Client
+else:
+    import boto3
+
+if sys.version_info >= (3, 11):
+    import tomllib
+elif sys.platform == "win32":
+    import winreg
+
+if os.name == "nt":
+    import colorama
+
+with suppress(ModuleNotFoundError):
+    import rich
+
+def handler():
+    import numpy
+    try:
+        import pandas
+    except Exception:
+        pandas = None
+
+try:
+    import requests
+finally:
+    pass
+`
+
+func TestFindImportGuards(t *testing.T) {
+	tempFile := createTempPythonFile(t, PY_GUARDED_IMPORTS_CODE)
+	defer os.Remove(tempFile)
+
+	cpf := &MockCodeParserFactory{}
+	codeParser, err := cpf.NewCodeParser()
+	if err != nil {
+		t.Fatalf("Error creating CodeParser: %v", err)
+	}
+
+	rootDir, relFilePath := path.Split(tempFile)
+	parsedCode, err := codeParser.ParseFile(context.TODO(), rootDir, relFilePath)
+	if err != nil {
+		t.Fatalf("Error parsing file: %v", err)
+	}
+
+	modules, err := parsedCode.ExtractModules()
+	assert.NoError(t, err)
+
+	guards := map[string][]ImportGuard{}
+	for _, mod := range modules {
+		guards[mod.Name.V] = mod.Guards
+	}
+
+	expected := map[string][]ImportGuard{
+		"os":            {},
+		"ujson":         {IMPORT_GUARD_OPTIONAL},
+		"json":          {IMPORT_GUARD_OPTIONAL},
+		"mypy_boto3_s3": {IMPORT_GUARD_TYPE_CHECKING},
+		"boto3":         {},
+		"tomllib":       {IMPORT_GUARD_VERSION},
+		"winreg":        {IMPORT_GUARD_VERSION, IMPORT_GUARD_PLATFORM},
+		"colorama":      {IMPORT_GUARD_PLATFORM},
+		"rich":          {IMPORT_GUARD_OPTIONAL},
+		"numpy":         {IMPORT_GUARD_LAZY},
+		"pandas":        {IMPORT_GUARD_OPTIONAL, IMPORT_GUARD_LAZY},
+		"requests":      {},
+	}
+
+	for name, expectedGuards := range expected {
+		assert.Equal(t, expectedGuards, guards[name], name)
+	}
+}
+
+func TestFindImportedModulesHardAndOptional(t *testing.T) {
+	rootDir := t.TempDir()
+	files := map[string]string{
+		"a.py": "try:\n    import yaml\nexcept ImportError:\n    yaml = None\n\ndef f():\n    import numpy\n",
+		"b.py": "import yaml\nif TYPE_CHECKING:\n    import pandas\n",
+	}
+	for name, code := range files {
+		assert.NoError(t, os.WriteFile(path.Join(rootDir, name), []byte(code), 0644))
+	}
+
+	cpf := &MockCodeParserFactory{}
+	codeParser, err := cpf.NewCodeParser()
+	if err != nil {
+		t.Fatalf("Error creating CodeParser: %v", err)
+	}
+
+	rootPkgs, err := codeParser.FindImportedModules(context.TODO(), rootDir, true, []string{".py"}, []string{})
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"numpy", "yaml"}, rootPkgs.GetHardDependencies())
+	assert.Equal(t, []string{"pandas"}, rootPkgs.GetOptionalDependencies())
+
+	provs := rootPkgs.GetProvenance("yaml")
+	assert.Equal(t, 2, len(provs))
+	assert.Equal(t, []ImportGuard{IMPORT_GUARD_OPTIONAL}, provs[0].Guards)
+	assert.Empty(t, provs[1].Guards)
+}
@@ -0,0 +1,102 @@
+package imports
+
+import (
+	"strings"
+
+	"github.com/safedep/codex/pkg/utils/ts"
+	tree_sitter "github.com/smacker/go-tree-sitter"
+)
+
+// ImportBinding is a name bound by an import statement
+type ImportBinding struct {
+	Name      string        // bound name, e.g. np for import numpy as np
+	Qualified string        // name it refers to, e.g. numpy
+	Imported  string        // imported name, e.g. a.b for import a.b which binds a
+	Aliased   bool          // bound with as, e.g. import numpy as np
+	Node      TypedValue    // import statement
+	Binding   TypedValue    // imported name with its alias, e.g. numpy as np
+	Guards    []ImportGuard // conditions under which the import runs
+}
+
+// findImportBindings maps the names bound by import statements to the qualified
+// names they refer to, e.g. il -> importlib for import importlib as il
+func (s *ParsedCode) findImportBindings() map[string]string {
+	bindings := make(map[string]string, 0)
+	for _, binding := range s.findImportBindingList() {
+		bindings[binding.Name] = binding.Qualified
+	}
+	return bindings
+}
+
+// findImportBindingList returns the names bound by import statements in the order of the code
+func (s *ParsedCode) findImportBindingList() []*ImportBinding {
+	bindings := make([]*ImportBinding, 0)
+	bind := func(statement *tree_sitter.Node, binding *tree_sitter.Node, name string, qualified string,
+		imported string, aliased bool) {
+		bindings = append(bindings, &ImportBinding{Name: name, Qualified: qualified,
+			Imported: imported,
+			Aliased:  aliased,
+			Node:     *s.typedValueOf(statement),
+			Binding:  *s.typedValueOf(binding),
+			Guards:   s.findImportGuards(statement)})
+	}
+
+	var walk func(node *tree_sitter.Node)
+	walk = func(node *tree_sitter.Node) {
+		switch node.Type() {
+		case "import_statement":
+			for _, name := range ts.FindChildrenByFieldName(node, "name") {
+				if name.Type() == "aliased_import" {
+					imported := name.ChildByFieldName("name").Content(s.code)
+					bind(node, name, name.ChildByFieldName("alias").Content(s.code), imported, imported, true)
+				} else {
+					// import a.b binds a
+					imported := name.Content(s.code)
+					top := strings.Split(imported, ".")[0]
+					bind(node, name, top, top, imported, false)
+				}
+			}
+			return
+		case "import_from_statement":
+			moduleName := node.ChildByFieldName("module_name").Content(s.code)
+			for _, name := range ts.FindChildrenByFieldName(node, "name") {
+				if name.Type() == "aliased_import" {
+					imported := joinModuleName(moduleName, name.ChildByFieldName("name").Content(s.code))
+					bind(node, name, name.ChildByFieldName("alias").Content(s.code), imported, imported, true)
+				} else {
+					imported := joinModuleName(moduleName, name.Content(s.code))
+					bind(node, name, name.Content(s.code), imported, imported, false)
+				}
+			}
+			return
+		}
+
+		for i := 0; i < int(node.NamedChildCount()); i++ {
+			walk(node.NamedChild(i))
+		}
+	}
+	walk(s.codeTree.RootNode())
+
+	return bindings
+}
+
+// joinModuleName joins a module name and a name imported from it, e.g. .utils for from . import utils
+func joinModuleName(moduleName string, name string) string {
+	if strings.HasSuffix(moduleName, ".") {
+		return moduleName + name
+	}
+	return moduleName + "." + name
+}
+
+// qualifyName replaces the first part of a dotted name with the name it is bound to
+func qualifyName(name string, bindings map[string]string) string {
+	first, rest, hasRest := strings.Cut(name, ".")
+	qualified, ok := bindings[first]
+	if !ok {
+		return name
+	}
+	if !hasRest {
+		return qualified
+	}
+	return qualified + "." + rest
+}
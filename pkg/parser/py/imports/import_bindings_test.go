@@ -0,0 +1,47 @@
+package imports
+
+import (
+	"context"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindImportBindingList(t *testing.T) {
+	tempFile := createTempPythonFile(t, "import os.path\nimport numpy as np\nfrom . import utils\nfrom a.b import c as d\n")
+	defer os.Remove(tempFile)
+
+	codeParser, err := (&MockCodeParserFactory{}).NewCodeParser()
+	assert.NoError(t, err)
+
+	rootDir, relFilePath := path.Split(tempFile)
+	parsedCode, err := codeParser.ParseFile(context.TODO(), rootDir, relFilePath)
+	assert.NoError(t, err)
+
+	bindings := parsedCode.findImportBindingList()
+	assert.Len(t, bindings, 4)
+
+	expected := []ImportBinding{
+		{Name: "os", Qualified: "os", Imported: "os.path"},
+		{Name: "np", Qualified: "numpy", Imported: "numpy", Aliased: true},
+		{Name: "utils", Qualified: ".utils", Imported: ".utils"},
+		{Name: "d", Qualified: "a.b.c", Imported: "a.b.c", Aliased: true},
+	}
+	for i, binding := range bindings {
+		assert.Equal(t, expected[i].Name, binding.Name)
+		assert.Equal(t, expected[i].Qualified, binding.Qualified)
+		assert.Equal(t, expected[i].Imported, binding.Imported)
+		assert.Equal(t, expected[i].Aliased, binding.Aliased)
+		assert.Equal(t, uint32(i), binding.Node.RowStart)
+	}
+}
+
+func TestQualifyName(t *testing.T) {
+	bindings := map[string]string{"np": "numpy", "il": "importlib"}
+
+	assert.Equal(t, "numpy.array", qualifyName("np.array", bindings))
+	assert.Equal(t, "importlib", qualifyName("il", bindings))
+	assert.Equal(t, "os.path.join", qualifyName("os.path.join", bindings))
+}
@@ -0,0 +1,161 @@
+package imports
+
+import (
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	tree_sitter "github.com/smacker/go-tree-sitter"
+)
+
+// Module of the statements such as from __future__ import annotations
+const FUTURE_MODULE = "__future__"
+
+// SourceRange is the location of a node in the code. Rows and columns are 0 based,
+// columns count unicode code points and bytes are offsets in the file. The end
+// column and the end byte are after the last character.
+type SourceRange struct {
+	StartByte   uint32
+	EndByte     uint32
+	RowStart    uint32
+	RowEnd      uint32
+	ColumnStart uint32
+	ColumnEnd   uint32
+}
+
+// Import is a name imported by an import statement. A statement importing
+// several names is one Import per name, e.g. from a import b, c.
+type Import struct {
+	Module      string      // module path without the dots of relative imports, e.g. os.path or empty for from . import x
+	Name        string      // name imported from the module, e.g. path for from os import path, empty for import os
+	Alias       string      // name bound with as, e.g. np for import numpy as np
+	Level       int         // number of leading dots of relative imports, 0 for absolute imports
+	Star        bool        // from a import *
+	Statement   SourceRange // complete import statement
+	Range       SourceRange // imported name with its alias, e.g. numpy as np
+	ModuleRange SourceRange // module as written, e.g. ..utils of from ..utils import x
+
+	statement *tree_sitter.Node
+}
+
+// ModuleName returns the module as written in the statement, with the dots of relative imports
+func (i *Import) ModuleName() string {
+	return strings.Repeat(".", i.Level) + i.Module
+}
+
+// ExtractImports returns every name imported by the import statements of the
+// code, in the order of the code. Dynamic imports are not included.
+func (s *ParsedCode) ExtractImports() ([]*Import, error) {
+	q, err := tree_sitter.NewQuery([]byte(IMPORT_QUERY), s.lang)
+	if err != nil {
+		return nil, err
+	}
+	defer q.Close()
+
+	qc := tree_sitter.NewQueryCursor()
+	defer qc.Close()
+	qc.Exec(q, s.codeTree.RootNode())
+
+	imports := make([]*Import, 0)
+	for {
+		m, ok := qc.NextMatch()
+		if !ok {
+			break
+		}
+		m = qc.FilterPredicates(m, s.code)
+
+		imp := &Import{}
+		for _, c := range m.Captures {
+			switch q.CaptureNameForId(c.Index) {
+			case "statement":
+				imp.Statement = s.rangeOf(c.Node)
+				imp.statement = c.Node
+				if c.Node.Type() == "future_import_statement" {
+					imp.Module = FUTURE_MODULE
+					imp.ModuleRange = imp.Statement
+				}
+			case "module":
+				imp.Module = c.Node.Content(s.code)
+				imp.ModuleRange = s.rangeOf(c.Node)
+			case "relative":
+				imp.ModuleRange = s.rangeOf(c.Node)
+				// The prefix of dots is followed by the module, if any
+				relative := c.Node.Content(s.code)
+				module := strings.TrimLeft(relative, ".")
+				imp.Level = len(relative) - len(module)
+				imp.Module = strings.TrimSpace(module)
+			case "name":
+				imp.Name = c.Node.Content(s.code)
+			case "alias":
+				imp.Alias = c.Node.Content(s.code)
+			case "star":
+				imp.Star = true
+			case "binding":
+				imp.Range = s.rangeOf(c.Node)
+			}
+		}
+
+		imports = append(imports, imp)
+	}
+
+	sort.SliceStable(imports, func(i, j int) bool {
+		return imports[i].Range.StartByte < imports[j].Range.StartByte
+	})
+	return imports, nil
+}
+
+// importedModuleOf converts an import to the module it imports. The imported name
+// is the definition of the module, * for star imports.
+func (s *ParsedCode) importedModuleOf(imp *Import) *ImportedModule {
+	mod := &ImportedModule{
+		Name:      typedValueAt("dotted_name", imp.ModuleName(), imp.ModuleRange),
+		Statement: s.typedValueOf(imp.statement),
+		Guards:    s.findImportGuards(imp.statement),
+	}
+	if imp.Level > 0 {
+		mod.Name.T = "relative_import"
+	}
+	if imp.Name != "" || imp.Star {
+		definition := imp.Name
+		if imp.Star {
+			definition = "*"
+		}
+		value := typedValueAt("dotted_name", definition, imp.Range)
+		mod.Definition = &value
+	}
+
+	if imp.Alias != "" {
+		value := typedValueAt("identifier", imp.Alias, imp.Range)
+		mod.Alias = &value
+	}
+
+	return mod
+}
+
+// typedValueAt returns a value located at the range of the code
+func typedValueAt(t string, v string, r SourceRange) TypedValue {
+	return TypedValue{T: t, V: v,
+		RowStart:    r.RowStart,
+		RowEnd:      r.RowEnd,
+		ColumnStart: r.ColumnStart,
+		ColumnEnd:   r.ColumnEnd,
+		StartByte:   r.StartByte,
+		EndByte:     r.EndByte}
+}
+
+func (s *ParsedCode) rangeOf(node *tree_sitter.Node) SourceRange {
+	return SourceRange{StartByte: node.StartByte(), EndByte: node.EndByte(),
+		RowStart:    node.StartPoint().Row,
+		RowEnd:      node.EndPoint().Row,
+		ColumnStart: s.columnOf(node.StartByte(), node.StartPoint().Column),
+		ColumnEnd:   s.columnOf(node.EndByte(), node.EndPoint().Column)}
+}
+
+// columnOf converts the column of tree-sitter, in bytes from the start of the row,
+// to unicode code points
+func (s *ParsedCode) columnOf(offset uint32, byteColumn uint32) uint32 {
+	if offset > uint32(len(s.code)) || byteColumn > offset {
+		return byteColumn
+	}
+	return uint32(utf8.RuneCount(s.code[offset-byteColumn : offset]))
+}
@@ -0,0 +1,137 @@
+package imports
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var updateGolden = flag.Bool("update", false, "update the golden files of the import model")
+
+const IMPORT_FIXTURES_DIR = "../../../../tests/data/examples/code/py/imports"
+
+func TestExtractImports(t *testing.T) {
+	rootDir := writeTestFiles(t, map[string]string{"app.py": `import numpy as np, os.path
+from .. import settings as conf
+from .utils import *
+from __future__ import annotations
+`})
+
+	parser, err := NewPyCodeParserFactory().NewCodeParser()
+	assert.NoError(t, err)
+	parsedCode, err := parser.ParseFile(context.Background(), rootDir, "app.py")
+	assert.NoError(t, err)
+
+	imports, err := parsedCode.ExtractImports()
+	assert.NoError(t, err)
+	assert.Equal(t, 5, len(imports))
+
+	assert.Equal(t, &Import{Module: "numpy", Alias: "np",
+		Statement:   SourceRange{StartByte: 0, EndByte: 27, ColumnEnd: 27},
+		Range:       SourceRange{StartByte: 7, EndByte: 18, ColumnStart: 7, ColumnEnd: 18},
+		ModuleRange: SourceRange{StartByte: 7, EndByte: 12, ColumnStart: 7, ColumnEnd: 12},
+		statement:   imports[0].statement}, imports[0])
+	assert.Equal(t, "os.path", imports[1].Module)
+	assert.Equal(t, "", imports[1].Alias)
+
+	assert.Equal(t, "", imports[2].Module)
+	assert.Equal(t, "settings", imports[2].Name)
+	assert.Equal(t, "conf", imports[2].Alias)
+	assert.Equal(t, 2, imports[2].Level)
+	assert.Equal(t, "..", imports[2].ModuleName())
+	assert.Equal(t, uint32(1), imports[2].Range.RowStart)
+	assert.Equal(t, SourceRange{StartByte: 33, EndByte: 35, RowStart: 1, RowEnd: 1, ColumnStart: 5, ColumnEnd: 7},
+		imports[2].ModuleRange)
+
+	assert.Equal(t, "utils", imports[3].Module)
+	assert.Equal(t, 1, imports[3].Level)
+	assert.True(t, imports[3].Star)
+
+	assert.Equal(t, FUTURE_MODULE, imports[4].Module)
+	assert.Equal(t, "annotations", imports[4].Name)
+}
+
+func TestExtractImportsColumns(t *testing.T) {
+	// Columns count unicode code points, bytes count bytes
+	rootDir := writeTestFiles(t, map[string]string{"app.py": "s = 'é'; import yaml\n"})
+
+	parser, err := NewPyCodeParserFactory().NewCodeParser()
+	assert.NoError(t, err)
+	parsedCode, err := parser.ParseFile(context.Background(), rootDir, "app.py")
+	assert.NoError(t, err)
+
+	modules, err := parsedCode.ExtractModules()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(modules))
+	assert.Equal(t, TypedValue{T: "dotted_name", V: "yaml", ColumnStart: 16, ColumnEnd: 20, StartByte: 17, EndByte: 21},
+		modules[0].Name)
+	assert.Equal(t, uint32(9), modules[0].Statement.ColumnStart)
+	assert.Equal(t, uint32(10), modules[0].Statement.StartByte)
+}
+
+func TestExtractModulesOnePerName(t *testing.T) {
+	rootDir := writeTestFiles(t, map[string]string{"app.py": `import numpy as np
+from django.db import models, connection as conn
+`})
+
+	parser, err := NewPyCodeParserFactory().NewCodeParser()
+	assert.NoError(t, err)
+	parsedCode, err := parser.ParseFile(context.Background(), rootDir, "app.py")
+	assert.NoError(t, err)
+
+	modules, err := parsedCode.ExtractModules()
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(modules))
+
+	assert.Equal(t, "numpy", modules[0].Name.V)
+	assert.Nil(t, modules[0].Definition)
+	assert.Equal(t, "np", modules[0].Alias.V)
+
+	assert.Equal(t, "django.db", modules[1].Name.V)
+	assert.Equal(t, "models", modules[1].Definition.V)
+	assert.Nil(t, modules[1].Alias)
+
+	assert.Equal(t, "django.db", modules[2].Name.V)
+	assert.Equal(t, "connection", modules[2].Definition.V)
+	assert.Equal(t, "conn", modules[2].Alias.V)
+	assert.Equal(t, "from django.db import models, connection as conn", modules[2].Statement.V)
+}
+
+// TestExtractImportsGolden compares the imports of the fixtures with their golden
+// files, run go test -run TestExtractImportsGolden -update to update them
+func TestExtractImportsGolden(t *testing.T) {
+	fixtures, err := filepath.Glob(filepath.Join(IMPORT_FIXTURES_DIR, "*.py"))
+	assert.NoError(t, err)
+	assert.NotEmpty(t, fixtures)
+
+	parser, err := NewPyCodeParserFactory().NewCodeParser()
+	assert.NoError(t, err)
+
+	for _, fixture := range fixtures {
+		t.Run(filepath.Base(fixture), func(t *testing.T) {
+			parsedCode, err := parser.ParseFile(context.Background(), IMPORT_FIXTURES_DIR, filepath.Base(fixture))
+			assert.NoError(t, err)
+
+			imports, err := parsedCode.ExtractImports()
+			assert.NoError(t, err)
+			actual, err := json.MarshalIndent(imports, "", "  ")
+			assert.NoError(t, err)
+			actual = append(actual, '\n')
+
+			golden := strings.TrimSuffix(fixture, ".py") + ".imports.json"
+			if *updateGolden {
+				assert.NoError(t, os.WriteFile(golden, actual, 0644))
+			}
+
+			expected, err := os.ReadFile(golden)
+			assert.NoError(t, err)
+			assert.Equal(t, string(expected), string(actual))
+		})
+	}
+}
@@ -6,6 +6,8 @@ import (
 	"path"
 	"testing"
 
+	"github.com/safedep/codex/pkg/utils/pathfilter"
+	"github.com/safedep/codex/pkg/utils/py/stdlib"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -145,3 +147,127 @@ func cleanupTempFile(tempFile *os.File) {
 	tempFile.Close()
 	os.Remove(tempFile.Name())
 }
+
+func TestFindImportedModulesProvenance(t *testing.T) {
+	rootDir := t.TempDir()
+	files := map[string]string{
+		"app.py":      "import os\nimport requests\n",
+		"lib/util.py": "from requests.adapters import (\n\tHTTPAdapter)\n",
+	}
+	for name, code := range files {
+		fullPath := path.Join(rootDir, name)
+		assert.NoError(t, os.MkdirAll(path.Dir(fullPath), os.ModePerm))
+		assert.NoError(t, os.WriteFile(fullPath, []byte(code), 0644))
+	}
+
+	cpf := &MockCodeParserFactory{}
+	codeParser, err := cpf.NewCodeParser()
+	if err != nil {
+		t.Fatalf("Error creating CodeParser: %v", err)
+	}
+
+	ctx := context.TODO()
+	rootPkgs, err := codeParser.FindImportedModules(ctx, rootDir, true, []string{".py"}, []string{})
+	assert.NoError(t, err)
+
+	provs := rootPkgs.GetProvenance("requests")
+	assert.Equal(t, 2, len(provs))
+
+	assert.Equal(t, "app.py", provs[0].Path)
+	assert.Equal(t, uint32(1), provs[0].RowStart)
+	assert.Equal(t, uint32(1), provs[0].RowEnd)
+	assert.Equal(t, "import requests", provs[0].Statement)
+
+	assert.Equal(t, "lib/util.py", provs[1].Path)
+	assert.Equal(t, uint32(0), provs[1].RowStart)
+	assert.Equal(t, uint32(1), provs[1].RowEnd)
+	assert.Equal(t, "from requests.adapters import (\n\tHTTPAdapter)", provs[1].Statement)
+
+	assert.Equal(t, 1, len(rootPkgs.GetAllProvenance()))
+	assert.Empty(t, rootPkgs.GetProvenance("numpy"))
+}
+
+func TestFindImportedModulesClassification(t *testing.T) {
+	rootDir := t.TempDir()
+	files := map[string]string{
+		"manage.py":         "import json\nimport config\nimport myapp.views\nfrom django.conf import settings\n",
+		"config.py":         "import argparse\nimport requests\n",
+		"myapp/__init__.py": "",
+		"myapp/views.py":    "from .models import User\nimport yaml\nimport distutils\n",
+		"myapp/models.py":   "import unittest\n",
+	}
+	for name, code := range files {
+		fullPath := path.Join(rootDir, name)
+		assert.NoError(t, os.MkdirAll(path.Dir(fullPath), os.ModePerm))
+		assert.NoError(t, os.WriteFile(fullPath, []byte(code), 0644))
+	}
+
+	cpf := &MockCodeParserFactory{}
+	codeParser, err := cpf.NewCodeParser()
+	if err != nil {
+		t.Fatalf("Error creating CodeParser: %v", err)
+	}
+
+	ctx := context.TODO()
+	rootPkgs, err := codeParser.FindImportedModules(ctx, rootDir, true, []string{".py"}, []string{})
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"django", "requests", "yaml"}, rootPkgs.GetPackagesNames())
+
+	assert.NoError(t, codeParser.SetPythonVersion("3.12"))
+	rootPkgs, err = codeParser.FindImportedModules(ctx, rootDir, true, []string{".py"}, []string{})
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"distutils", "django", "requests", "yaml"}, rootPkgs.GetPackagesNames())
+
+	assert.Error(t, codeParser.SetPythonVersion("4.0"))
+}
+
+func TestClassifyModule(t *testing.T) {
+	stdlibModules, err := stdlib.NewStdlibModules(stdlib.DEFAULT_VERSION)
+	assert.NoError(t, err)
+
+	rootPackages := map[string]string{"myapp": "src/myapp"}
+	localModules := map[string]bool{"config": true}
+
+	tests := []struct {
+		name     string
+		expected ModuleClassification
+	}{
+		{"os.path", MODULE_CLASS_STDLIB},
+		{"myapp.views", MODULE_CLASS_FIRST_PARTY},
+		{"..helper", MODULE_CLASS_FIRST_PARTY},
+		{"config", MODULE_CLASS_FIRST_PARTY},
+		{"numpy.linalg", MODULE_CLASS_THIRD_PARTY},
+		{"", MODULE_CLASS_UNKNOWN},
+		{"not-a-module", MODULE_CLASS_UNKNOWN},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.expected, classifyModule(test.name, rootPackages, localModules, stdlibModules), test.name)
+	}
+}
+
+func TestFindImportedModulesExcludes(t *testing.T) {
+	// Absolute root directory, excluded directories at any depth
+	rootDir := writeTestFiles(t, map[string]string{
+		"app/main.py":            "import requests\n",
+		"test/test_main.py":      "import pytest\n",
+		"app/test/test_views.py": "import mock\n",
+		".venv/lib/site.py":      "import venvpkg\n",
+		"build/lib/main.py":      "import buildpkg\n",
+		".gitignore":             "build/\n",
+	})
+
+	parser, err := NewPyCodeParserFactory().NewCodeParser()
+	assert.NoError(t, err)
+
+	importedModules, err := parser.FindImportedModules(context.Background(), rootDir, true,
+		[]string{".py"}, []string{"**/test"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"requests"}, importedModules.GetPackagesNames())
+
+	parser.SetPathFilter(pathfilter.Config{Include: []string{"test/**"}})
+	importedModules, err = parser.FindImportedModules(context.Background(), rootDir, true,
+		[]string{".py"}, []string{})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"pytest"}, importedModules.GetPackagesNames())
+}
@@ -10,9 +10,13 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"runtime"
 	"strings"
 
+	"github.com/safedep/codex/pkg/cache"
+	"github.com/safedep/codex/pkg/utils/pathfilter"
 	"github.com/safedep/codex/pkg/utils/py/dir"
+	"github.com/safedep/codex/pkg/utils/py/stdlib"
 	"github.com/safedep/dry/log"
 	tree_sitter "github.com/smacker/go-tree-sitter"
 	"github.com/smacker/go-tree-sitter/python"
@@ -24,150 +28,119 @@ const FUNC_DEFINITION_QUERY = `
 	parameters: (parameters) @method_params) @method
 `
 
+// IMPORT_QUERY matches every name imported by an import statement. Captures are
+// read by their names, see ExtractImports.
 const IMPORT_QUERY = `
-(import_statement 
-	name: ((dotted_name) @module_name
-	)
-)
+(import_statement
+	name: (dotted_name) @module @binding) @statement
 
-(import_from_statement
-	 module_name: (dotted_name) @module_name
-	 name: (dotted_name 
-			(identifier) @submodule_name @submodule_alias
-	)
-)
+(import_statement
+	name: (aliased_import
+		name: (dotted_name) @module
+		alias: (identifier) @alias) @binding) @statement
 
 (import_from_statement
-	 module_name: (relative_import) @module_name
-	 name: (dotted_name 
-			(identifier) @submodule_name @submodule_alias
-	)
-)
-
-(import_statement 
-	 name: (aliased_import
-			 name: (
-				 (dotted_name) @module_name @submodule_name
-			 )
-			 alias: (identifier) @submodule_alias
-	)
-)
+	module_name: [(dotted_name) @module (relative_import) @relative]
+	name: (dotted_name) @name @binding) @statement
 
 (import_from_statement
-	 module_name: (dotted_name) @module_name
-	 name: (aliased_import
-			 name: (dotted_name 
-				(identifier) @submodule_name
-				)
-			 alias: (identifier) @submodule_alias
-			 
-	)
-)
+	module_name: [(dotted_name) @module (relative_import) @relative]
+	name: (aliased_import
+		name: (dotted_name) @name
+		alias: (identifier) @alias) @binding) @statement
 
 (import_from_statement
-	 module_name: (relative_import) @module_name
-	 name: (aliased_import
-			 name: (
-				 (dotted_name) @submodule_name
-			 )
-			 alias: (identifier) @submodule_alias
-			 
-	)
-)
-
-`
+	module_name: [(dotted_name) @module (relative_import) @relative]
+	(wildcard_import) @star @binding) @statement
 
-type TypedValue struct {
-	T        string
-	V        string
-	RowStart uint32
-	RowEnd   uint32
-}
+(future_import_statement
+	name: (dotted_name) @name @binding) @statement
 
-type ImportedModule struct {
-	Name       TypedValue
-	Alias      *TypedValue
-	Definition *TypedValue // it can be module, and other definitions
-}
+(future_import_statement
+	name: (aliased_import
+		name: (dotted_name) @name
+		alias: (identifier) @alias) @binding) @statement
+`
 
-type FileCodeAnalysis struct {
-	Path    string
-	Modules []*ImportedModule
+type PyCodeParserFactory struct {
 }
 
-type RepoCodeAnalysis struct {
-	Path          string
-	FilesAnalysis []*FileCodeAnalysis
-}
+type CodeParser struct {
+	parser        *tree_sitter.Parser
+	lang          *tree_sitter.Language
+	stdlibModules *stdlib.StdlibModules
+	concurrency   int              // number of files parsed in parallel
+	cache         *cache.DiskCache // cache of file analyses, nil when disabled
 
-type ImportedModules struct {
-	pkgNames map[string]bool
-}
+	// Patterns and ignore files deciding which files are scanned
+	pathFilterConfig pathfilter.Config
 
-func NewImportedModules() *ImportedModules {
-	return &ImportedModules{pkgNames: make(map[string]bool, 0)}
+	scopeRules []ScopeRule       // user rules classifying files into scopes
+	scopes     []DependencyScope // scopes of the reported dependencies, all when empty
 }
 
-func (dd *ImportedModules) addDependency(pkg string, path string) {
-	dd.pkgNames[pkg] = true
-}
-
-func (dd *ImportedModules) GetPackagesNames() []string {
-	pkgs := make([]string, 0)
-	for pkg, _ := range dd.pkgNames {
-		pkgs = append(pkgs, pkg)
-	}
-
-	return pkgs
+type ParsedCode struct {
+	codeTree      *tree_sitter.Tree
+	code          []byte // Original Code Content
+	lang          *tree_sitter.Language
+	path          string // file path of the file
+	findings      []*Finding
+	unusedImports []*UnusedImport
 }
 
-type ExportedModules struct {
-	pkgNames map[string]string
+func NewPyCodeParserFactory() *PyCodeParserFactory {
+	return &PyCodeParserFactory{}
 }
 
-func NewExportedModules() *ExportedModules {
-	return &ExportedModules{pkgNames: make(map[string]string, 0)}
+func (cpf *PyCodeParserFactory) NewCodeParser() (*CodeParser, error) {
+	// TODO: Detect the language. Currently, assuming it is Python code.
+	lang := python.GetLanguage()
+	parser := tree_sitter.NewParser()
+	parser.SetLanguage(lang)
+	codeParser := &CodeParser{parser: parser, lang: lang, concurrency: runtime.NumCPU(),
+		pathFilterConfig: pathfilter.Config{HonorIgnores: true}}
+	return codeParser, nil
 }
 
-func (dd *ExportedModules) addModule(pkg string, path string) {
-	dd.pkgNames[pkg] = path
+// SetPathFilter sets the patterns and ignore files deciding which files are scanned.
+// By default, ignore files are honored and pathfilter.DEFAULT_EXCLUDES are skipped.
+func (cpf *CodeParser) SetPathFilter(config pathfilter.Config) {
+	cpf.pathFilterConfig = config
 }
 
-func (dd *ExportedModules) GetExportedModules() []string {
-	pkgs := make([]string, 0)
-	for pkg, _ := range dd.pkgNames {
-		pkgs = append(pkgs, pkg)
+// SetConcurrency sets the number of files parsed in parallel, by default the number of CPUs
+func (cpf *CodeParser) SetConcurrency(concurrency int) error {
+	if concurrency < 1 {
+		return fmt.Errorf("concurrency must be at least 1, got %d", concurrency)
 	}
 
-	return pkgs
-}
-
-type PyCodeParserFactory struct {
+	cpf.concurrency = concurrency
+	return nil
 }
 
-type CodeParser struct {
-	parser *tree_sitter.Parser
-	lang   *tree_sitter.Language
-}
+// SetPythonVersion sets the Python version whose standard library is used to
+// classify imported modules. By default, any Python 3 standard library module
+// is classified as stdlib.
+func (cpf *CodeParser) SetPythonVersion(version string) error {
+	stdlibModules, err := stdlib.NewStdlibModules(version)
+	if err != nil {
+		return err
+	}
 
-type ParsedCode struct {
-	codeTree *tree_sitter.Tree
-	code     []byte // Original Code Content
-	lang     *tree_sitter.Language
-	path     string // file path of the file
+	cpf.stdlibModules = stdlibModules
+	return nil
 }
 
-func NewPyCodeParserFactory() *PyCodeParserFactory {
-	return &PyCodeParserFactory{}
-}
+func (cpf *CodeParser) getStdlibModules() (*stdlib.StdlibModules, error) {
+	if cpf.stdlibModules == nil {
+		stdlibModules, err := stdlib.NewStdlibModules(stdlib.DEFAULT_VERSION)
+		if err != nil {
+			return nil, err
+		}
+		cpf.stdlibModules = stdlibModules
+	}
 
-func (cpf *PyCodeParserFactory) NewCodeParser() (*CodeParser, error) {
-	// TODO: Detect the language. Currently, assuming it is Python code.
-	lang := python.GetLanguage()
-	parser := tree_sitter.NewParser()
-	parser.SetLanguage(lang)
-	codeParser := &CodeParser{parser: parser, lang: lang}
-	return codeParser, nil
+	return cpf.stdlibModules, nil
 }
 
 // FindImportedModules analyzes the code repository in the specified directory and returns its direct dependencies.
@@ -181,11 +154,27 @@ func (cpf *CodeParser) FindImportedModules(ctx context.Context,
 	repoAnalysis, err := cpf.findModulesRecursive(ctx, dirpath, failOnFirstError, includeExtensions, excludeDirs)
 	if err != nil {
 		// If there is an error during analysis, return an error.
-		return nil, nil
+		return nil, err
+	}
+
+	// Classify every imported module as stdlib, first-party or third-party.
+	err = cpf.classifyModules(dirpath, rootPackages, repoAnalysis)
+	if err != nil {
+		return nil, err
+	}
+
+	// Resolve the first-party modules, relative ones included, to their files.
+	cpf.resolveModules(dirpath, repoAnalysis)
+
+	// Keep the cache within its size limit.
+	if cpf.cache != nil {
+		if err := cpf.cache.Prune(); err != nil {
+			log.Warnf("Error while pruning the cache %v", err)
+		}
 	}
 
 	// Find unique modules/packages in the analyzed code files.
-	dd := cpf.findUniqueModules(rootPackages, repoAnalysis)
+	dd := cpf.findUniqueModules(repoAnalysis)
 
 	// Return the direct dependencies found.
 	return dd, nil
@@ -198,43 +187,44 @@ func (cpf *CodeParser) FindExportedModules(ctx context.Context,
 	rootPackages, _ := dir.FindTopLevelModules(dirpath)
 	exportedModules := NewExportedModules()
 	for name, path := range rootPackages {
-		exportedModules.addModule(name, path)
+		exportedModules.AddModule(name, path)
 	}
 	// Return the direct dependencies found.
 	return exportedModules, nil
 }
 
-// findUniqueModules finds and returns unique modules/packages from the analyzed code files.
-func (cpf *CodeParser) findUniqueModules(rootPackages map[string]string,
-	repoAnalysis *RepoCodeAnalysis) *ImportedModules {
+// findUniqueModules finds and returns unique third-party modules/packages from the analyzed code files.
+func (cpf *CodeParser) findUniqueModules(repoAnalysis *RepoCodeAnalysis) *ImportedModules {
 	// Create a new ImportedModules instance to store the results.
 	dd := NewImportedModules()
-
-	// Create a map to track unique module/package names.
-	uniqueModNames := map[string]bool{}
+	dd.SetRepoCodeAnalysis(repoAnalysis)
 
 	// Iterate through the analyzed code files.
 	for _, fa := range repoAnalysis.FilesAnalysis {
-		for _, mod := range fa.Modules {
-			// Initialize flags to check for relative and local imports.
-			isRelativeImport := false
-			isImportLocal := false
-
-			// Iterate through the root packages to check for relative and local imports.
-			for pkg := range rootPackages {
-				isRelativeImport = isRelativeImport || strings.HasPrefix(mod.Name.V, ".")
-				isImportLocal = isImportLocal || strings.HasPrefix(mod.Name.V, fmt.Sprintf("%s.", pkg)) || mod.Name.V == pkg
+		// Only the dependencies of the selected scopes are reported.
+		if !cpf.isScopeIncluded(fa.Scope) {
+			continue
+		}
+
+		// Start bytes of the import statements binding only unused names.
+		deadStatements := make(map[uint32]bool, 0)
+		for _, u := range fa.UnusedImports {
+			if u.DeadStatement {
+				deadStatements[u.Statement.StartByte] = true
 			}
+		}
 
-			// If it's neither a relative nor local import, consider it a direct dependency.
-			if !isRelativeImport && !isImportLocal {
-				// Extract the top-level package name.
-				topLevelPkg := dir.SplitAndGetLeftMost(mod.Name.V, ".")
-				// Add the top-level package as a direct dependency.
-				dd.addDependency(topLevelPkg, fa.Path)
-				// Mark the package name as unique.
-				uniqueModNames[topLevelPkg] = true
+		for _, mod := range fa.Modules {
+			// Standard library and first-party imports are not dependencies.
+			if mod.Classification != MODULE_CLASS_THIRD_PARTY {
+				continue
 			}
+
+			// Extract the top-level package name.
+			topLevelPkg := dir.SplitAndGetLeftMost(mod.Name.V, ".")
+			// Add the top-level package as a direct dependency.
+			unused := !mod.Dynamic && mod.Statement != nil && deadStatements[mod.Statement.StartByte]
+			dd.AddDependency(topLevelPkg, fa.Path, fa.Scope, mod, unused)
 		}
 	}
 
@@ -242,65 +232,170 @@ func (cpf *CodeParser) findUniqueModules(rootPackages map[string]string,
 	return dd
 }
 
-// findModulesRecursive recursively analyzes code files in a directory.
-func (cpf *CodeParser) findModulesRecursive(ctx context.Context,
-	rootDir string, failOnFirstError bool, includeExtensions, excludeDirs []string) (*RepoCodeAnalysis, error) {
-	// Create a RepoCodeAnalysis instance to store the analysis results.
-	repoAnalysis := &RepoCodeAnalysis{Path: rootDir}
+// classifyModules sets the classification of every module imported in the analyzed code files.
+func (cpf *CodeParser) classifyModules(dirpath string, rootPackages map[string]string,
+	repoAnalysis *RepoCodeAnalysis) error {
+	stdlibModules, err := cpf.getStdlibModules()
+	if err != nil {
+		return err
+	}
 
-	// Walk through the directory tree to analyze files and subdirectories.
-	err := filepath.Walk(rootDir, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
+	// Modules in the repository are importable by their top-level name as well.
+	localModules := findLocalModules(dirpath, repoAnalysis)
+
+	for _, fa := range repoAnalysis.FilesAnalysis {
+		for _, mod := range fa.Modules {
+			if mod.Loader == LOADER_SPEC_FROM_FILE_LOCATION {
+				// Modules loaded from a file are part of the code base
+				mod.Classification = MODULE_CLASS_FIRST_PARTY
+				continue
+			}
+			mod.Classification = classifyModule(mod.Name.V, rootPackages, localModules, stdlibModules)
 		}
+	}
 
-		// Check if the directory should be excluded based on the provided exclusion list.
-		if info.IsDir() && cpf.shouldExcludeDir(path, excludeDirs) {
-			log.Debugf("Skipping directory .. %s", path)
-			return filepath.SkipDir
+	return nil
+}
+
+func classifyModule(name string, rootPackages map[string]string,
+	localModules map[string]bool, stdlibModules *stdlib.StdlibModules) ModuleClassification {
+	if name == "" {
+		return MODULE_CLASS_UNKNOWN
+	}
+
+	// Relative imports always refer to the package itself.
+	if strings.HasPrefix(name, ".") {
+		return MODULE_CLASS_FIRST_PARTY
+	}
+
+	topLevelPkg := dir.SplitAndGetLeftMost(name, ".")
+	if _, ok := rootPackages[topLevelPkg]; ok || localModules[topLevelPkg] {
+		return MODULE_CLASS_FIRST_PARTY
+	}
+
+	if stdlibModules.IsStdlib(topLevelPkg) {
+		return MODULE_CLASS_STDLIB
+	}
+
+	if !isIdentifier(topLevelPkg) {
+		return MODULE_CLASS_UNKNOWN
+	}
+
+	return MODULE_CLASS_THIRD_PARTY
+}
+
+// findLocalModules finds the top-level modules defined by files which are not part of a package,
+// e.g. config.py next to manage.py can be imported as config.
+func findLocalModules(dirpath string, repoAnalysis *RepoCodeAnalysis) map[string]bool {
+	localModules := make(map[string]bool, 0)
+	for _, fa := range repoAnalysis.FilesAnalysis {
+		fileDir := filepath.Dir(fa.Path)
+		if _, err := os.Stat(filepath.Join(dirpath, fileDir, "__init__.py")); err == nil {
+			continue
 		}
 
-		// Check if the file should be included based on its extension and analyze it if so.
-		if !info.IsDir() && cpf.shouldIncludeFile(path, includeExtensions) {
-			relPath, err := dir.RelativePath(rootDir, path)
-			if err != nil {
-				log.Debugf("Error while getting relative path %s", err)
-				return err
-			}
-			fa, err := cpf.findModulesInFile(ctx, rootDir, relPath)
-			if err != nil {
-				log.Debugf("Error while parsing the file %s", path)
-				if failOnFirstError {
-					// If failOnFirstError is true, return the error immediately.
-					return err
-				}
-				// If failOnFirstError is false, continue analyzing other files.
-				return nil
-			}
-			// Append the file analysis results to the repository analysis.
-			repoAnalysis.FilesAnalysis = append(repoAnalysis.FilesAnalysis, fa)
+		moduleName := strings.TrimSuffix(filepath.Base(fa.Path), filepath.Ext(fa.Path))
+		localModules[moduleName] = true
+	}
+
+	return localModules
+}
+
+func isIdentifier(name string) bool {
+	for i, r := range name {
+		isLetter := r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || r > 127
+		if !isLetter && (i == 0 || r < '0' || r > '9') {
+			return false
 		}
+	}
 
-		return nil
-	})
+	return name != ""
+}
+
+// findModulesRecursive recursively analyzes code files in a directory. Files are
+// parsed by a pool of workers, the results are in the order of the directory walk.
+func (cpf *CodeParser) findModulesRecursive(ctx context.Context,
+	rootDir string, failOnFirstError bool, includeExtensions, excludeDirs []string) (*RepoCodeAnalysis, error) {
+	// Create a RepoCodeAnalysis instance to store the analysis results.
+	repoAnalysis := &RepoCodeAnalysis{Path: rootDir}
+
+	// Find the files to analyze, skipping excluded directories.
+	relPaths, err := cpf.findFiles(ctx, rootDir, includeExtensions, excludeDirs)
+	if err != nil {
+		return nil, err
+	}
 
+	// Analyze the files in parallel.
+	results, err := cpf.analyzeFiles(ctx, rootDir, relPaths, failOnFirstError)
 	if err != nil {
 		// If there is an error during analysis, return the error.
 		return nil, err
 	}
 
+	for _, result := range results {
+		if result.err != nil {
+			// If failOnFirstError is false, failing files are reported along with the results.
+			repoAnalysis.Errors = append(repoAnalysis.Errors, &ParseError{Path: result.relPath, Err: result.err})
+			continue
+		}
+		// The scope depends on the location of the file, it is not part of the cached analysis.
+		result.fa.Scope = cpf.classifyScope(result.relPath)
+
+		// Append the file analysis results to the repository analysis.
+		repoAnalysis.FilesAnalysis = append(repoAnalysis.FilesAnalysis, result.fa)
+	}
+
 	// Return the RepoCodeAnalysis instance containing the analysis results.
 	return repoAnalysis, nil
 }
 
-// Helper function to check if a directory should be excluded
-func (cpf *CodeParser) shouldExcludeDir(dirPath string, excludeDirs []string) bool {
-	for _, excludeDir := range excludeDirs {
-		if dirPath == excludeDir {
-			return true
-		}
+// findFiles walks the directory tree and returns the paths of the files to analyze
+// relative to the root directory, in lexical order. The excluded directories are
+// glob patterns relative to the root directory, added to those of the path filter.
+func (cpf *CodeParser) findFiles(ctx context.Context,
+	rootDir string, includeExtensions, excludeDirs []string) ([]string, error) {
+	config := cpf.pathFilterConfig
+	config.Exclude = append(append([]string{}, excludeDirs...), config.Exclude...)
+	filter, err := pathfilter.NewFilter(rootDir, config)
+	if err != nil {
+		return nil, err
 	}
-	return false
+
+	relPaths := make([]string, 0)
+	err = filepath.Walk(rootDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		// Stop walking when the scan is cancelled.
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		relPath, err := dir.RelativePath(rootDir, path)
+		if err != nil {
+			log.Debugf("Error while getting relative path %s", err)
+			return err
+		}
+		if relPath == "" {
+			relPath = "."
+		}
+
+		// Check if the directory should be excluded by the patterns and ignore files.
+		if info.IsDir() && filter.ExcludeDir(relPath) {
+			log.Debugf("Skipping directory .. %s", path)
+			return filepath.SkipDir
+		}
+
+		// Check if the file should be included based on its extension and the patterns.
+		if !info.IsDir() && cpf.shouldIncludeFile(path, includeExtensions) && filter.IncludeFile(relPath) {
+			relPaths = append(relPaths, relPath)
+		}
+
+		return nil
+	})
+
+	return relPaths, err
 }
 
 // Helper function to check if a file should be included based on its extension
@@ -316,6 +411,9 @@ func (cpf *CodeParser) shouldIncludeFile(filePath string, includeExtensions []st
 
 func (cpf *CodeParser) findModulesInFile(ctx context.Context,
 	rootDir string, relFilePath string) (*FileCodeAnalysis, error) {
+	if cpf.cache != nil {
+		return cpf.findModulesInFileCached(ctx, rootDir, relFilePath)
+	}
 
 	parsedCode, err := cpf.ParseFile(ctx, rootDir, relFilePath)
 	if err != nil {
@@ -323,13 +421,51 @@ func (cpf *CodeParser) findModulesInFile(ctx context.Context,
 		return nil, err
 	}
 
+	return cpf.analyzeParsedCode(parsedCode, rootDir, relFilePath)
+}
+
+// findModulesInFileCached returns the cached analysis of the file content, or analyzes and caches it
+func (cpf *CodeParser) findModulesInFileCached(ctx context.Context,
+	rootDir string, relFilePath string) (*FileCodeAnalysis, error) {
+	code, err := os.ReadFile(path.Join(rootDir, relFilePath))
+	if err != nil {
+		log.Debugf("Error reading file: %v", err)
+		return nil, err
+	}
+
+	key := cpf.fileCacheKey(code, relFilePath)
+	if fca, ok := cpf.getCachedAnalysis(key, relFilePath); ok {
+		log.Debugf("Using cached analysis of %s", relFilePath)
+		return fca, nil
+	}
+
+	parsedCode, err := cpf.parseCode(ctx, nil, code, relFilePath)
+	if err != nil {
+		log.Debugf("Error while parsing file to parsed code")
+		return nil, err
+	}
+
+	fca, err := cpf.analyzeParsedCode(parsedCode, rootDir, relFilePath)
+	if err != nil {
+		return nil, err
+	}
+
+	cpf.putCachedAnalysis(key, fca)
+	return fca, nil
+}
+
+func (cpf *CodeParser) analyzeParsedCode(parsedCode *ParsedCode,
+	rootDir string, relFilePath string) (*FileCodeAnalysis, error) {
+
 	modules, err := parsedCode.ExtractModules()
 	if err != nil {
 		log.Debugf("Error while extracting modules from the file %s %s", rootDir, relFilePath)
 		return nil, err
 	}
 
-	fca := &FileCodeAnalysis{Modules: modules, Path: relFilePath}
+	fca := &FileCodeAnalysis{Modules: modules, Path: relFilePath,
+		Findings:      parsedCode.GetFindings(),
+		UnusedImports: parsedCode.GetUnusedImports()}
 	return fca, nil
 
 }
@@ -388,48 +524,38 @@ func (cp *CodeParser) parseCode(ctx context.Context,
 		lang: cp.lang, path: sourcePath}, nil
 }
 
+// ExtractModules returns one module per name imported by the import statements
+// of the code, followed by the modules imported at runtime
 func (s *ParsedCode) ExtractModules() ([]*ImportedModule, error) {
-	// Parse source code
-	lang := s.lang
-	// Execute the query
-	q, err := tree_sitter.NewQuery([]byte(IMPORT_QUERY), lang)
 	modules := make([]*ImportedModule, 0)
+	imports, err := s.ExtractImports()
 	if err != nil {
 		return modules, err
 	}
-	qc := tree_sitter.NewQueryCursor()
-	qc.Exec(q, s.codeTree.RootNode())
-	// Iterate over query results
-	for {
-		m, ok := qc.NextMatch()
-		if !ok {
-			break
-		}
-		// Apply predicates filtering
-		m = qc.FilterPredicates(m, s.code)
-		mod := ImportedModule{}
-		var value TypedValue
-		for i, c := range m.Captures {
-			value = TypedValue{T: c.Node.Type(), V: c.Node.Content(s.code),
-				RowStart: c.Node.StartPoint().Row,
-				RowEnd:   c.Node.EndPoint().Row}
-
-			// fmt.Printf("%d, %s %s\n", i, c.Node.Type(), c.Node.Content(s.code))
-
-			if i == 0 {
-				// Module Name
-				mod.Name = value
-			} else if i == 1 {
-				// Any definitions
-				mod.Definition = &value
-			} else if i == 2 {
-				// Any alias
-				mod.Alias = &value
-			}
+	for _, imp := range imports {
+		modules = append(modules, s.importedModuleOf(imp))
+	}
 
-			modules = append(modules, &mod)
-		}
+	// Modules imported at runtime by importlib, __import__ and pkgutil
+	dynamicModules, findings, err := s.extractDynamicModules()
+	if err != nil {
+		return modules, err
 	}
+	modules = append(modules, dynamicModules...)
+
+	// Names bound by imports and never referenced
+	s.unusedImports = s.FindUnusedImports()
+	s.findings = append(findings, s.unusedImportFindings(s.unusedImports)...)
 
 	return modules, nil
 }
+
+// GetFindings returns the issues found by ExtractModules, e.g. unresolved dynamic imports
+func (s *ParsedCode) GetFindings() []*Finding {
+	return s.findings
+}
+
+// GetUnusedImports returns the unused imports found by ExtractModules
+func (s *ParsedCode) GetUnusedImports() []*UnusedImport {
+	return s.unusedImports
+}
@@ -1,9 +1,8 @@
 package imports
 
 import (
-	"fmt"
-
 	"github.com/safedep/codex/pkg/utils/ts"
+	"github.com/safedep/dry/log"
 	tree_sitter "github.com/smacker/go-tree-sitter"
 )
 
@@ -16,9 +15,9 @@ type MethodInfo struct {
 }
 
 type MethodMapKey struct {
-	path      string // source file path
-	name      string // method name
-	className string // if any
+	Path      string // source file path
+	Name      string // method name
+	ClassName string // if any
 }
 
 type MethodMap struct {
@@ -60,7 +59,7 @@ func (s *ParsedCode) MakeMethodMap() (*MethodMap, error) {
 		methodParams := s.getContentIfNotNil(method_params_node)
 
 		invokeType := getInvokeType(descName, className)
-		key := MethodMapKey{path: s.path, className: className, name: methodName}
+		key := MethodMapKey{Path: s.path, ClassName: className, Name: methodName}
 		methodInfo := MethodInfo{index: methodIndex,
 			node:          methodNode,
 			name:          methodName,
@@ -70,7 +69,7 @@ func (s *ParsedCode) MakeMethodMap() (*MethodMap, error) {
 		methodDict[key] = methodInfo
 		methodIndex += 1
 
-		fmt.Println(className, methodName, descName, methodParams)
+		log.Debugf("Found method %s %s %s %s", className, methodName, descName, methodParams)
 	}
 
 	methods := &MethodMap{methods: methodDict}
@@ -51,21 +51,21 @@ class AnotherClass:
 
 	// Assert expected values for specific methods
 	expectedMethods := map[MethodMapKey]MethodInfo{
-		{path: relFilePath, className: "MyClass", name: "method1"}: {
+		{Path: relFilePath, ClassName: "MyClass", Name: "method1"}: {
 			index:         0,
 			node:          nil, // Set the expected value based on your test case
 			name:          "method1",
 			invoketype:    "invokevirtual", // Set the expected value based on your test case
 			argumentCount: 1,               // Set the expected value based on your test case
 		},
-		{path: relFilePath, className: "MyClass", name: "static_method"}: {
+		{Path: relFilePath, ClassName: "MyClass", Name: "static_method"}: {
 			index:         1,
 			node:          nil, // Set the expected value based on your test case
 			name:          "static_method",
 			invoketype:    "invokestatic", // Set the expected value based on your test case
 			argumentCount: 0,              // Set the expected value based on your test case
 		},
-		{path: relFilePath, className: "AnotherClass", name: "method2"}: {
+		{Path: relFilePath, ClassName: "AnotherClass", Name: "method2"}: {
 			index:         2,
 			node:          nil, // Set the expected value based on your test case
 			name:          "method2",
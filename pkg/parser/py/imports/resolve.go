@@ -0,0 +1,84 @@
+package imports
+
+import (
+	"fmt"
+
+	"github.com/safedep/codex/pkg/utils/py/dir"
+)
+
+// moduleIndex maps the dotted names of the modules of the repository to their files
+type moduleIndex map[string]string
+
+// newModuleIndex names every analyzed file after the package layout of the directory,
+// e.g. pkg.sub.mod for pkg/sub/mod.py. Files which are not part of a package are named
+// after their file name.
+func newModuleIndex(dirpath string, repoAnalysis *RepoCodeAnalysis) moduleIndex {
+	index := make(moduleIndex, 0)
+	for _, fa := range repoAnalysis.FilesAnalysis {
+		name := dir.ModuleName(dirpath, fa.Path)
+		if name == "" {
+			continue
+		}
+		// The first file in lexical order wins when scripts share a name
+		if _, exists := index[name]; !exists {
+			index[name] = fa.Path
+		}
+	}
+
+	return index
+}
+
+// resolveModules sets the absolute name and the file of every first-party module.
+// Relative imports are resolved against the package of the importing file, and
+// those going beyond its top-level package are reported as findings.
+func (cpf *CodeParser) resolveModules(dirpath string, repoAnalysis *RepoCodeAnalysis) {
+	index := newModuleIndex(dirpath, repoAnalysis)
+
+	for _, fa := range repoAnalysis.FilesAnalysis {
+		moduleName := dir.ModuleName(dirpath, fa.Path)
+		isPackage := isPackageFile(fa.Path)
+
+		for _, mod := range fa.Modules {
+			if mod.Classification != MODULE_CLASS_FIRST_PARTY || mod.Loader == LOADER_SPEC_FROM_FILE_LOCATION {
+				continue
+			}
+
+			absolute, err := dir.ResolveRelativeName(moduleName, isPackage, mod.Name.V)
+			if err != nil {
+				fa.Findings = append(fa.Findings, &Finding{Type: FINDING_UNRESOLVED_RELATIVE_IMPORT,
+					Message: fmt.Sprintf("relative import %s goes beyond the top-level package", mod.Name.V),
+					Path:    fa.Path,
+					Node:    *importNodeOf(mod)})
+				continue
+			}
+
+			mod.AbsoluteName, mod.ModulePath = index.resolve(absolute, mod.Definition)
+		}
+	}
+}
+
+// resolve returns the most specific module of an import and its file. The name
+// imported from a module is a module itself when it is a submodule, e.g. pkg.views
+// for from pkg import views.
+func (index moduleIndex) resolve(module string, definition *TypedValue) (string, string) {
+	if definition != nil && definition.V != "*" {
+		submodule := definition.V
+		if module != "" {
+			submodule = module + "." + definition.V
+		}
+		if path, ok := index[submodule]; ok {
+			return submodule, path
+		}
+	}
+
+	// Modules which are not scanned have no file
+	return module, index[module]
+}
+
+// importNodeOf returns the statement of an import, or its name when the statement is not known
+func importNodeOf(mod *ImportedModule) *TypedValue {
+	if mod.Statement != nil {
+		return mod.Statement
+	}
+	return &mod.Name
+}
@@ -0,0 +1,54 @@
+package imports
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindImportedModulesResolved(t *testing.T) {
+	rootDir := writeTestFiles(t, map[string]string{
+		"myapp/__init__.py":                 "",
+		"myapp/services.py":                 "",
+		"myapp/helper/__init__.py":          "",
+		"myapp/helper/get_score.py":         "",
+		"myapp/views/__init__.py":           "from . import detail\n",
+		"myapp/views/detail.py":             "from ..services import fetch\nfrom ..helper.get_score import grade\nfrom ... import settings\nfrom myapp import helper\n",
+		"manage.py":                         "from .config import URL\nimport myapp.services\n",
+		"myapp/views/templates/__init__.py": "",
+	})
+
+	parser, err := NewPyCodeParserFactory().NewCodeParser()
+	assert.NoError(t, err)
+
+	importedModules, err := parser.FindImportedModules(context.Background(), rootDir, true, []string{".py"}, []string{})
+	assert.NoError(t, err)
+
+	resolved := make(map[string][]string, 0)
+	for _, fa := range importedModules.GetRepoCodeAnalysis().FilesAnalysis {
+		for _, mod := range fa.Modules {
+			resolved[fa.Path] = append(resolved[fa.Path], mod.AbsoluteName+" "+mod.ModulePath)
+		}
+	}
+
+	assert.Equal(t, []string{
+		"myapp.services myapp/services.py",
+		"myapp.helper.get_score myapp/helper/get_score.py",
+		" ",
+		"myapp.helper myapp/helper/__init__.py",
+	}, resolved["myapp/views/detail.py"])
+	assert.Equal(t, []string{"myapp.views.detail myapp/views/detail.py"}, resolved["myapp/views/__init__.py"])
+	assert.Equal(t, []string{" ", "myapp.services myapp/services.py"}, resolved["manage.py"])
+
+	paths := make([]string, 0)
+	for _, f := range importedModules.GetFindings() {
+		if f.Type == FINDING_UNRESOLVED_RELATIVE_IMPORT {
+			paths = append(paths, f.Path+" "+f.Message)
+		}
+	}
+	assert.Equal(t, []string{
+		"manage.py relative import .config goes beyond the top-level package",
+		"myapp/views/detail.py relative import ... goes beyond the top-level package",
+	}, paths)
+}
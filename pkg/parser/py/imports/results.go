@@ -0,0 +1,71 @@
+package imports
+
+import (
+	shared "github.com/safedep/codex/pkg/parser/imports"
+)
+
+// The results of the analysis are shared with the parsers of other languages,
+// they are re-exported here so that Python callers only need this package.
+
+type (
+	TypedValue           = shared.TypedValue
+	ImportedModule       = shared.ImportedModule
+	FileCodeAnalysis     = shared.FileCodeAnalysis
+	ParseError           = shared.ParseError
+	RepoCodeAnalysis     = shared.RepoCodeAnalysis
+	ImportProvenance     = shared.ImportProvenance
+	ImportedModules      = shared.ImportedModules
+	ExportedModules      = shared.ExportedModules
+	FindingType          = shared.FindingType
+	Finding              = shared.Finding
+	UnusedImport         = shared.UnusedImport
+	ModuleClassification = shared.ModuleClassification
+	ImportGuard          = shared.ImportGuard
+	DependencyScope      = shared.DependencyScope
+	ScopeRule            = shared.ScopeRule
+)
+
+const (
+	MODULE_CLASS_STDLIB      = shared.MODULE_CLASS_STDLIB
+	MODULE_CLASS_FIRST_PARTY = shared.MODULE_CLASS_FIRST_PARTY
+	MODULE_CLASS_THIRD_PARTY = shared.MODULE_CLASS_THIRD_PARTY
+	MODULE_CLASS_UNKNOWN     = shared.MODULE_CLASS_UNKNOWN
+
+	IMPORT_GUARD_OPTIONAL      = shared.IMPORT_GUARD_OPTIONAL
+	IMPORT_GUARD_TYPE_CHECKING = shared.IMPORT_GUARD_TYPE_CHECKING
+	IMPORT_GUARD_VERSION       = shared.IMPORT_GUARD_VERSION
+	IMPORT_GUARD_PLATFORM      = shared.IMPORT_GUARD_PLATFORM
+	IMPORT_GUARD_LAZY          = shared.IMPORT_GUARD_LAZY
+
+	SCOPE_RUNTIME = shared.SCOPE_RUNTIME
+	SCOPE_BUILD   = shared.SCOPE_BUILD
+	SCOPE_TEST    = shared.SCOPE_TEST
+	SCOPE_DEV     = shared.SCOPE_DEV
+
+	FINDING_UNRESOLVED_DYNAMIC_IMPORT  = shared.FINDING_UNRESOLVED_DYNAMIC_IMPORT
+	FINDING_UNUSED_IMPORT              = shared.FINDING_UNUSED_IMPORT
+	FINDING_UNRESOLVED_RELATIVE_IMPORT = shared.FINDING_UNRESOLVED_RELATIVE_IMPORT
+)
+
+func NewImportedModules() *ImportedModules {
+	return shared.NewImportedModules()
+}
+
+func NewExportedModules() *ExportedModules {
+	return shared.NewExportedModules()
+}
+
+// ParseScope validates the name of a scope
+func ParseScope(name string) (DependencyScope, error) {
+	return shared.ParseScope(name)
+}
+
+// ParseScopeRule parses a rule written as <pattern>=<scope>, e.g. tools/**=dev
+func ParseScopeRule(rule string) (ScopeRule, error) {
+	return shared.ParseScopeRule(rule)
+}
+
+// MoreImportantScope returns the most important of two scopes, runtime first
+func MoreImportantScope(a, b DependencyScope) DependencyScope {
+	return shared.MoreImportantScope(a, b)
+}
@@ -0,0 +1,76 @@
+package imports
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/bmatcuk/doublestar/v4"
+)
+
+// DEFAULT_SCOPE_RULES classify files by their location, the first matching rule wins.
+// Files matching no rule are runtime code.
+var DEFAULT_SCOPE_RULES = []ScopeRule{
+	{Pattern: "**/tests/**", Scope: SCOPE_TEST},
+	{Pattern: "**/test/**", Scope: SCOPE_TEST},
+	{Pattern: "**/test_*.py", Scope: SCOPE_TEST},
+	{Pattern: "**/*_test.py", Scope: SCOPE_TEST},
+	{Pattern: "**/conftest.py", Scope: SCOPE_TEST},
+	{Pattern: "**/setup.py", Scope: SCOPE_BUILD},
+	{Pattern: "**/docs/**", Scope: SCOPE_DEV},
+	{Pattern: "**/doc/**", Scope: SCOPE_DEV},
+	{Pattern: "**/examples/**", Scope: SCOPE_DEV},
+	{Pattern: "**/scripts/**", Scope: SCOPE_DEV},
+	{Pattern: "**/benchmarks/**", Scope: SCOPE_DEV},
+	{Pattern: "**/noxfile.py", Scope: SCOPE_DEV},
+	{Pattern: "**/fabfile.py", Scope: SCOPE_DEV},
+}
+
+// SetScopeRules sets rules classifying files into scopes. They are applied
+// before DEFAULT_SCOPE_RULES.
+func (cpf *CodeParser) SetScopeRules(rules []ScopeRule) error {
+	for _, rule := range rules {
+		if !doublestar.ValidatePattern(rule.Pattern) {
+			return fmt.Errorf("invalid glob pattern %q", rule.Pattern)
+		}
+		if _, err := ParseScope(string(rule.Scope)); err != nil {
+			return err
+		}
+	}
+
+	cpf.scopeRules = rules
+	return nil
+}
+
+// SetScopes limits the dependencies to the packages imported by files of the scopes.
+// Dependencies of every scope are found when empty.
+func (cpf *CodeParser) SetScopes(scopes []DependencyScope) {
+	cpf.scopes = scopes
+}
+
+// classifyScope returns the scope of a file relative to the scanned directory
+func (cpf *CodeParser) classifyScope(relPath string) DependencyScope {
+	relPath = filepath.ToSlash(relPath)
+	for _, rules := range [][]ScopeRule{cpf.scopeRules, DEFAULT_SCOPE_RULES} {
+		for _, rule := range rules {
+			if matched, _ := doublestar.Match(rule.Pattern, relPath); matched {
+				return rule.Scope
+			}
+		}
+	}
+
+	return SCOPE_RUNTIME
+}
+
+// isScopeIncluded checks if the dependencies of the scope are reported
+func (cpf *CodeParser) isScopeIncluded(scope DependencyScope) bool {
+	if len(cpf.scopes) == 0 {
+		return true
+	}
+
+	for _, s := range cpf.scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,88 @@
+package imports
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassifyScope(t *testing.T) {
+	parser, err := NewPyCodeParserFactory().NewCodeParser()
+	assert.NoError(t, err)
+
+	cases := map[string]DependencyScope{
+		"mypkg/views.py":          SCOPE_RUNTIME,
+		"tests/test_views.py":     SCOPE_TEST,
+		"mypkg/tests/helpers.py":  SCOPE_TEST,
+		"test_main.py":            SCOPE_TEST,
+		"mypkg/views_test.py":     SCOPE_TEST,
+		"conftest.py":             SCOPE_TEST,
+		"tests/setup.py":          SCOPE_TEST,
+		"setup.py":                SCOPE_BUILD,
+		"docs/conf.py":            SCOPE_DEV,
+		"examples/quickstart.py":  SCOPE_DEV,
+		"scripts/release.py":      SCOPE_DEV,
+		"noxfile.py":              SCOPE_DEV,
+		"tools/lint.py":           SCOPE_RUNTIME,
+		"mypkg/testing_utils.py":  SCOPE_RUNTIME,
+		"mypkg/contest/models.py": SCOPE_RUNTIME,
+	}
+	for path, scope := range cases {
+		assert.Equal(t, scope, parser.classifyScope(path), path)
+	}
+
+	// User rules are applied first
+	assert.NoError(t, parser.SetScopeRules([]ScopeRule{{Pattern: "tools/**", Scope: SCOPE_DEV},
+		{Pattern: "tests/e2e/**", Scope: SCOPE_DEV}}))
+	assert.Equal(t, SCOPE_DEV, parser.classifyScope("tools/lint.py"))
+	assert.Equal(t, SCOPE_DEV, parser.classifyScope("tests/e2e/run.py"))
+	assert.Equal(t, SCOPE_TEST, parser.classifyScope("tests/unit/run.py"))
+
+	assert.Error(t, parser.SetScopeRules([]ScopeRule{{Pattern: "[a", Scope: SCOPE_DEV}}))
+	assert.Error(t, parser.SetScopeRules([]ScopeRule{{Pattern: "a/**", Scope: "prod"}}))
+}
+
+func TestParseScopeRule(t *testing.T) {
+	rule, err := ParseScopeRule("tools/** = dev")
+	assert.NoError(t, err)
+	assert.Equal(t, ScopeRule{Pattern: "tools/**", Scope: SCOPE_DEV}, rule)
+
+	_, err = ParseScopeRule("tools/**")
+	assert.Error(t, err)
+
+	_, err = ParseScopeRule("tools/**=prod")
+	assert.Error(t, err)
+}
+
+func TestFindImportedModulesScopes(t *testing.T) {
+	rootDir := writeTestFiles(t, map[string]string{
+		"mypkg/__init__.py": "import requests\n",
+		"tests/test_api.py": "import pytest\nimport requests\nimport responses\n",
+		"tests/conftest.py": "import pytest\n",
+		"setup.py":          "import setuptools_scm\n",
+		"docs/conf.py":      "import sphinx\nimport pytest\n",
+	})
+
+	parser, err := NewPyCodeParserFactory().NewCodeParser()
+	assert.NoError(t, err)
+
+	importedModules, err := parser.FindImportedModules(context.Background(), rootDir, true, []string{".py"}, []string{})
+	assert.NoError(t, err)
+
+	assert.Equal(t, SCOPE_RUNTIME, importedModules.GetScope("requests"))
+	assert.Equal(t, SCOPE_TEST, importedModules.GetScope("pytest"))
+	assert.Equal(t, SCOPE_BUILD, importedModules.GetScope("setuptools_scm"))
+	assert.Equal(t, SCOPE_DEV, importedModules.GetScope("sphinx"))
+	assert.Equal(t, []string{"pytest", "responses"}, importedModules.GetDependenciesOfScope(SCOPE_TEST))
+
+	provs := importedModules.GetProvenance("requests")
+	assert.Equal(t, 2, len(provs))
+	assert.Equal(t, SCOPE_RUNTIME, provs[0].Scope)
+	assert.Equal(t, SCOPE_TEST, provs[1].Scope)
+
+	parser.SetScopes([]DependencyScope{SCOPE_RUNTIME, SCOPE_BUILD})
+	importedModules, err = parser.FindImportedModules(context.Background(), rootDir, true, []string{".py"}, []string{})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"requests", "setuptools_scm"}, importedModules.GetPackagesNames())
+}
@@ -0,0 +1,226 @@
+package imports
+
+import (
+	"context"
+	"strings"
+
+	"github.com/safedep/codex/pkg/utils/workerpool"
+	tree_sitter "github.com/smacker/go-tree-sitter"
+)
+
+// MODULE_SCOPE is the name of the caller of the calls made at module level
+const MODULE_SCOPE = "<module>"
+
+// FunctionDefinition is a function or a method defined in a file. Functions nested
+// in other functions are named after them, e.g. outer.inner.
+type FunctionDefinition struct {
+	Key        MethodMapKey
+	Node       TypedValue // name of the function
+	InvokeType InvokeType
+	Decorators []string // decorators without @, e.g. app.route("/")
+}
+
+// ClassDefinition is a class defined in a file. Nested classes are named after
+// their enclosing classes, e.g. Outer.Inner.
+type ClassDefinition struct {
+	Name  string
+	Node  TypedValue // name of the class
+	Bases []string   // base classes as written, e.g. models.Model
+}
+
+// CallSite is a call of a named function or method
+type CallSite struct {
+	Caller MethodMapKey // enclosing function, named MODULE_SCOPE for calls at module level
+	Callee string       // called name as written, e.g. helper, self.save or np.array
+	Node   TypedValue   // call expression
+}
+
+// FileSymbols holds the definitions, the calls and the import bindings of a file
+type FileSymbols struct {
+	Path           string
+	Functions      []*FunctionDefinition
+	Classes        []*ClassDefinition
+	Calls          []*CallSite
+	Imports        map[string]string // names bound by imports to the names they refer to, e.g. np -> numpy
+	ImportBindings []*ImportBinding  // import statements binding the names, in the order of the code
+	HasMainBlock   bool              // the file has an if __name__ == "__main__": block
+}
+
+// RepoSymbols holds the symbols of the files of a repository
+type RepoSymbols struct {
+	Path   string
+	Files  []*FileSymbols
+	Errors []*ParseError // files skipped when not failing on the first error
+}
+
+// FindSymbols extracts the symbols of every file of the repository in the directory
+func (cpf *CodeParser) FindSymbols(ctx context.Context,
+	dirpath string, failOnFirstError bool,
+	includeExtensions, excludeDirs []string) (*RepoSymbols, error) {
+	// Find the files to analyze, skipping excluded directories.
+	relPaths, err := cpf.findFiles(ctx, dirpath, includeExtensions, excludeDirs)
+	if err != nil {
+		return nil, err
+	}
+
+	// Extract the symbols of the files in parallel.
+	results, err := workerpool.Run(ctx, cpf.concurrency, relPaths, failOnFirstError, cpf.newWorker,
+		func(ctx context.Context, worker *CodeParser, relPath string) (*FileSymbols, error) {
+			parsedCode, err := worker.ParseFile(ctx, dirpath, relPath)
+			if err != nil {
+				return nil, err
+			}
+			return parsedCode.ExtractSymbols(), nil
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	repoSymbols := &RepoSymbols{Path: dirpath}
+	for _, result := range results {
+		if result.Err != nil {
+			repoSymbols.Errors = append(repoSymbols.Errors, &ParseError{Path: result.RelPath, Err: result.Err})
+			continue
+		}
+		repoSymbols.Files = append(repoSymbols.Files, result.Value)
+	}
+
+	return repoSymbols, nil
+}
+
+// ExtractSymbols finds the functions, classes, calls and import bindings of the code
+func (s *ParsedCode) ExtractSymbols() *FileSymbols {
+	symbols := &FileSymbols{Path: s.path, Imports: make(map[string]string, 0),
+		ImportBindings: s.findImportBindingList(),
+		HasMainBlock:   s.hasMainBlock()}
+	for _, binding := range symbols.ImportBindings {
+		symbols.Imports[binding.Name] = binding.Qualified
+	}
+
+	// scope holds the names of the enclosing classes and functions, inClass
+	// tells whether the innermost one is a class.
+	var walk func(node *tree_sitter.Node, scope []string, inClass bool, caller MethodMapKey)
+	walk = func(node *tree_sitter.Node, scope []string, inClass bool, caller MethodMapKey) {
+		switch node.Type() {
+		case "class_definition":
+			nameNode := node.ChildByFieldName("name")
+			name := nameNode.Content(s.code)
+			class := &ClassDefinition{Name: joinScope(scope, name), Node: *s.typedValueOf(nameNode)}
+			if superclasses := node.ChildByFieldName("superclasses"); superclasses != nil {
+				for i := 0; i < int(superclasses.NamedChildCount()); i++ {
+					// Keyword arguments such as metaclass=ABCMeta are not base classes
+					if base := superclasses.NamedChild(i); isDottedName(base) {
+						class.Bases = append(class.Bases, base.Content(s.code))
+					}
+				}
+			}
+			symbols.Classes = append(symbols.Classes, class)
+
+			if body := node.ChildByFieldName("body"); body != nil {
+				walk(body, appendScope(scope, name), true, caller)
+			}
+			return
+		case "function_definition":
+			nameNode := node.ChildByFieldName("name")
+			name := nameNode.Content(s.code)
+			key := MethodMapKey{Path: s.path, Name: joinScope(scope, name)}
+			if inClass {
+				key = MethodMapKey{Path: s.path, ClassName: strings.Join(scope, "."), Name: name}
+			}
+
+			function := &FunctionDefinition{Key: key, Node: *s.typedValueOf(nameNode),
+				Decorators: s.findDecorators(node),
+				InvokeType: getInvokeType("", key.ClassName)}
+			for _, decorator := range function.Decorators {
+				switch decorator {
+				case "staticmethod", "classmethod", "abstractmethod":
+					function.InvokeType = getInvokeType("@"+decorator, key.ClassName)
+				}
+			}
+			symbols.Functions = append(symbols.Functions, function)
+
+			// Default values are evaluated by the enclosing scope
+			if parameters := node.ChildByFieldName("parameters"); parameters != nil {
+				walk(parameters, scope, false, caller)
+			}
+			if body := node.ChildByFieldName("body"); body != nil {
+				walk(body, appendScope(scope, name), false, key)
+			}
+			return
+		case "call":
+			if function := node.ChildByFieldName("function"); isDottedName(function) {
+				symbols.Calls = append(symbols.Calls, &CallSite{Caller: caller,
+					Callee: function.Content(s.code),
+					Node:   *s.typedValueOf(node)})
+			}
+		}
+
+		for i := 0; i < int(node.NamedChildCount()); i++ {
+			walk(node.NamedChild(i), scope, inClass, caller)
+		}
+	}
+	walk(s.codeTree.RootNode(), nil, false, MethodMapKey{Path: s.path, Name: MODULE_SCOPE})
+
+	return symbols
+}
+
+// hasMainBlock tells whether the module runs code when it is run as a script
+func (s *ParsedCode) hasMainBlock() bool {
+	root := s.codeTree.RootNode()
+	for i := 0; i < int(root.NamedChildCount()); i++ {
+		stmt := root.NamedChild(i)
+		if stmt.Type() != "if_statement" {
+			continue
+		}
+
+		condition := stmt.ChildByFieldName("condition")
+		if condition == nil {
+			continue
+		}
+		normalized := strings.NewReplacer(" ", "", "'", "\"", "(", "", ")", "").Replace(condition.Content(s.code))
+		if normalized == `__name__=="__main__"` || normalized == `"__main__"==__name__` {
+			return true
+		}
+	}
+	return false
+}
+
+// findDecorators returns the decorators of a function definition without @
+func (s *ParsedCode) findDecorators(node *tree_sitter.Node) []string {
+	decorators := make([]string, 0)
+	parent := node.Parent()
+	if parent == nil || parent.Type() != "decorated_definition" {
+		return decorators
+	}
+
+	for i := 0; i < int(parent.NamedChildCount()); i++ {
+		if child := parent.NamedChild(i); child.Type() == "decorator" {
+			decorators = append(decorators, strings.TrimSpace(strings.TrimPrefix(child.Content(s.code), "@")))
+		}
+	}
+	return decorators
+}
+
+// isDottedName tells whether the node is a name such as foo or foo.bar.baz
+func isDottedName(node *tree_sitter.Node) bool {
+	if node == nil {
+		return false
+	}
+
+	switch node.Type() {
+	case "identifier":
+		return true
+	case "attribute":
+		return isDottedName(node.ChildByFieldName("object"))
+	}
+	return false
+}
+
+func joinScope(scope []string, name string) string {
+	return strings.Join(appendScope(scope, name), ".")
+}
+
+// appendScope returns a new scope, the enclosing scope is shared by sibling definitions
+func appendScope(scope []string, name string) []string {
+	return append(append(make([]string, 0, len(scope)+1), scope...), name)
+}
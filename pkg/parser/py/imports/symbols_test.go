@@ -0,0 +1,94 @@
+package imports
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtractSymbols(t *testing.T) {
+	rootDir := writeTestFiles(t, map[string]string{"app.py": `
+import numpy as np
+from . import utils
+from .models import User as U
+
+class Service(Base, metaclass=ABCMeta):
+    def run(self, size=default_size()):
+        self.save(np.zeros(size))
+
+        def inner():
+            utils.helper()
+        inner()
+
+    @staticmethod
+    def save(data):
+        pass
+
+main()
+
+if __name__ == '__main__':
+    main()
+`})
+
+	parser, err := NewPyCodeParserFactory().NewCodeParser()
+	assert.NoError(t, err)
+
+	parsedCode, err := parser.ParseFile(context.Background(), rootDir, "app.py")
+	assert.NoError(t, err)
+
+	symbols := parsedCode.ExtractSymbols()
+	assert.Equal(t, "app.py", symbols.Path)
+	assert.Equal(t, map[string]string{"np": "numpy", "utils": ".utils", "U": ".models.User"}, symbols.Imports)
+	assert.Equal(t, 3, len(symbols.ImportBindings))
+	assert.Equal(t, "U", symbols.ImportBindings[2].Name)
+	assert.Equal(t, uint32(3), symbols.ImportBindings[2].Node.RowStart)
+	assert.True(t, symbols.HasMainBlock)
+
+	assert.Equal(t, 1, len(symbols.Classes))
+	assert.Equal(t, "Service", symbols.Classes[0].Name)
+	assert.Equal(t, []string{"Base"}, symbols.Classes[0].Bases)
+
+	assert.Equal(t, 3, len(symbols.Functions))
+	assert.Equal(t, MethodMapKey{Path: "app.py", ClassName: "Service", Name: "run"}, symbols.Functions[0].Key)
+	assert.Equal(t, INVOKEVIRTUAL, symbols.Functions[0].InvokeType)
+	assert.Equal(t, MethodMapKey{Path: "app.py", Name: "Service.run.inner"}, symbols.Functions[1].Key)
+	assert.Equal(t, MethodMapKey{Path: "app.py", ClassName: "Service", Name: "save"}, symbols.Functions[2].Key)
+	assert.Equal(t, INVOKESTATIC, symbols.Functions[2].InvokeType)
+	assert.Equal(t, []string{"staticmethod"}, symbols.Functions[2].Decorators)
+
+	run := MethodMapKey{Path: "app.py", ClassName: "Service", Name: "run"}
+	module := MethodMapKey{Path: "app.py", Name: MODULE_SCOPE}
+	calls := make([]CallSite, 0)
+	for _, call := range symbols.Calls {
+		calls = append(calls, CallSite{Caller: call.Caller, Callee: call.Callee})
+	}
+	assert.Equal(t, []CallSite{
+		{Caller: module, Callee: "default_size"},
+		{Caller: run, Callee: "self.save"},
+		{Caller: run, Callee: "np.zeros"},
+		{Caller: MethodMapKey{Path: "app.py", Name: "Service.run.inner"}, Callee: "utils.helper"},
+		{Caller: run, Callee: "inner"},
+		{Caller: module, Callee: "main"},
+		{Caller: module, Callee: "main"},
+	}, calls)
+	assert.Equal(t, uint32(7), symbols.Calls[1].Node.RowStart)
+}
+
+func TestFindSymbols(t *testing.T) {
+	rootDir := writeTestFiles(t, map[string]string{
+		"a.py":     "def a():\n    pass\n",
+		"pkg/b.py": "from a import a\na()\n",
+	})
+
+	parser, err := NewPyCodeParserFactory().NewCodeParser()
+	assert.NoError(t, err)
+
+	repoSymbols, err := parser.FindSymbols(context.Background(), rootDir, true, []string{".py"}, []string{})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(repoSymbols.Files))
+	assert.Equal(t, "a.py", repoSymbols.Files[0].Path)
+	assert.Equal(t, "pkg/b.py", repoSymbols.Files[1].Path)
+	assert.Equal(t, "a", repoSymbols.Files[1].Calls[0].Callee)
+	assert.False(t, repoSymbols.Files[1].HasMainBlock)
+}
@@ -0,0 +1,225 @@
+package imports
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/safedep/codex/pkg/utils/py/strlit"
+	tree_sitter "github.com/smacker/go-tree-sitter"
+)
+
+// Name of the list of the public names of a module
+const ALL_NAME = "__all__"
+
+// FindUnusedImports returns the names bound by import statements which are never
+// referenced in the file. Imports are not reported when they are re-exported:
+// imports of __init__.py files, names listed in __all__ and redundant aliases
+// such as from a import b as b. Imports probing whether a module is installed,
+// e.g. in try/except ImportError blocks, are not reported either.
+func (s *ParsedCode) FindUnusedImports() []*UnusedImport {
+	// Imports of packages are their public interface
+	if isPackageFile(s.path) {
+		return []*UnusedImport{}
+	}
+
+	used := s.findReferencedNames()
+	for _, name := range s.findAllNames() {
+		used[name] = true
+	}
+
+	// Collect the unused names of every statement, statements are identified by their
+	// start byte since several of them may share a row, e.g. import os; import sys
+	unused := make([]*UnusedImport, 0)
+	bindingsPerStatement := make(map[uint32]int, 0)
+	unusedPerStatement := make(map[uint32]int, 0)
+	for _, binding := range s.findImportBindingList() {
+		bindingsPerStatement[binding.Node.StartByte] += 1
+		if used[binding.Name] || isReExport(binding) || isImportProbe(binding) {
+			continue
+		}
+
+		unusedPerStatement[binding.Node.StartByte] += 1
+		unused = append(unused, &UnusedImport{Name: binding.Name, Imported: binding.Imported,
+			Statement: binding.Node,
+			Binding:   binding.Binding})
+	}
+
+	for _, u := range unused {
+		start := u.Statement.StartByte
+		u.DeadStatement = unusedPerStatement[start] == bindingsPerStatement[start]
+	}
+
+	return unused
+}
+
+// isPackageFile tells whether the file is the __init__.py of a package
+func isPackageFile(filePath string) bool {
+	return filepath.Base(filePath) == "__init__.py"
+}
+
+// unusedImportFindings reports every unused import as a finding
+func (s *ParsedCode) unusedImportFindings(unused []*UnusedImport) []*Finding {
+	findings := make([]*Finding, 0, len(unused))
+	for _, u := range unused {
+		message := fmt.Sprintf("%s is imported but never used", u.Name)
+		if u.Name != u.Imported {
+			message = fmt.Sprintf("%s (%s) is imported but never used", u.Name, u.Imported)
+		}
+		findings = append(findings, &Finding{Type: FINDING_UNUSED_IMPORT,
+			Message: message,
+			Path:    s.path,
+			Node:    u.Binding})
+	}
+	return findings
+}
+
+// isReExport tells whether the import uses a redundant alias to mark the name as
+// public, e.g. import a as a or from a import b as b
+func isReExport(binding *ImportBinding) bool {
+	if !binding.Aliased {
+		return false
+	}
+	parts := strings.Split(binding.Imported, ".")
+	return parts[len(parts)-1] == binding.Name
+}
+
+// isImportProbe tells whether the import only checks that a module is installed
+func isImportProbe(binding *ImportBinding) bool {
+	for _, guard := range binding.Guards {
+		if guard == IMPORT_GUARD_OPTIONAL {
+			return true
+		}
+	}
+	return false
+}
+
+// findReferencedNames returns the names referenced by the code outside of import
+// statements. Attributes, keyword arguments and names assigned to are not references
+// of names, and type annotations written as strings reference the first part of their names.
+func (s *ParsedCode) findReferencedNames() map[string]bool {
+	names := make(map[string]bool, 0)
+
+	var walk func(node *tree_sitter.Node)
+	// walkTarget skips the names assigned to, e.g. a and b of a, b = c but not x of x[0] = c
+	var walkTarget func(node *tree_sitter.Node)
+	walkTarget = func(node *tree_sitter.Node) {
+		switch node.Type() {
+		case "identifier":
+			return
+		case "pattern_list", "tuple_pattern", "list_pattern":
+			for i := 0; i < int(node.NamedChildCount()); i++ {
+				walkTarget(node.NamedChild(i))
+			}
+		default:
+			walk(node)
+		}
+	}
+
+	walk = func(node *tree_sitter.Node) {
+		switch node.Type() {
+		case "import_statement", "import_from_statement", "future_import_statement":
+			return
+		case "identifier":
+			names[node.Content(s.code)] = true
+			return
+		case "attribute":
+			// a.b references a only
+			walk(node.ChildByFieldName("object"))
+			return
+		case "keyword_argument":
+			walk(node.ChildByFieldName("value"))
+			return
+		case "assignment":
+			for i := 0; i < int(node.NamedChildCount()); i++ {
+				if child := node.NamedChild(i); child.Equal(node.ChildByFieldName("left")) {
+					walkTarget(child)
+				} else {
+					walk(child)
+				}
+			}
+			return
+		case "type":
+			s.addAnnotationNames(node, names)
+		}
+
+		for i := 0; i < int(node.NamedChildCount()); i++ {
+			walk(node.NamedChild(i))
+		}
+	}
+	walk(s.codeTree.RootNode())
+
+	return names
+}
+
+// addAnnotationNames adds the names of the type annotations written as strings,
+// e.g. np of "np.ndarray" or of "Optional[np.ndarray]"
+func (s *ParsedCode) addAnnotationNames(node *tree_sitter.Node, names map[string]bool) {
+	if node.Type() == "string" {
+		value, ok := strlit.Unquote(node.Content(s.code))
+		if !ok {
+			return
+		}
+		for _, name := range strings.FieldsFunc(value, func(r rune) bool {
+			return !(r == '_' || r == '.' || r >= '0' && r <= '9' ||
+				r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r > 127)
+		}) {
+			names[strings.Split(name, ".")[0]] = true
+		}
+		return
+	}
+
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		s.addAnnotationNames(node.NamedChild(i), names)
+	}
+}
+
+// findAllNames returns the names listed in __all__, by assignments such as
+// __all__ = ["a"] or __all__ += ["b"] and by calls of __all__.append or __all__.extend
+func (s *ParsedCode) findAllNames() []string {
+	names := make([]string, 0)
+	addStrings := func(node *tree_sitter.Node) {
+		for _, str := range findStrings(node) {
+			if value, ok := strlit.Unquote(str.Content(s.code)); ok {
+				names = append(names, value)
+			}
+		}
+	}
+
+	var walk func(node *tree_sitter.Node)
+	walk = func(node *tree_sitter.Node) {
+		switch node.Type() {
+		case "assignment", "augmented_assignment":
+			left := node.ChildByFieldName("left")
+			right := node.ChildByFieldName("right")
+			if left != nil && right != nil && left.Content(s.code) == ALL_NAME {
+				addStrings(right)
+			}
+		case "call":
+			function := node.ChildByFieldName("function").Content(s.code)
+			if function == ALL_NAME+".append" || function == ALL_NAME+".extend" {
+				addStrings(node.ChildByFieldName("arguments"))
+			}
+		}
+
+		for i := 0; i < int(node.NamedChildCount()); i++ {
+			walk(node.NamedChild(i))
+		}
+	}
+	walk(s.codeTree.RootNode())
+
+	return names
+}
+
+// findStrings returns the string literals of the node and of its descendants
+func findStrings(node *tree_sitter.Node) []*tree_sitter.Node {
+	if node.Type() == "string" {
+		return []*tree_sitter.Node{node}
+	}
+
+	strs := make([]*tree_sitter.Node, 0)
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		strs = append(strs, findStrings(node.NamedChild(i))...)
+	}
+	return strs
+}
@@ -0,0 +1,123 @@
+package imports
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const PY_UNUSED_IMPORTS_CODE = `from __future__ import annotations
+import os, sys
+import numpy as np
+import xml.etree.ElementTree
+from typing import Optional, List
+from collections import OrderedDict as OD, defaultdict
+from .models import User as User
+from .helpers import export_me
+
+try:
+    import ujson
+except ImportError:
+    ujson = None
+
+__all__ = ["export_me"]
+
+def run(path: "Optional[np.ndarray]", items=List) -> None:
+    sys.exit(defaultdict(list, path=path))
+
+class Config:
+    os = None
+
+    def load(self):
+        return self.OD
+`
+
+func TestFindUnusedImports(t *testing.T) {
+	rootDir := writeTestFiles(t, map[string]string{"app.py": PY_UNUSED_IMPORTS_CODE,
+		"pkg/__init__.py": "import requests\nfrom .core import run\n"})
+
+	parser, err := NewPyCodeParserFactory().NewCodeParser()
+	assert.NoError(t, err)
+
+	parsedCode, err := parser.ParseFile(context.Background(), rootDir, "app.py")
+	assert.NoError(t, err)
+
+	unused := make(map[string]*UnusedImport, 0)
+	for _, u := range parsedCode.FindUnusedImports() {
+		unused[u.Name] = u
+	}
+
+	// os is only bound as an attribute of Config, OD only read as self.OD
+	assert.Equal(t, []string{"OD", "os", "xml"}, sortedKeys(unused))
+	assert.Equal(t, "collections.OrderedDict", unused["OD"].Imported)
+	assert.False(t, unused["OD"].DeadStatement)
+	assert.False(t, unused["os"].DeadStatement)
+	assert.True(t, unused["xml"].DeadStatement)
+	assert.Equal(t, "xml.etree.ElementTree", unused["xml"].Imported)
+	assert.Equal(t, uint32(3), unused["xml"].Statement.RowStart)
+
+	// Imports of packages are re-exported
+	parsedCode, err = parser.ParseFile(context.Background(), rootDir, "pkg/__init__.py")
+	assert.NoError(t, err)
+	assert.Empty(t, parsedCode.FindUnusedImports())
+}
+
+func TestFindImportedModulesUnused(t *testing.T) {
+	rootDir := writeTestFiles(t, map[string]string{
+		"a.py": "import requests\nimport yaml\nfrom numpy import array, zeros\n\nyaml.load(array)\n",
+		"b.py": "import requests\n",
+	})
+
+	parser, err := NewPyCodeParserFactory().NewCodeParser()
+	assert.NoError(t, err)
+
+	importedModules, err := parser.FindImportedModules(context.Background(), rootDir, true, []string{".py"}, []string{})
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"requests"}, importedModules.GetUnusedDependencies())
+	assert.True(t, importedModules.GetProvenance("requests")[0].Unused)
+	assert.False(t, importedModules.GetProvenance("numpy")[0].Unused)
+
+	messages := make([]string, 0)
+	for _, f := range importedModules.GetFindings() {
+		assert.Equal(t, FINDING_UNUSED_IMPORT, f.Type)
+		messages = append(messages, f.Path+" "+f.Message)
+	}
+	assert.Equal(t, []string{
+		"a.py requests is imported but never used",
+		"a.py zeros (numpy.zeros) is imported but never used",
+		"b.py requests is imported but never used",
+	}, messages)
+}
+
+func TestFindUnusedImportsSameRow(t *testing.T) {
+	rootDir := writeTestFiles(t, map[string]string{
+		"app.py": "import requests; import yaml\nyaml.load()\n",
+	})
+
+	parser, err := NewPyCodeParserFactory().NewCodeParser()
+	assert.NoError(t, err)
+
+	parsedCode, err := parser.ParseFile(context.Background(), rootDir, "app.py")
+	assert.NoError(t, err)
+	unused := parsedCode.FindUnusedImports()
+	assert.Equal(t, 1, len(unused))
+	assert.Equal(t, "requests", unused[0].Name)
+	assert.True(t, unused[0].DeadStatement)
+
+	importedModules, err := parser.FindImportedModules(context.Background(), rootDir, true, []string{".py"}, []string{})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"requests"}, importedModules.GetUnusedDependencies())
+	assert.False(t, importedModules.GetProvenance("yaml")[0].Unused)
+}
+
+func sortedKeys(m map[string]*UnusedImport) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,47 @@
+package imports
+
+import (
+	"context"
+
+	"github.com/safedep/codex/pkg/utils/workerpool"
+	tree_sitter "github.com/smacker/go-tree-sitter"
+)
+
+// fileResult is the analysis of a file by a worker
+type fileResult struct {
+	relPath string
+	fa      *FileCodeAnalysis
+	err     error
+}
+
+// newWorker creates a parser for a worker goroutine. A tree_sitter.Parser
+// must not be used by more than one goroutine at a time.
+func (cpf *CodeParser) newWorker() *CodeParser {
+	parser := tree_sitter.NewParser()
+	parser.SetLanguage(cpf.lang)
+	return &CodeParser{parser: parser, lang: cpf.lang,
+		stdlibModules: cpf.stdlibModules,
+		concurrency:   1,
+		cache:         cpf.cache}
+}
+
+// analyzeFiles parses the files with a pool of workers. The results are in the
+// order of the paths, whatever the number of workers. With failOnFirstError the
+// remaining files are skipped after a failure and the error of the first failing
+// path is returned.
+func (cpf *CodeParser) analyzeFiles(ctx context.Context,
+	rootDir string, relPaths []string, failOnFirstError bool) ([]*fileResult, error) {
+	results, err := workerpool.Run(ctx, cpf.concurrency, relPaths, failOnFirstError, cpf.newWorker,
+		func(ctx context.Context, worker *CodeParser, relPath string) (*FileCodeAnalysis, error) {
+			return worker.findModulesInFile(ctx, rootDir, relPath)
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	fileResults := make([]*fileResult, 0, len(results))
+	for _, result := range results {
+		fileResults = append(fileResults, &fileResult{relPath: result.RelPath, fa: result.Value, err: result.Err})
+	}
+	return fileResults, nil
+}
@@ -0,0 +1,95 @@
+package imports
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeTestFiles(t *testing.T, files map[string]string) string {
+	rootDir := t.TempDir()
+	for relPath, code := range files {
+		fullPath := path.Join(rootDir, relPath)
+		assert.NoError(t, os.MkdirAll(path.Dir(fullPath), os.ModePerm))
+		assert.NoError(t, os.WriteFile(fullPath, []byte(code), 0644))
+	}
+	return rootDir
+}
+
+func TestFindImportedModulesConcurrency(t *testing.T) {
+	files := make(map[string]string, 0)
+	for i := 0; i < 50; i++ {
+		files[fmt.Sprintf("pkg%d/mod%d.py", i%7, i)] = fmt.Sprintf("import lib%d\nimport shared\n", i%11)
+	}
+	rootDir := writeTestFiles(t, files)
+
+	var expected *RepoCodeAnalysis
+	for _, concurrency := range []int{1, 2, 8, 64} {
+		parser, err := NewPyCodeParserFactory().NewCodeParser()
+		assert.NoError(t, err)
+		assert.NoError(t, parser.SetConcurrency(concurrency))
+
+		importedModules, err := parser.FindImportedModules(context.Background(), rootDir, true, []string{".py"}, []string{})
+		assert.NoError(t, err)
+
+		repoAnalysis := importedModules.GetRepoCodeAnalysis()
+		assert.Equal(t, 50, len(repoAnalysis.FilesAnalysis))
+		assert.Equal(t, 12, len(importedModules.GetPackagesNames()))
+		assert.Equal(t, 50, len(importedModules.GetProvenance("shared")))
+
+		if expected == nil {
+			expected = repoAnalysis
+			continue
+		}
+		for i, fa := range repoAnalysis.FilesAnalysis {
+			assert.Equal(t, expected.FilesAnalysis[i].Path, fa.Path)
+			assert.Equal(t, expected.FilesAnalysis[i].Modules, fa.Modules)
+		}
+	}
+}
+
+func TestFindImportedModulesCancelled(t *testing.T) {
+	rootDir := writeTestFiles(t, map[string]string{"a.py": "import requests\n", "b.py": "import yaml\n"})
+
+	parser, err := NewPyCodeParserFactory().NewCodeParser()
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err = parser.FindImportedModules(ctx, rootDir, false, []string{".py"}, []string{})
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestAnalyzeFilesErrors(t *testing.T) {
+	rootDir := writeTestFiles(t, map[string]string{"a.py": "import requests\n", "c.py": "import yaml\n"})
+	relPaths := []string{"a.py", "b.py", "c.py"}
+
+	parser, err := NewPyCodeParserFactory().NewCodeParser()
+	assert.NoError(t, err)
+	assert.NoError(t, parser.SetConcurrency(3))
+
+	// b.py does not exist
+	_, err = parser.analyzeFiles(context.Background(), rootDir, relPaths, true)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	results, err := parser.analyzeFiles(context.Background(), rootDir, relPaths, false)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(results))
+	assert.NoError(t, results[0].err)
+	assert.ErrorIs(t, results[1].err, os.ErrNotExist)
+	assert.NoError(t, results[2].err)
+	assert.Equal(t, "c.py", results[2].fa.Path)
+}
+
+func TestSetConcurrency(t *testing.T) {
+	parser, err := NewPyCodeParserFactory().NewCodeParser()
+	assert.NoError(t, err)
+
+	assert.Error(t, parser.SetConcurrency(0))
+	assert.NoError(t, parser.SetConcurrency(4))
+}
@@ -0,0 +1,212 @@
+/*
+	Evaluate policies written as CEL expressions over the imports of a Python repository
+*/
+
+package policy
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/google/cel-go/cel"
+	"github.com/safedep/codex/pkg/parser/py/imports"
+	"github.com/safedep/codex/pkg/utils/py/dir"
+	"gopkg.in/yaml.v3"
+)
+
+// Name of the policy file looked up in the project directory
+const DEFAULT_CONFIG_FILE = "policy.yaml"
+
+// Variables of the expressions, one value per import
+const (
+	VAR_PACKAGE        = "pkg"            // top-level package, e.g. yaml for import yaml.constructor
+	VAR_MODULE         = "module"         // imported module, absolute for relative imports, e.g. yaml.constructor
+	VAR_NAME           = "name"           // name imported from the module, e.g. load for from yaml import load
+	VAR_CLASSIFICATION = "classification" // stdlib, first-party, third-party or unknown
+	VAR_PATH           = "path"           // importing file, relative to the project directory
+	VAR_SCOPE          = "scope"          // runtime, build, test or dev
+	VAR_DYNAMIC        = "dynamic"        // imported at runtime, e.g. importlib.import_module("yaml")
+	VAR_OPTIONAL       = "optional"       // may not run at all, e.g. try/except ImportError fallbacks
+	VAR_GUARDS         = "guards"         // conditions under which the import runs, e.g. ["type-checking"]
+)
+
+// newEnv declares the variables of the expressions with their types, so that rules
+// are type-checked when they are compiled
+func newEnv() (*cel.Env, error) {
+	return cel.NewEnv(
+		cel.Variable(VAR_PACKAGE, cel.StringType),
+		cel.Variable(VAR_MODULE, cel.StringType),
+		cel.Variable(VAR_NAME, cel.StringType),
+		cel.Variable(VAR_CLASSIFICATION, cel.StringType),
+		cel.Variable(VAR_PATH, cel.StringType),
+		cel.Variable(VAR_SCOPE, cel.StringType),
+		cel.Variable(VAR_DYNAMIC, cel.BoolType),
+		cel.Variable(VAR_OPTIONAL, cel.BoolType),
+		cel.Variable(VAR_GUARDS, cel.ListType(cel.StringType)),
+	)
+}
+
+// Rule flags every import for which its expression is true
+type Rule struct {
+	Name       string `yaml:"name"`
+	Expression string `yaml:"expression"`
+
+	program cel.Program
+}
+
+// Config is the list of the rules of a project, e.g.
+//
+//	rules:
+//	  - name: No pickle outside of the tests
+//	    expression: pkg == "pickle" && !path.startsWith("tests/")
+//	  - name: Only approved packages
+//	    expression: classification == "third-party" && !(pkg in ["requests", "django"])
+//	  - name: No lazy imports
+//	    expression: guards.exists(g, g == "lazy")
+type Config struct {
+	Rules []*Rule `yaml:"rules"`
+}
+
+// Violation is an import flagged by a rule
+type Violation struct {
+	Rule    *Rule
+	Module  string // imported module
+	Path    string
+	Node    imports.TypedValue // import statement, or the imported module when the statement is not known
+	Message string
+}
+
+// LoadConfig reads the rules of a file and compiles their expressions
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseConfig(data)
+}
+
+// ParseConfig parses rules in YAML and compiles their expressions. Unknown fields
+// are rejected so that misspelled rules are not silently ignored, and expressions
+// using unknown variables or functions, or not evaluating to a bool, are rejected
+// so that broken rules are not silently passed.
+func ParseConfig(data []byte) (*Config, error) {
+	config := &Config{}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(config); err != nil {
+		return nil, fmt.Errorf("invalid policy config: %w", err)
+	}
+
+	env, err := newEnv()
+	if err != nil {
+		return nil, err
+	}
+
+	for i, rule := range config.Rules {
+		if rule.Name == "" {
+			rule.Name = fmt.Sprintf("rule %d", i+1)
+		}
+		program, err := compile(env, rule.Expression)
+		if err != nil {
+			return nil, fmt.Errorf("invalid rule %q: %w", rule.Name, err)
+		}
+		rule.program = program
+	}
+
+	return config, nil
+}
+
+// compile parses and type-checks an expression, which must evaluate to a bool
+func compile(env *cel.Env, expression string) (cel.Program, error) {
+	if strings.TrimSpace(expression) == "" {
+		return nil, fmt.Errorf("missing expression")
+	}
+
+	ast, issues := env.Compile(expression)
+	if issues != nil && issues.Err() != nil {
+		return nil, issues.Err()
+	}
+	if ast.OutputType() != cel.BoolType {
+		return nil, fmt.Errorf("expression must evaluate to a bool, got %s", ast.OutputType())
+	}
+
+	return env.Program(ast)
+}
+
+// Evaluate evaluates the rules on every import of the analyzed files and returns
+// the violations sorted by file and row. A rule failing on an import, e.g. indexing
+// past the end of the guards, is an error, so that a broken rule does not pass.
+func Evaluate(repoAnalysis *imports.RepoCodeAnalysis, config *Config) ([]*Violation, error) {
+	violations := make([]*Violation, 0)
+	seen := make(map[string]bool, 0)
+
+	for _, fa := range repoAnalysis.FilesAnalysis {
+		for _, mod := range fa.Modules {
+			vars := variablesOf(fa, mod)
+			for _, rule := range config.Rules {
+				value, _, err := rule.program.Eval(vars)
+				if err != nil {
+					return nil, fmt.Errorf("error while evaluating rule %q on %s: %w", rule.Name, fa.Path, err)
+				}
+				if matched, ok := value.Value().(bool); !ok || !matched {
+					continue
+				}
+
+				module := vars[VAR_MODULE].(string)
+				v := &Violation{Rule: rule, Module: module, Path: fa.Path,
+					Node:    mod.Name,
+					Message: fmt.Sprintf("import of %s is not allowed (%s)", module, rule.Name)}
+				if mod.Statement != nil {
+					v.Node = *mod.Statement
+				}
+
+				// Every name of a statement is an import, report the statement once
+				key := fmt.Sprintf("%s:%s:%d:%s", rule.Name, v.Path, v.Node.StartByte, v.Module)
+				if !seen[key] {
+					seen[key] = true
+					violations = append(violations, v)
+				}
+			}
+		}
+	}
+
+	sort.SliceStable(violations, func(i, j int) bool {
+		if violations[i].Path != violations[j].Path {
+			return violations[i].Path < violations[j].Path
+		}
+		return violations[i].Node.StartByte < violations[j].Node.StartByte
+	})
+	return violations, nil
+}
+
+// variablesOf returns the values of the variables of the expressions for an import
+func variablesOf(fa *imports.FileCodeAnalysis, mod *imports.ImportedModule) map[string]interface{} {
+	module := mod.AbsoluteName
+	if module == "" {
+		module = mod.Name.V
+	}
+	name := ""
+	if mod.Definition != nil {
+		name = mod.Definition.V
+	}
+	guards := make([]string, 0, len(mod.Guards))
+	for _, guard := range mod.Guards {
+		guards = append(guards, string(guard))
+	}
+
+	return map[string]interface{}{
+		VAR_PACKAGE:        dir.SplitAndGetLeftMost(strings.TrimLeft(module, "."), "."),
+		VAR_MODULE:         module,
+		VAR_NAME:           name,
+		VAR_CLASSIFICATION: string(mod.Classification),
+		VAR_PATH:           fa.Path,
+		VAR_SCOPE:          string(fa.Scope),
+		VAR_DYNAMIC:        mod.Dynamic,
+		VAR_OPTIONAL:       mod.IsOptional(),
+		VAR_GUARDS:         guards,
+	}
+}
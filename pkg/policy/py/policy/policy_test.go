@@ -0,0 +1,119 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/safedep/codex/pkg/parser/py/imports"
+	"github.com/stretchr/testify/assert"
+)
+
+func evaluateFiles(t *testing.T, files map[string]string, config string) []string {
+	rootDir := t.TempDir()
+	for relPath, code := range files {
+		fullPath := path.Join(rootDir, relPath)
+		assert.NoError(t, os.MkdirAll(path.Dir(fullPath), os.ModePerm))
+		assert.NoError(t, os.WriteFile(fullPath, []byte(code), 0644))
+	}
+
+	c, err := ParseConfig([]byte(config))
+	assert.NoError(t, err)
+
+	parser, err := imports.NewPyCodeParserFactory().NewCodeParser()
+	assert.NoError(t, err)
+
+	importedModules, err := parser.FindImportedModules(context.Background(), rootDir, true, []string{".py"}, []string{})
+	assert.NoError(t, err)
+
+	violations, err := Evaluate(importedModules.GetRepoCodeAnalysis(), c)
+	assert.NoError(t, err)
+
+	messages := []string{}
+	for _, v := range violations {
+		messages = append(messages, fmt.Sprintf("%s:%d %s", v.Path, v.Node.RowStart+1, v.Message))
+	}
+	return messages
+}
+
+func TestEvaluate(t *testing.T) {
+	files := map[string]string{
+		"app/__init__.py":   "",
+		"app/util.py":       "",
+		"app/main.py":       "import pickle\nimport telnetlib\nimport requests, yaml\nfrom yaml import load, dump\nfrom . import util\n",
+		"app/loader.py":     "import importlib\nmod = importlib.import_module('marshal')\n",
+		"tests/test_app.py": "import pickle\n",
+	}
+
+	assert.Equal(t, []string{
+		"app/loader.py:2 import of marshal is not allowed (rule 4)",
+		"app/main.py:1 import of pickle is not allowed (No pickle outside of the tests)",
+		"app/main.py:2 import of telnetlib is not allowed (No telnetlib)",
+		"app/main.py:3 import of yaml is not allowed (Only approved packages)",
+		"app/main.py:4 import of yaml is not allowed (Only approved packages)",
+	}, evaluateFiles(t, files, `
+rules:
+  - name: No pickle outside of the tests
+    expression: pkg == "pickle" && !path.startsWith("tests/")
+  - name: No telnetlib
+    expression: pkg == "telnetlib"
+  - name: Only approved packages
+    expression: classification == "third-party" && !(pkg in ["requests", "django"])
+  - expression: dynamic
+`))
+
+	assert.Equal(t, []string{
+		"app/main.py:5 import of app.util is not allowed (first-party)",
+	}, evaluateFiles(t, files, `
+rules:
+  - name: first-party
+    expression: classification == "first-party" && module == "app.util" && name == "util" && scope == "runtime"
+`))
+
+	assert.Equal(t, []string{
+		"app/loader.py:2 import of marshal is not allowed (macros)",
+		"app/main.py:2 import of telnetlib is not allowed (strings)",
+	}, evaluateFiles(t, files, `
+rules:
+  - name: macros
+    expression: guards.all(g, g != "type-checking") && dynamic && [pkg].exists(p, p.size() == 1 + 6)
+  - name: strings
+    expression: pkg + "." + 'x' == "telnetlib.x" && r"\d" == '\\d' && """tel""" + "netlib" == pkg
+`))
+}
+
+func TestEvaluateError(t *testing.T) {
+	rootDir := t.TempDir()
+	assert.NoError(t, os.WriteFile(path.Join(rootDir, "app.py"), []byte("import yaml\n"), 0644))
+
+	c, err := ParseConfig([]byte("rules:\n  - name: first guard\n    expression: guards[0] == 'lazy'\n"))
+	assert.NoError(t, err)
+
+	parser, err := imports.NewPyCodeParserFactory().NewCodeParser()
+	assert.NoError(t, err)
+	importedModules, err := parser.FindImportedModules(context.Background(), rootDir, true, []string{".py"}, []string{})
+	assert.NoError(t, err)
+
+	_, err = Evaluate(importedModules.GetRepoCodeAnalysis(), c)
+	assert.ErrorContains(t, err, `error while evaluating rule "first guard" on app.py`)
+}
+
+func TestParseConfigInvalid(t *testing.T) {
+	invalid := []string{
+		"rules:\n  - name: a\n    expression: license == 'MIT'\n",
+		"rules:\n  - name: a\n",
+		"rules:\n  - name: a\n    expr: pkg == 'a'\n",
+		"rules:\n  - name: a\n    expression: pkg.lowerAscii() == 'a'\n",
+		"rules:\n  - name: a\n    expression: has(pkg)\n",
+		"rules:\n  - name: a\n    expression: path.split('/')[0] == 'a'\n",
+		"rules:\n  - name: a\n    expression: pkg < 1\n",
+		"rules:\n  - name: a\n    expression: pkg\n",
+		"rules:\n  - name: a\n    expression: pkg ==\n",
+	}
+	for _, config := range invalid {
+		_, err := ParseConfig([]byte(config))
+		assert.Error(t, err, config)
+	}
+}
@@ -0,0 +1,152 @@
+/*
+	Find whether the code of a Python repository can reach vulnerable functions
+*/
+
+package reachability
+
+import (
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/safedep/codex/pkg/callgraph/py/callgraph"
+	"github.com/safedep/codex/pkg/manifest/py/manifest"
+	"github.com/safedep/codex/pkg/parser/py/imports"
+)
+
+type EntryPointKind string
+
+const (
+	ENTRY_POINT_MAIN           EntryPointKind = "main"           // if __name__ == "__main__": blocks and __main__.py
+	ENTRY_POINT_CONSOLE_SCRIPT EntryPointKind = "console-script" // commands declared in the manifests
+	ENTRY_POINT_WEB_HANDLER    EntryPointKind = "web-handler"    // Flask, FastAPI and Django handlers
+	ENTRY_POINT_TEST           EntryPointKind = "test"           // test functions of test files
+)
+
+// Last part of the decorators of web handlers, e.g. app.route("/") or router.get("/")
+var webHandlerDecorators = []string{"route", "get", "post", "put", "patch", "delete", "head", "options",
+	"websocket", "api_view", "view_config"}
+
+// Methods of Django class based views
+var djangoViewMethods = []string{"get", "post", "put", "patch", "delete", "head", "options", "dispatch"}
+
+// EntryPoint is a node of the call graph run by the interpreter or by a framework
+type EntryPoint struct {
+	Node   string // ID of the node
+	Kind   EntryPointKind
+	Path   string // file of the node
+	Row    uint32 // row of the definition (0 based)
+	Reason string // script name or decorator making the node an entry point
+}
+
+// FindEntryPoints finds the entry points of the repository. The module level code
+// of the files of the entry points is an entry point too, as it runs when the
+// files are loaded.
+func FindEntryPoints(g *callgraph.Graph, repoSymbols *imports.RepoSymbols,
+	scripts []*manifest.ConsoleScript) []*EntryPoint {
+	found := make(map[string]*EntryPoint, 0)
+	add := func(node *callgraph.Node, kind EntryPointKind, reason string) {
+		if _, exists := found[node.ID]; exists {
+			return
+		}
+		found[node.ID] = &EntryPoint{Node: node.ID, Kind: kind, Path: node.Key.Path,
+			Row: node.Row, Reason: reason}
+
+		// The module of the entry point is loaded before it runs
+		if module, ok := g.GetNodeByKey(imports.MethodMapKey{Path: node.Key.Path,
+			Name: imports.MODULE_SCOPE}); ok && module.ID != node.ID {
+			if _, exists := found[module.ID]; !exists {
+				found[module.ID] = &EntryPoint{Node: module.ID, Kind: kind, Path: node.Key.Path,
+					Reason: reason}
+			}
+		}
+	}
+
+	for _, script := range scripts {
+		if node, ok := g.GetNode(script.Module + "." + script.Function); ok {
+			add(node, ENTRY_POINT_CONSOLE_SCRIPT, script.Name)
+		}
+	}
+
+	for _, symbols := range repoSymbols.Files {
+		if symbols.HasMainBlock || path.Base(symbols.Path) == "__main__.py" {
+			if node, ok := g.GetNodeByKey(imports.MethodMapKey{Path: symbols.Path,
+				Name: imports.MODULE_SCOPE}); ok {
+				add(node, ENTRY_POINT_MAIN, "__main__")
+			}
+		}
+
+		for _, function := range symbols.Functions {
+			node, ok := g.GetNodeByKey(function.Key)
+			if !ok {
+				continue
+			}
+
+			if decorator, ok := findWebHandlerDecorator(function); ok {
+				add(node, ENTRY_POINT_WEB_HANDLER, decorator)
+			} else if isDjangoView(symbols.Path, function.Key) {
+				add(node, ENTRY_POINT_WEB_HANDLER, "django view")
+			} else if isTestFunction(symbols.Path, function.Key) {
+				add(node, ENTRY_POINT_TEST, "test")
+			}
+		}
+	}
+
+	entryPoints := make([]*EntryPoint, 0, len(found))
+	for _, entryPoint := range found {
+		entryPoints = append(entryPoints, entryPoint)
+	}
+	sort.Slice(entryPoints, func(i, j int) bool {
+		return entryPoints[i].Node < entryPoints[j].Node
+	})
+
+	return entryPoints
+}
+
+// findWebHandlerDecorator returns the decorator routing requests to the function
+func findWebHandlerDecorator(function *imports.FunctionDefinition) (string, bool) {
+	for _, decorator := range function.Decorators {
+		name, _, _ := strings.Cut(decorator, "(")
+		parts := strings.Split(strings.TrimSpace(name), ".")
+		last := parts[len(parts)-1]
+		if !contains(webHandlerDecorators, last) {
+			continue
+		}
+
+		// HTTP methods are only decorators of routers, e.g. app.get
+		if len(parts) > 1 || last == "route" || last == "api_view" || last == "view_config" {
+			return decorator, true
+		}
+	}
+	return "", false
+}
+
+// isDjangoView tells whether the function is a view of a views module, either
+// a function or a request method of a class based view
+func isDjangoView(filePath string, key imports.MethodMapKey) bool {
+	isViews := path.Base(filePath) == "views.py" || path.Base(path.Dir(filePath)) == "views"
+	if !isViews || strings.HasPrefix(key.Name, "_") || strings.Contains(key.Name, ".") {
+		return false
+	}
+
+	if key.ClassName == "" {
+		return true
+	}
+	return !strings.Contains(key.ClassName, ".") && contains(djangoViewMethods, key.Name)
+}
+
+// isTestFunction tells whether pytest or unittest run the function
+func isTestFunction(filePath string, key imports.MethodMapKey) bool {
+	base := path.Base(filePath)
+	isTestFile := (strings.HasPrefix(base, "test_") || strings.HasSuffix(base, "_test.py"))
+	return isTestFile && strings.HasPrefix(key.Name, "test") && !strings.Contains(key.Name, ".")
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,151 @@
+package reachability
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/safedep/codex/pkg/callgraph/py/callgraph"
+)
+
+type MatchKind string
+
+const (
+	MATCH_CALL  MatchKind = "call"  // the symbol or a member of it is called
+	MATCH_CLASS MatchKind = "class" // the class of the method is instantiated, calls of the method are not resolved
+)
+
+// Step is a call or an import on the path from an entry point to a symbol
+type Step struct {
+	Caller    string // ID of the calling or importing node
+	Callee    string // ID of the called or imported node
+	Import    bool   // the caller imports the module of the callee
+	Path      string // file of the call or of the import
+	Row       uint32 // row of the call or of the import (0 based)
+	Column    uint32 // column of the call or of the import (0 based, in unicode code points)
+	ColumnEnd uint32 // column after the end of the call or of the import (0 based, in unicode code points)
+	StartByte uint32 // offset of the call or of the import in the file
+	EndByte   uint32 // offset after the call or the import in the file
+}
+
+// Result tells whether a vulnerable symbol is reachable, and how
+type Result struct {
+	Symbol     string // qualified name of the symbol, e.g. yaml.load
+	Reachable  bool
+	Match      MatchKind
+	Node       string      // node matching the symbol
+	EntryPoint *EntryPoint // entry point of the shortest path to the node
+	Steps      []*Step     // path from the entry point to the node
+}
+
+// visit is how a node was first reached
+type visit struct {
+	parent     string
+	step       *Step
+	entryPoint *EntryPoint
+}
+
+// Analyze finds the shortest path from an entry point to every symbol through
+// the calls and the imports of the call graph. Symbols are qualified names such
+// as yaml.load or jinja2.Template.render, and match the calls of the symbol or
+// of its members. A method which is not called directly is matched by the
+// instantiation of its class, as calls of methods of objects are not resolved.
+func Analyze(g *callgraph.Graph, entryPoints []*EntryPoint, symbols []string) []*Result {
+	visits, order := search(g, entryPoints)
+
+	results := make([]*Result, 0, len(symbols))
+	for _, symbol := range symbols {
+		result := &Result{Symbol: symbol}
+		node, match, ok := findMatch(g, order, symbol)
+		if ok {
+			result.Reachable = true
+			result.Match = match
+			result.Node = node
+			result.EntryPoint, result.Steps = pathTo(visits, node)
+		}
+		results = append(results, result)
+	}
+
+	return results
+}
+
+// search visits the graph breadth first from the entry points. It returns how
+// every node was reached and the nodes in the order of their distance.
+func search(g *callgraph.Graph, entryPoints []*EntryPoint) (map[string]*visit, []string) {
+	visits := make(map[string]*visit, 0)
+	order := make([]string, 0)
+	for _, entryPoint := range entryPoints {
+		if _, ok := visits[entryPoint.Node]; !ok {
+			visits[entryPoint.Node] = &visit{entryPoint: entryPoint}
+			order = append(order, entryPoint.Node)
+		}
+	}
+
+	for i := 0; i < len(order); i++ {
+		id := order[i]
+		steps := make([]*Step, 0)
+		for _, edge := range g.GetImports(id) {
+			steps = append(steps, &Step{Caller: edge.Caller, Callee: edge.Callee, Import: true,
+				Path: edge.Path, Row: edge.Row, Column: edge.Column, ColumnEnd: edge.ColumnEnd,
+				StartByte: edge.StartByte, EndByte: edge.EndByte})
+		}
+		for _, edge := range g.GetCallees(id) {
+			steps = append(steps, &Step{Caller: edge.Caller, Callee: edge.Callee,
+				Path: edge.Path, Row: edge.Row, Column: edge.Column, ColumnEnd: edge.ColumnEnd,
+				StartByte: edge.StartByte, EndByte: edge.EndByte})
+		}
+
+		for _, step := range steps {
+			if _, ok := visits[step.Callee]; ok {
+				continue
+			}
+			visits[step.Callee] = &visit{parent: id, step: step}
+			order = append(order, step.Callee)
+		}
+	}
+
+	return visits, order
+}
+
+// findMatch returns the closest reached node matching the symbol
+func findMatch(g *callgraph.Graph, order []string, symbol string) (string, MatchKind, bool) {
+	for _, id := range order {
+		if id == symbol || strings.HasPrefix(id, symbol+".") {
+			return id, MATCH_CALL, true
+		}
+	}
+
+	for _, id := range order {
+		node, _ := g.GetNode(id)
+		if node.Kind == callgraph.NODE_EXTERNAL && isMethodOfClass(symbol, id) {
+			return id, MATCH_CLASS, true
+		}
+	}
+
+	return "", "", false
+}
+
+// isMethodOfClass tells whether the symbol is a method of the class, e.g.
+// jinja2.Template.render of jinja2.Template
+func isMethodOfClass(symbol string, class string) bool {
+	method, found := strings.CutPrefix(symbol, class+".")
+	if !found || method == "" || strings.Contains(method, ".") {
+		return false
+	}
+
+	// Classes are named in CamelCase
+	parts := strings.Split(class, ".")
+	name := []rune(parts[len(parts)-1])
+	return len(name) > 0 && unicode.IsUpper(name[0])
+}
+
+// pathTo returns the entry point and the steps reaching the node
+func pathTo(visits map[string]*visit, node string) (*EntryPoint, []*Step) {
+	steps := make([]*Step, 0)
+	v := visits[node]
+	for v.entryPoint == nil {
+		steps = append([]*Step{v.step}, steps...)
+		v = visits[v.parent]
+	}
+
+	return v.entryPoint, steps
+}
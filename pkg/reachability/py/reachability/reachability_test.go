@@ -0,0 +1,124 @@
+package reachability
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/safedep/codex/pkg/callgraph/py/callgraph"
+	"github.com/safedep/codex/pkg/manifest/py/manifest"
+	"github.com/safedep/codex/pkg/parser/py/imports"
+	"github.com/stretchr/testify/assert"
+)
+
+func analyzeTestFiles(t *testing.T, files map[string]string, symbols []string) ([]*EntryPoint, []*Result) {
+	rootDir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(rootDir, name)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		assert.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+
+	parser, err := imports.NewPyCodeParserFactory().NewCodeParser()
+	assert.NoError(t, err)
+	repoSymbols, err := parser.FindSymbols(context.Background(), rootDir, true, []string{".py"}, []string{})
+	assert.NoError(t, err)
+
+	manifests, err := manifest.FindManifests(rootDir)
+	assert.NoError(t, err)
+
+	g := callgraph.Build(rootDir, repoSymbols)
+	entryPoints := FindEntryPoints(g, repoSymbols, manifest.GetConsoleScripts(manifests))
+	return entryPoints, Analyze(g, entryPoints, symbols)
+}
+
+func TestFindEntryPoints(t *testing.T) {
+	entryPoints, _ := analyzeTestFiles(t, map[string]string{
+		"pyproject.toml":    "[project.scripts]\napp = \"app.cli:main\"\n",
+		"app/__init__.py":   "",
+		"app/cli.py":        "def main():\n    pass\n\ndef _helper():\n    pass\n",
+		"app/web.py":        "@app.route('/')\ndef index():\n    pass\n\n@router.get('/items')\ndef items():\n    pass\n\n@get\ndef not_a_route():\n    pass\n",
+		"app/views.py":      "class ItemView(View):\n    def get(self, request):\n        pass\n\n    def _render(self):\n        pass\n\ndef home(request):\n    pass\n",
+		"run.py":            "if __name__ == \"__main__\":\n    pass\n",
+		"tests/test_cli.py": "def test_main():\n    pass\n\ndef helper():\n    pass\n",
+		"tests/conftest.py": "def test_like_fixture():\n    pass\n",
+		"app/__main__.py":   "",
+	}, nil)
+
+	found := make(map[string]EntryPointKind, 0)
+	for _, entryPoint := range entryPoints {
+		found[entryPoint.Node] = entryPoint.Kind
+	}
+
+	assert.Equal(t, map[string]EntryPointKind{
+		"app.__main__":           ENTRY_POINT_MAIN,
+		"app.cli":                ENTRY_POINT_CONSOLE_SCRIPT,
+		"app.cli.main":           ENTRY_POINT_CONSOLE_SCRIPT,
+		"app.views":              ENTRY_POINT_WEB_HANDLER,
+		"app.views.ItemView.get": ENTRY_POINT_WEB_HANDLER,
+		"app.views.home":         ENTRY_POINT_WEB_HANDLER,
+		"app.web":                ENTRY_POINT_WEB_HANDLER,
+		"app.web.index":          ENTRY_POINT_WEB_HANDLER,
+		"app.web.items":          ENTRY_POINT_WEB_HANDLER,
+		"run":                    ENTRY_POINT_MAIN,
+		"test_cli":               ENTRY_POINT_TEST,
+		"test_cli.test_main":     ENTRY_POINT_TEST,
+	}, found)
+	assert.Equal(t, "app.cli", entryPoints[1].Node)
+	assert.Equal(t, "app", entryPoints[1].Reason)
+}
+
+func TestAnalyze(t *testing.T) {
+	_, results := analyzeTestFiles(t, map[string]string{
+		"app/__init__.py": "",
+		"app/config.py":   "import yaml\n\ndef load(path):\n    return yaml.load(open(path))\n",
+		"app/render.py":   "from jinja2 import Template\n\ndef render(text):\n    return Template(text).render()\n",
+		"app/main.py": `from app.config import load
+
+def main():
+    load("config.yaml")
+
+if __name__ == "__main__":
+    main()
+`,
+		"app/unused.py":  "import requests\n\ndef fetch():\n    requests.get('https://example.com')\n",
+		"app/startup.py": "import pickle\n\nSTATE = pickle.loads(b'')\n",
+		"app/web.py":     "import app.startup\nfrom app import render\n\n@app.route('/')\ndef index():\n    return render.render('hi')\n",
+	}, []string{"yaml.load", "jinja2.Template.render", "requests.get", "pickle.loads", "yaml"})
+
+	assert.Equal(t, 5, len(results))
+
+	yamlLoad := results[0]
+	assert.True(t, yamlLoad.Reachable)
+	assert.Equal(t, MATCH_CALL, yamlLoad.Match)
+	assert.Equal(t, "yaml.load", yamlLoad.Node)
+	assert.Equal(t, "app.main", yamlLoad.EntryPoint.Node)
+	assert.Equal(t, ENTRY_POINT_MAIN, yamlLoad.EntryPoint.Kind)
+	assert.Equal(t, []*Step{
+		{Caller: "app.main", Callee: "app.main.main", Path: "app/main.py", Row: 6, Column: 4, ColumnEnd: 10, StartByte: 97, EndByte: 103},
+		{Caller: "app.main.main", Callee: "app.config.load", Path: "app/main.py", Row: 3, Column: 4, ColumnEnd: 23, StartByte: 45, EndByte: 64},
+		{Caller: "app.config.load", Callee: "yaml.load", Path: "app/config.py", Row: 3, Column: 11, ColumnEnd: 32, StartByte: 40, EndByte: 61},
+	}, yamlLoad.Steps)
+
+	render := results[1]
+	assert.True(t, render.Reachable)
+	assert.Equal(t, MATCH_CLASS, render.Match)
+	assert.Equal(t, "jinja2.Template", render.Node)
+	assert.Equal(t, "app.web.index", render.EntryPoint.Node)
+	assert.Equal(t, ENTRY_POINT_WEB_HANDLER, render.EntryPoint.Kind)
+	assert.Equal(t, 2, len(render.Steps))
+
+	assert.False(t, results[2].Reachable)
+	assert.Nil(t, results[2].Steps)
+
+	loads := results[3]
+	assert.True(t, loads.Reachable)
+	assert.Equal(t, []*Step{
+		{Caller: "app.web", Callee: "app.startup", Import: true, Path: "app/web.py", Row: 0, ColumnEnd: 18, EndByte: 18},
+		{Caller: "app.startup", Callee: "pickle.loads", Path: "app/startup.py", Row: 2, Column: 8, ColumnEnd: 25, StartByte: 23, EndByte: 40},
+	}, loads.Steps)
+
+	assert.True(t, results[4].Reachable)
+	assert.Equal(t, "yaml.load", results[4].Node)
+}
@@ -0,0 +1,608 @@
+/*
+	Versioned, machine readable schema of the scan results
+*/
+
+package report
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/safedep/codex/pkg/architecture/py/architecture"
+	"github.com/safedep/codex/pkg/callgraph/py/callgraph"
+	"github.com/safedep/codex/pkg/manifest/py/manifest"
+	"github.com/safedep/codex/pkg/modulegraph/py/modulegraph"
+	"github.com/safedep/codex/pkg/parser/py/imports"
+	"github.com/safedep/codex/pkg/policy/py/policy"
+	"github.com/safedep/codex/pkg/reachability/py/reachability"
+	"github.com/safedep/codex/pkg/usage/py/usage"
+)
+
+// SCHEMA_VERSION is bumped on its minor version when fields are added
+// and on its major version when fields are removed or change their meaning
+const SCHEMA_VERSION = "1.12.0"
+
+// Report is the root of the output of every scan command. Sections which
+// are not produced by a command are omitted.
+type Report struct {
+	SchemaVersion   string             `json:"schema_version" yaml:"schema_version"`
+	Command         string             `json:"command" yaml:"command"`
+	Input           string             `json:"input" yaml:"input"`
+	ImportedModules []*ImportedPackage `json:"imported_modules,omitempty" yaml:"imported_modules,omitempty"`
+	ExportedModules []*ExportedModule  `json:"exported_modules,omitempty" yaml:"exported_modules,omitempty"`
+	Files           []*FileAnalysis    `json:"files,omitempty" yaml:"files,omitempty"`
+	ManifestDrift   *ManifestDrift     `json:"manifest_drift,omitempty" yaml:"manifest_drift,omitempty"`
+	CallGraph       *CallGraph         `json:"call_graph,omitempty" yaml:"call_graph,omitempty"`
+	Reachability    *Reachability      `json:"reachability,omitempty" yaml:"reachability,omitempty"`
+	ApiUsage        []*PackageUsage    `json:"api_usage,omitempty" yaml:"api_usage,omitempty"`
+	ModuleGraph     *ModuleGraph       `json:"module_graph,omitempty" yaml:"module_graph,omitempty"`
+	ImportCycles    []*ImportCycle     `json:"import_cycles,omitempty" yaml:"import_cycles,omitempty"`
+	Violations      []*Violation       `json:"violations,omitempty" yaml:"violations,omitempty"`
+	Findings        []*Finding         `json:"findings,omitempty" yaml:"findings,omitempty"`
+	Errors          []*ParseError      `json:"errors,omitempty" yaml:"errors,omitempty"`
+}
+
+// ImportedPackage is a top-level third-party package with every place it is imported
+type ImportedPackage struct {
+	Name        string        `json:"name" yaml:"name"`
+	Optional    bool          `json:"optional" yaml:"optional"`
+	Unused      bool          `json:"unused,omitempty" yaml:"unused,omitempty"`
+	Scope       string        `json:"scope" yaml:"scope"`
+	Occurrences []*Occurrence `json:"occurrences" yaml:"occurrences"`
+}
+
+// Occurrence is an import statement, lines are 1 based
+type Occurrence struct {
+	Path      string   `json:"path" yaml:"path"`
+	LineStart uint32   `json:"line_start" yaml:"line_start"`
+	LineEnd   uint32   `json:"line_end" yaml:"line_end"`
+	Statement string   `json:"statement" yaml:"statement"`
+	Region    *Region  `json:"region,omitempty" yaml:"region,omitempty"`
+	Guards    []string `json:"guards,omitempty" yaml:"guards,omitempty"`
+	Scope     string   `json:"scope,omitempty" yaml:"scope,omitempty"`
+	Unused    bool     `json:"unused,omitempty" yaml:"unused,omitempty"`
+}
+
+type ExportedModule struct {
+	Name string `json:"name" yaml:"name"`
+	Path string `json:"path" yaml:"path"`
+}
+
+// FileAnalysis lists every module imported by a file
+type FileAnalysis struct {
+	Path     string     `json:"path" yaml:"path"`
+	Scope    string     `json:"scope,omitempty" yaml:"scope,omitempty"`
+	Modules  []*Module  `json:"modules" yaml:"modules"`
+	Findings []*Finding `json:"findings,omitempty" yaml:"findings,omitempty"`
+}
+
+type Module struct {
+	Name           string   `json:"name" yaml:"name"`
+	Definition     string   `json:"definition,omitempty" yaml:"definition,omitempty"`
+	Alias          string   `json:"alias,omitempty" yaml:"alias,omitempty"`
+	LineStart      uint32   `json:"line_start" yaml:"line_start"`
+	LineEnd        uint32   `json:"line_end" yaml:"line_end"`
+	Classification string   `json:"classification,omitempty" yaml:"classification,omitempty"`
+	Dynamic        bool     `json:"dynamic,omitempty" yaml:"dynamic,omitempty"`
+	Loader         string   `json:"loader,omitempty" yaml:"loader,omitempty"`
+	Guards         []string `json:"guards,omitempty" yaml:"guards,omitempty"`
+	AbsoluteName   string   `json:"absolute_name,omitempty" yaml:"absolute_name,omitempty"`
+	ModulePath     string   `json:"module_path,omitempty" yaml:"module_path,omitempty"`
+}
+
+type Finding struct {
+	Type      string  `json:"type" yaml:"type"`
+	Message   string  `json:"message" yaml:"message"`
+	Path      string  `json:"path" yaml:"path"`
+	LineStart uint32  `json:"line_start" yaml:"line_start"`
+	LineEnd   uint32  `json:"line_end" yaml:"line_end"`
+	Region    *Region `json:"region,omitempty" yaml:"region,omitempty"`
+}
+
+// Region is the exact span of a location between its lines. Columns are 1 based
+// and count unicode code points, bytes are 0 based offsets in the file. The end
+// column and the end byte are after the last character.
+type Region struct {
+	ColumnStart uint32 `json:"column_start" yaml:"column_start"`
+	ColumnEnd   uint32 `json:"column_end" yaml:"column_end"`
+	ByteStart   uint32 `json:"byte_start" yaml:"byte_start"`
+	ByteEnd     uint32 `json:"byte_end" yaml:"byte_end"`
+}
+
+// Violation is an import breaking a rule of a check command
+type Violation struct {
+	Check     string  `json:"check" yaml:"check"`
+	Rule      string  `json:"rule" yaml:"rule"`
+	Message   string  `json:"message" yaml:"message"`
+	Path      string  `json:"path" yaml:"path"`
+	LineStart uint32  `json:"line_start" yaml:"line_start"`
+	LineEnd   uint32  `json:"line_end" yaml:"line_end"`
+	Region    *Region `json:"region,omitempty" yaml:"region,omitempty"`
+	Statement string  `json:"statement,omitempty" yaml:"statement,omitempty"`
+}
+
+type ParseError struct {
+	Path    string `json:"path" yaml:"path"`
+	Message string `json:"message" yaml:"message"`
+}
+
+type ManifestDrift struct {
+	Unused     []*DeclaredDependency   `json:"unused" yaml:"unused"`
+	Undeclared []*UndeclaredDependency `json:"undeclared" yaml:"undeclared"`
+}
+
+type DeclaredDependency struct {
+	Name      string `json:"name" yaml:"name"`
+	Specifier string `json:"specifier,omitempty" yaml:"specifier,omitempty"`
+	Group     string `json:"group,omitempty" yaml:"group,omitempty"`
+	Manifest  string `json:"manifest" yaml:"manifest"`
+	Line      int    `json:"line,omitempty" yaml:"line,omitempty"`
+}
+
+type UndeclaredDependency struct {
+	ImportName   string        `json:"import_name" yaml:"import_name"`
+	Distribution string        `json:"distribution" yaml:"distribution"`
+	Confidence   string        `json:"confidence" yaml:"confidence"`
+	Candidates   []string      `json:"candidates" yaml:"candidates"`
+	Occurrences  []*Occurrence `json:"occurrences" yaml:"occurrences"`
+}
+
+// CallGraph lists the functions of the repository and the calls between them
+type CallGraph struct {
+	Nodes           []*CallGraphNode `json:"nodes" yaml:"nodes"`
+	Edges           []*CallGraphEdge `json:"edges" yaml:"edges"`
+	UnresolvedCalls int              `json:"unresolved_calls" yaml:"unresolved_calls"`
+}
+
+// CallGraphNode is a function, the module level code of a file or an external
+// function. External functions have no path.
+type CallGraphNode struct {
+	ID        string `json:"id" yaml:"id"`
+	Kind      string `json:"kind" yaml:"kind"`
+	Path      string `json:"path,omitempty" yaml:"path,omitempty"`
+	ClassName string `json:"class_name,omitempty" yaml:"class_name,omitempty"`
+	Name      string `json:"name,omitempty" yaml:"name,omitempty"`
+	Line      uint32 `json:"line,omitempty" yaml:"line,omitempty"`
+}
+
+// CallGraphEdge is a call, lines are 1 based
+type CallGraphEdge struct {
+	Caller string `json:"caller" yaml:"caller"`
+	Callee string `json:"callee" yaml:"callee"`
+	Path   string `json:"path" yaml:"path"`
+	Line   uint32 `json:"line" yaml:"line"`
+}
+
+// Reachability lists the entry points of the repository and tells whether
+// they reach every vulnerable symbol
+type Reachability struct {
+	EntryPoints []*EntryPoint `json:"entry_points" yaml:"entry_points"`
+	Symbols     []*Reachable  `json:"symbols" yaml:"symbols"`
+}
+
+type EntryPoint struct {
+	Node   string `json:"node" yaml:"node"`
+	Kind   string `json:"kind" yaml:"kind"`
+	Path   string `json:"path" yaml:"path"`
+	Line   uint32 `json:"line" yaml:"line"`
+	Reason string `json:"reason,omitempty" yaml:"reason,omitempty"`
+}
+
+// Reachable is a vulnerable symbol with the shortest call path reaching it
+type Reachable struct {
+	Symbol     string      `json:"symbol" yaml:"symbol"`
+	Reachable  bool        `json:"reachable" yaml:"reachable"`
+	Match      string      `json:"match,omitempty" yaml:"match,omitempty"`
+	Node       string      `json:"node,omitempty" yaml:"node,omitempty"`
+	EntryPoint *EntryPoint `json:"entry_point,omitempty" yaml:"entry_point,omitempty"`
+	Path       []*CallStep `json:"path,omitempty" yaml:"path,omitempty"`
+}
+
+// CallStep is a call or an import of a call path, lines are 1 based
+type CallStep struct {
+	Caller string  `json:"caller" yaml:"caller"`
+	Callee string  `json:"callee" yaml:"callee"`
+	Import bool    `json:"import,omitempty" yaml:"import,omitempty"`
+	Path   string  `json:"path" yaml:"path"`
+	Line   uint32  `json:"line" yaml:"line"`
+	Column uint32  `json:"column,omitempty" yaml:"column,omitempty"` // 1 based, in unicode code points
+	Region *Region `json:"region,omitempty" yaml:"region,omitempty"`
+}
+
+// PackageUsage lists the symbols of a third-party package used by the code
+type PackageUsage struct {
+	Package string         `json:"package" yaml:"package"`
+	Symbols []*SymbolUsage `json:"symbols" yaml:"symbols"`
+}
+
+type SymbolUsage struct {
+	Symbol    string      `json:"symbol" yaml:"symbol"`
+	Kind      string      `json:"kind" yaml:"kind"`
+	Count     int         `json:"count" yaml:"count"`
+	Locations []*Location `json:"locations" yaml:"locations"`
+}
+
+// Location is a line of a file, lines are 1 based
+type Location struct {
+	Path string `json:"path" yaml:"path"`
+	Line uint32 `json:"line" yaml:"line"`
+}
+
+// ModuleGraph lists the modules of the repository, the third-party packages
+// and the imports between them
+type ModuleGraph struct {
+	Nodes []*ModuleGraphNode `json:"nodes" yaml:"nodes"`
+	Edges []*ModuleGraphEdge `json:"edges" yaml:"edges"`
+}
+
+// ModuleGraphNode is a module of the repository or a third-party package, packages have no path
+type ModuleGraphNode struct {
+	ID   string `json:"id" yaml:"id"`
+	Kind string `json:"kind" yaml:"kind"`
+	Path string `json:"path,omitempty" yaml:"path,omitempty"`
+}
+
+// ModuleGraphEdge is a module importing another module or a package. Deferred
+// edges are only imported inside functions or TYPE_CHECKING blocks.
+type ModuleGraphEdge struct {
+	From     string        `json:"from" yaml:"from"`
+	To       string        `json:"to" yaml:"to"`
+	Deferred bool          `json:"deferred,omitempty" yaml:"deferred,omitempty"`
+	Imports  []*Occurrence `json:"imports" yaml:"imports"`
+}
+
+// ImportCycle is a cycle of imports between modules of the repository, with the
+// modules of its strongly connected component and the edges of its shortest cycle.
+// Deferred cycles go through imports inside functions or TYPE_CHECKING blocks and
+// are harmless at runtime.
+type ImportCycle struct {
+	Modules  []string           `json:"modules" yaml:"modules"`
+	Deferred bool               `json:"deferred" yaml:"deferred"`
+	Path     []*ModuleGraphEdge `json:"path" yaml:"path"`
+}
+
+func NewReport(command, input string) *Report {
+	return &Report{SchemaVersion: SCHEMA_VERSION, Command: command, Input: input}
+}
+
+// AddImportedModules adds the imported packages, the analysis of every file,
+// the findings and the parse errors
+func (r *Report) AddImportedModules(importedModules *imports.ImportedModules) {
+	optional := make(map[string]bool, 0)
+	for _, pkg := range importedModules.GetOptionalDependencies() {
+		optional[pkg] = true
+	}
+
+	unused := make(map[string]bool, 0)
+	for _, pkg := range importedModules.GetUnusedDependencies() {
+		unused[pkg] = true
+	}
+
+	names := importedModules.GetPackagesNames()
+	sort.Strings(names)
+	for _, name := range names {
+		r.ImportedModules = append(r.ImportedModules, &ImportedPackage{Name: name,
+			Optional:    optional[name],
+			Unused:      unused[name],
+			Scope:       string(importedModules.GetScope(name)),
+			Occurrences: newOccurrences(importedModules.GetProvenance(name))})
+	}
+
+	if repoAnalysis := importedModules.GetRepoCodeAnalysis(); repoAnalysis != nil {
+		for _, fa := range repoAnalysis.FilesAnalysis {
+			r.AddFileAnalysis(fa)
+		}
+	}
+
+	for _, f := range importedModules.GetFindings() {
+		r.Findings = append(r.Findings, newFinding(f))
+	}
+
+	for _, e := range importedModules.GetParseErrors() {
+		r.Errors = append(r.Errors, &ParseError{Path: e.Path, Message: e.Err.Error()})
+	}
+}
+
+// AddFinding adds an issue found while analyzing the code
+func (r *Report) AddFinding(f *imports.Finding) {
+	r.Findings = append(r.Findings, newFinding(f))
+}
+
+// AddExportedModules adds the modules exported by the scanned package
+func (r *Report) AddExportedModules(exportedModules *imports.ExportedModules) {
+	paths := exportedModules.GetModulePaths()
+	names := make([]string, 0, len(paths))
+	for name := range paths {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	for _, name := range names {
+		r.ExportedModules = append(r.ExportedModules, &ExportedModule{Name: name, Path: paths[name]})
+	}
+}
+
+// AddFileAnalysis adds the modules imported by a single file
+func (r *Report) AddFileAnalysis(fa *imports.FileCodeAnalysis) {
+	file := &FileAnalysis{Path: fa.Path, Scope: string(fa.Scope), Modules: make([]*Module, 0)}
+
+	seen := make(map[string]bool, 0)
+	for _, mod := range fa.Modules {
+		m := newModule(mod)
+
+		// Skip repeated entries of the same import
+		key := fmt.Sprintf("%s|%s|%s|%d|%d|%s", m.Name, m.Definition, m.Alias,
+			m.LineStart, m.LineEnd, m.Loader)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		file.Modules = append(file.Modules, m)
+	}
+
+	for _, f := range fa.Findings {
+		file.Findings = append(file.Findings, newFinding(f))
+	}
+
+	r.Files = append(r.Files, file)
+	sort.SliceStable(r.Files, func(i, j int) bool {
+		return r.Files[i].Path < r.Files[j].Path
+	})
+}
+
+// AddManifestDrift adds the comparison of declared and imported dependencies
+func (r *Report) AddManifestDrift(drift *manifest.DriftReport) {
+	md := &ManifestDrift{Unused: make([]*DeclaredDependency, 0),
+		Undeclared: make([]*UndeclaredDependency, 0)}
+
+	for _, dep := range drift.Unused {
+		md.Unused = append(md.Unused, &DeclaredDependency{Name: dep.Name,
+			Specifier: dep.Specifier,
+			Group:     dep.Group,
+			Manifest:  dep.Manifest,
+			Line:      dep.Line})
+	}
+
+	for _, dep := range drift.Undeclared {
+		undeclared := &UndeclaredDependency{ImportName: dep.ImportName,
+			Candidates:  make([]string, 0),
+			Occurrences: newOccurrences(dep.Provenance)}
+		if best := dep.Resolution.Best(); best != nil {
+			undeclared.Distribution = best.Distribution
+			undeclared.Confidence = string(best.Confidence)
+		}
+		for _, candidate := range dep.Resolution.Candidates {
+			undeclared.Candidates = append(undeclared.Candidates, candidate.Distribution)
+		}
+		md.Undeclared = append(md.Undeclared, undeclared)
+	}
+
+	r.ManifestDrift = md
+}
+
+// AddCallGraph adds the functions and the calls of the call graph
+func (r *Report) AddCallGraph(g *callgraph.Graph) {
+	cg := &CallGraph{Nodes: make([]*CallGraphNode, 0),
+		Edges:           make([]*CallGraphEdge, 0),
+		UnresolvedCalls: len(g.GetUnresolvedCalls())}
+
+	for _, node := range g.GetNodes() {
+		n := &CallGraphNode{ID: node.ID, Kind: string(node.Kind)}
+		if node.Kind != callgraph.NODE_EXTERNAL {
+			n.Path = node.Key.Path
+			n.ClassName = node.Key.ClassName
+			n.Name = node.Key.Name
+		}
+		if node.Kind == callgraph.NODE_FUNCTION || node.Kind == callgraph.NODE_METHOD {
+			n.Line = node.Row + 1
+		}
+		cg.Nodes = append(cg.Nodes, n)
+	}
+
+	for _, edge := range g.GetEdges() {
+		cg.Edges = append(cg.Edges, &CallGraphEdge{Caller: edge.Caller,
+			Callee: edge.Callee,
+			Path:   edge.Path,
+			Line:   edge.Row + 1})
+	}
+
+	r.CallGraph = cg
+}
+
+// AddReachability adds the entry points and the reachability of the vulnerable symbols
+func (r *Report) AddReachability(entryPoints []*reachability.EntryPoint, results []*reachability.Result) {
+	rr := &Reachability{EntryPoints: make([]*EntryPoint, 0), Symbols: make([]*Reachable, 0)}
+	for _, entryPoint := range entryPoints {
+		rr.EntryPoints = append(rr.EntryPoints, newEntryPoint(entryPoint))
+	}
+
+	for _, result := range results {
+		reachable := &Reachable{Symbol: result.Symbol, Reachable: result.Reachable}
+		if result.Reachable {
+			reachable.Match = string(result.Match)
+			reachable.Node = result.Node
+			reachable.EntryPoint = newEntryPoint(result.EntryPoint)
+			reachable.Path = make([]*CallStep, 0, len(result.Steps))
+			for _, step := range result.Steps {
+				reachable.Path = append(reachable.Path, &CallStep{Caller: step.Caller,
+					Callee: step.Callee,
+					Import: step.Import,
+					Path:   step.Path,
+					Line:   step.Row + 1,
+					Column: step.Column + 1,
+					Region: newRegion(imports.TypedValue{ColumnStart: step.Column, ColumnEnd: step.ColumnEnd,
+						StartByte: step.StartByte,
+						EndByte:   step.EndByte})})
+			}
+		}
+		rr.Symbols = append(rr.Symbols, reachable)
+	}
+
+	r.Reachability = rr
+}
+
+// AddModuleGraph adds the modules, the packages and the imports of the graph
+func (r *Report) AddModuleGraph(g *modulegraph.Graph) {
+	mg := &ModuleGraph{Nodes: make([]*ModuleGraphNode, 0), Edges: make([]*ModuleGraphEdge, 0)}
+	for _, node := range g.GetNodes() {
+		mg.Nodes = append(mg.Nodes, &ModuleGraphNode{ID: node.ID, Kind: string(node.Kind), Path: node.Path})
+	}
+
+	for _, edge := range g.GetEdges() {
+		mg.Edges = append(mg.Edges, newModuleGraphEdge(edge))
+	}
+
+	r.ModuleGraph = mg
+}
+
+// AddImportCycles adds the import cycles between the modules of the repository
+func (r *Report) AddImportCycles(cycles []*modulegraph.Cycle) {
+	r.ImportCycles = make([]*ImportCycle, 0, len(cycles))
+	for _, cycle := range cycles {
+		ic := &ImportCycle{Modules: cycle.Modules, Deferred: cycle.Deferred,
+			Path: make([]*ModuleGraphEdge, 0, len(cycle.Path))}
+		for _, edge := range cycle.Path {
+			ic.Path = append(ic.Path, newModuleGraphEdge(edge))
+		}
+		r.ImportCycles = append(r.ImportCycles, ic)
+	}
+}
+
+// AddApiUsage adds the symbols of every third-party package used by the code
+func (r *Report) AddApiUsage(usages []*usage.PackageUsage) {
+	r.ApiUsage = make([]*PackageUsage, 0, len(usages))
+	for _, pu := range usages {
+		pkg := &PackageUsage{Package: pu.Package, Symbols: make([]*SymbolUsage, 0, len(pu.Symbols))}
+		for _, su := range pu.Symbols {
+			symbol := &SymbolUsage{Symbol: su.Symbol, Kind: string(su.Kind), Count: su.Count,
+				Locations: make([]*Location, 0, len(su.Locations))}
+			for _, loc := range su.Locations {
+				symbol.Locations = append(symbol.Locations, &Location{Path: loc.Path, Line: loc.Row + 1})
+			}
+			pkg.Symbols = append(pkg.Symbols, symbol)
+		}
+		r.ApiUsage = append(r.ApiUsage, pkg)
+	}
+}
+
+// AddArchitectureViolations adds the imports breaking the architecture contracts
+func (r *Report) AddArchitectureViolations(violations []*architecture.Violation) {
+	for _, v := range violations {
+		r.Violations = append(r.Violations, newViolation("architecture", v.Contract.Name, v.Message, v.Path, v.Node))
+	}
+}
+
+// AddPolicyViolations adds the imports flagged by the rules of the policy
+func (r *Report) AddPolicyViolations(violations []*policy.Violation) {
+	for _, v := range violations {
+		r.Violations = append(r.Violations, newViolation("policy", v.Rule.Name, v.Message, v.Path, v.Node))
+	}
+}
+
+func newModuleGraphEdge(edge *modulegraph.Edge) *ModuleGraphEdge {
+	e := &ModuleGraphEdge{From: edge.From, To: edge.To, Deferred: edge.IsDeferred(),
+		Imports: make([]*Occurrence, 0, len(edge.Imports))}
+	for _, site := range edge.Imports {
+		e.Imports = append(e.Imports, &Occurrence{Path: site.Path,
+			LineStart: site.RowStart + 1,
+			LineEnd:   site.RowEnd + 1,
+			Statement: site.Statement,
+			Region: newRegion(imports.TypedValue{ColumnStart: site.ColumnStart, ColumnEnd: site.ColumnEnd,
+				StartByte: site.StartByte,
+				EndByte:   site.EndByte}),
+			Guards: guardNames(site.Guards)})
+	}
+	return e
+}
+
+func newEntryPoint(entryPoint *reachability.EntryPoint) *EntryPoint {
+	return &EntryPoint{Node: entryPoint.Node,
+		Kind:   string(entryPoint.Kind),
+		Path:   entryPoint.Path,
+		Line:   entryPoint.Row + 1,
+		Reason: entryPoint.Reason}
+}
+
+func newOccurrences(provs []*imports.ImportProvenance) []*Occurrence {
+	occurrences := make([]*Occurrence, 0, len(provs))
+	for _, prov := range provs {
+		occurrences = append(occurrences, &Occurrence{Path: prov.Path,
+			LineStart: prov.RowStart + 1,
+			LineEnd:   prov.RowEnd + 1,
+			Statement: prov.Statement,
+			Region: newRegion(imports.TypedValue{ColumnStart: prov.ColumnStart, ColumnEnd: prov.ColumnEnd,
+				StartByte: prov.StartByte,
+				EndByte:   prov.EndByte}),
+			Guards: guardNames(prov.Guards),
+			Scope:  string(prov.Scope),
+			Unused: prov.Unused})
+	}
+
+	return occurrences
+}
+
+func newModule(mod *imports.ImportedModule) *Module {
+	m := &Module{Name: mod.Name.V,
+		LineStart:      mod.Name.RowStart + 1,
+		LineEnd:        mod.Name.RowEnd + 1,
+		Classification: string(mod.Classification),
+		Dynamic:        mod.Dynamic,
+		Loader:         mod.Loader,
+		Guards:         guardNames(mod.Guards),
+		AbsoluteName:   mod.AbsoluteName,
+		ModulePath:     mod.ModulePath}
+	if mod.Definition != nil {
+		m.Definition = mod.Definition.V
+	}
+	if mod.Alias != nil {
+		m.Alias = mod.Alias.V
+	}
+
+	return m
+}
+
+func newFinding(f *imports.Finding) *Finding {
+	return &Finding{Type: string(f.Type),
+		Message:   f.Message,
+		Path:      f.Path,
+		LineStart: f.Node.RowStart + 1,
+		LineEnd:   f.Node.RowEnd + 1,
+		Region:    newRegion(f.Node)}
+}
+
+func newViolation(check string, rule string, message string, path string, node imports.TypedValue) *Violation {
+	v := &Violation{Check: check, Rule: rule, Message: message, Path: path,
+		LineStart: node.RowStart + 1,
+		LineEnd:   node.RowEnd + 1,
+		Region:    newRegion(node)}
+	// The node is the imported module when the statement is not known
+	if node.T != "dotted_name" && node.T != "relative_import" {
+		v.Statement = node.V
+	}
+	return v
+}
+
+// newRegion returns the region of a node, nil when its position is not known,
+// e.g. for results cached by older versions
+func newRegion(node imports.TypedValue) *Region {
+	if node.EndByte == 0 {
+		return nil
+	}
+	return &Region{ColumnStart: node.ColumnStart + 1,
+		ColumnEnd: node.ColumnEnd + 1,
+		ByteStart: node.StartByte,
+		ByteEnd:   node.EndByte}
+}
+
+func guardNames(guards []imports.ImportGuard) []string {
+	if len(guards) == 0 {
+		return nil
+	}
+
+	names := make([]string, 0, len(guards))
+	for _, guard := range guards {
+		names = append(names, string(guard))
+	}
+	return names
+}
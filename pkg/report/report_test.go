@@ -0,0 +1,338 @@
+package report
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/safedep/codex/pkg/architecture/py/architecture"
+	"github.com/safedep/codex/pkg/callgraph/py/callgraph"
+	"github.com/safedep/codex/pkg/modulegraph/py/modulegraph"
+	"github.com/safedep/codex/pkg/parser/py/imports"
+	"github.com/safedep/codex/pkg/policy/py/policy"
+	"github.com/safedep/codex/pkg/reachability/py/reachability"
+	"github.com/safedep/codex/pkg/usage/py/usage"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+func findImportedModules(t *testing.T, files map[string]string) (*imports.ImportedModules, *imports.ExportedModules) {
+	rootDir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(rootDir, name)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		assert.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+
+	cf := imports.NewPyCodeParserFactory()
+	parser, err := cf.NewCodeParser()
+	assert.NoError(t, err)
+
+	importedModules, err := parser.FindImportedModules(context.Background(), rootDir, false, []string{".py"}, []string{})
+	assert.NoError(t, err)
+
+	exportedModules, err := parser.FindExportedModules(context.Background(), rootDir)
+	assert.NoError(t, err)
+
+	return importedModules, exportedModules
+}
+
+func TestReport(t *testing.T) {
+	importedModules, exportedModules := findImportedModules(t, map[string]string{
+		"mypkg/__init__.py": "",
+		"mypkg/app.py":      "import os\nimport requests\n\ntry:\n    import ujson\nexcept ImportError:\n    ujson = None\n",
+	})
+
+	r := NewReport("find-direct-deps", "project")
+	r.AddImportedModules(importedModules)
+	r.AddExportedModules(exportedModules)
+
+	assert.Equal(t, SCHEMA_VERSION, r.SchemaVersion)
+	assert.Equal(t, 2, len(r.ImportedModules))
+
+	assert.Equal(t, "requests", r.ImportedModules[0].Name)
+	assert.False(t, r.ImportedModules[0].Optional)
+	assert.Equal(t, "runtime", r.ImportedModules[0].Scope)
+	assert.Equal(t, 1, len(r.ImportedModules[0].Occurrences))
+	assert.Equal(t, "mypkg/app.py", r.ImportedModules[0].Occurrences[0].Path)
+	assert.Equal(t, uint32(2), r.ImportedModules[0].Occurrences[0].LineStart)
+	assert.True(t, r.ImportedModules[0].Unused)
+	assert.True(t, r.ImportedModules[0].Occurrences[0].Unused)
+
+	assert.Equal(t, "ujson", r.ImportedModules[1].Name)
+	assert.True(t, r.ImportedModules[1].Optional)
+	assert.Equal(t, []string{"optional"}, r.ImportedModules[1].Occurrences[0].Guards)
+	assert.False(t, r.ImportedModules[1].Unused)
+
+	assert.Equal(t, 1, len(r.ExportedModules))
+	assert.Equal(t, "mypkg", r.ExportedModules[0].Name)
+
+	assert.Equal(t, 2, len(r.Files))
+	assert.Equal(t, "mypkg/__init__.py", r.Files[0].Path)
+	assert.Equal(t, "mypkg/app.py", r.Files[1].Path)
+	assert.Equal(t, 3, len(r.Files[1].Modules))
+	assert.Equal(t, "os", r.Files[1].Modules[0].Name)
+	assert.Equal(t, "stdlib", r.Files[1].Modules[0].Classification)
+}
+
+func TestReportCallGraph(t *testing.T) {
+	rootDir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(rootDir, "app.py"),
+		[]byte("import requests\n\ndef main():\n    requests.get(url)\n\nmain()\n"), 0644))
+
+	parser, err := imports.NewPyCodeParserFactory().NewCodeParser()
+	assert.NoError(t, err)
+	repoSymbols, err := parser.FindSymbols(context.Background(), rootDir, true, []string{".py"}, []string{})
+	assert.NoError(t, err)
+
+	g := callgraph.Build(rootDir, repoSymbols)
+	r := NewReport("callgraph", "project")
+	r.AddCallGraph(g)
+
+	assert.Equal(t, []*CallGraphNode{
+		{ID: "app", Kind: "module", Path: "app.py", Name: "<module>"},
+		{ID: "app.main", Kind: "function", Path: "app.py", Name: "main", Line: 3},
+		{ID: "requests.get", Kind: "external"},
+	}, r.CallGraph.Nodes)
+	assert.Equal(t, []*CallGraphEdge{
+		{Caller: "app", Callee: "app.main", Path: "app.py", Line: 6},
+		{Caller: "app.main", Callee: "requests.get", Path: "app.py", Line: 4},
+	}, r.CallGraph.Edges)
+	assert.Equal(t, 0, r.CallGraph.UnresolvedCalls)
+
+	entryPoints := []*reachability.EntryPoint{{Node: "app", Kind: reachability.ENTRY_POINT_MAIN, Path: "app.py"}}
+	r.AddReachability(entryPoints, reachability.Analyze(g, entryPoints, []string{"requests.get", "yaml.load"}))
+
+	assert.Equal(t, []*EntryPoint{{Node: "app", Kind: "main", Path: "app.py", Line: 1}}, r.Reachability.EntryPoints)
+	assert.Equal(t, []*Reachable{
+		{Symbol: "requests.get", Reachable: true, Match: "call", Node: "requests.get",
+			EntryPoint: &EntryPoint{Node: "app", Kind: "main", Path: "app.py", Line: 1},
+			Path: []*CallStep{
+				{Caller: "app", Callee: "app.main", Path: "app.py", Line: 6, Column: 1,
+					Region: &Region{ColumnStart: 1, ColumnEnd: 7, ByteStart: 52, ByteEnd: 58}},
+				{Caller: "app.main", Callee: "requests.get", Path: "app.py", Line: 4, Column: 5,
+					Region: &Region{ColumnStart: 5, ColumnEnd: 22, ByteStart: 33, ByteEnd: 50}},
+			}},
+		{Symbol: "yaml.load", Reachable: false},
+	}, r.Reachability.Symbols)
+}
+
+func TestReportApiUsage(t *testing.T) {
+	r := NewReport("api-usage", "project")
+	r.AddApiUsage([]*usage.PackageUsage{{Package: "requests", Symbols: []*usage.SymbolUsage{
+		{Symbol: "requests.get", Kind: usage.USAGE_CALL, Count: 1,
+			Locations: []*usage.Location{{Path: "app.py", Row: 3}}},
+	}}})
+
+	assert.Equal(t, []*PackageUsage{{Package: "requests", Symbols: []*SymbolUsage{
+		{Symbol: "requests.get", Kind: "call", Count: 1, Locations: []*Location{{Path: "app.py", Line: 4}}},
+	}}}, r.ApiUsage)
+}
+
+func TestReportModuleGraph(t *testing.T) {
+	g := modulegraph.NewGraph()
+	g.AddNode(&modulegraph.Node{ID: "app", Kind: modulegraph.NODE_MODULE, Path: "app.py"})
+	g.AddNode(&modulegraph.Node{ID: "requests", Kind: modulegraph.NODE_PACKAGE})
+	g.AddImport("app", "requests", &modulegraph.ImportSite{Path: "app.py", RowStart: 2, RowEnd: 2,
+		Statement: "import requests",
+		Guards:    []imports.ImportGuard{imports.IMPORT_GUARD_LAZY}})
+
+	r := NewReport("graph-modules", "project")
+	r.AddModuleGraph(g)
+
+	assert.Equal(t, []*ModuleGraphNode{
+		{ID: "app", Kind: "module", Path: "app.py"},
+		{ID: "requests", Kind: "package"},
+	}, r.ModuleGraph.Nodes)
+	assert.Equal(t, []*ModuleGraphEdge{{From: "app", To: "requests", Deferred: true,
+		Imports: []*Occurrence{{Path: "app.py", LineStart: 3, LineEnd: 3, Statement: "import requests",
+			Guards: []string{"lazy"}}}}}, r.ModuleGraph.Edges)
+}
+
+func TestReportImportCycles(t *testing.T) {
+	g := modulegraph.NewGraph()
+	g.AddNode(&modulegraph.Node{ID: "a", Kind: modulegraph.NODE_MODULE, Path: "a.py"})
+	g.AddNode(&modulegraph.Node{ID: "b", Kind: modulegraph.NODE_MODULE, Path: "b.py"})
+	g.AddImport("a", "b", &modulegraph.ImportSite{Path: "a.py", ColumnEnd: 8, EndByte: 8, Statement: "import b"})
+	g.AddImport("b", "a", &modulegraph.ImportSite{Path: "b.py", RowStart: 4, RowEnd: 4, Statement: "import a"})
+
+	r := NewReport("check-cycles", "project")
+	r.AddImportCycles(modulegraph.FindCycles(g))
+
+	assert.Equal(t, []*ImportCycle{{Modules: []string{"a", "b"}, Deferred: false, Path: []*ModuleGraphEdge{
+		{From: "a", To: "b", Imports: []*Occurrence{{Path: "a.py", LineStart: 1, LineEnd: 1, Statement: "import b",
+			Region: &Region{ColumnStart: 1, ColumnEnd: 9, ByteStart: 0, ByteEnd: 8}}}},
+		{From: "b", To: "a", Imports: []*Occurrence{{Path: "b.py", LineStart: 5, LineEnd: 5, Statement: "import a"}}},
+	}}}, r.ImportCycles)
+}
+
+func TestReportArchitectureViolations(t *testing.T) {
+	contract := &architecture.Contract{Name: "domain", Type: architecture.CONTRACT_FORBIDDEN}
+	r := NewReport("check-architecture", "project")
+	r.AddArchitectureViolations([]*architecture.Violation{{Contract: contract, Importer: "app.domain",
+		Imported: "app.web", Path: "app/domain.py", Node: imports.TypedValue{T: "import_statement", V: "import app.web",
+			RowStart: 2, RowEnd: 2, ColumnStart: 0, ColumnEnd: 14, StartByte: 20, EndByte: 34},
+		Message: "app.domain must not import app.web (domain)"}})
+
+	assert.Equal(t, []*Violation{{Check: "architecture", Rule: "domain",
+		Message: "app.domain must not import app.web (domain)", Path: "app/domain.py",
+		LineStart: 3, LineEnd: 3, Region: &Region{ColumnStart: 1, ColumnEnd: 15, ByteStart: 20, ByteEnd: 34},
+		Statement: "import app.web"}}, r.Violations)
+}
+
+func TestWriteSarif(t *testing.T) {
+	config, err := policy.ParseConfig([]byte("rules:\n  - name: No pickle\n    expression: pkg == 'pickle'\n" +
+		"  - name: no-pickle\n    expression: pkg == 'pickle'\n"))
+	assert.NoError(t, err)
+
+	projectDir := t.TempDir()
+	r := NewReport("check-policy", projectDir)
+	r.AddPolicyViolations([]*policy.Violation{
+		{Rule: config.Rules[0], Module: "pickle", Path: "app.py",
+			Node:    imports.TypedValue{T: "import_statement", V: "import pickle", EndByte: 13, ColumnEnd: 13},
+			Message: "import of pickle is not allowed (No pickle)"},
+		{Rule: config.Rules[1], Module: "pickle", Path: "lib/util.py",
+			Node: imports.TypedValue{T: "dotted_name", V: "pickle", RowStart: 3, RowEnd: 4,
+				ColumnStart: 4, ColumnEnd: 2, StartByte: 40, EndByte: 52},
+			Message: "import of pickle is not allowed (no-pickle)"},
+	})
+
+	var out bytes.Buffer
+	assert.NoError(t, Write(&out, FORMAT_SARIF, r))
+
+	var log map[string]interface{}
+	assert.NoError(t, json.Unmarshal(out.Bytes(), &log))
+	assert.Equal(t, "2.1.0", log["version"])
+
+	run := log["runs"].([]interface{})[0].(map[string]interface{})
+	rules := run["tool"].(map[string]interface{})["driver"].(map[string]interface{})["rules"].([]interface{})
+	assert.Len(t, rules, 2)
+	assert.Equal(t, "policy/no-pickle", rules[0].(map[string]interface{})["id"])
+	assert.Equal(t, "policy/no-pickle-2", rules[1].(map[string]interface{})["id"])
+	assert.Equal(t, "no-pickle", rules[1].(map[string]interface{})["name"])
+
+	srcRoot := run["originalUriBaseIds"].(map[string]interface{})["%SRCROOT%"].(map[string]interface{})
+	assert.Equal(t, "file://"+filepath.ToSlash(projectDir)+"/", srcRoot["uri"])
+
+	results := run["results"].([]interface{})
+	assert.Len(t, results, 2)
+	assert.Equal(t, "policy/no-pickle-2", results[1].(map[string]interface{})["ruleId"])
+	assert.Equal(t, 1.0, results[1].(map[string]interface{})["ruleIndex"])
+	location := results[1].(map[string]interface{})["locations"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{
+		"artifactLocation": map[string]interface{}{"uri": "lib/util.py", "uriBaseId": "%SRCROOT%"},
+		"region": map[string]interface{}{"startLine": 4.0, "endLine": 5.0,
+			"startColumn": 5.0, "endColumn": 3.0, "byteOffset": 40.0, "byteLength": 12.0},
+	}, location["physicalLocation"])
+}
+
+func TestWriteSarifResults(t *testing.T) {
+	importedModules, _ := findImportedModules(t, map[string]string{
+		"app.py": "import json\nimport yaml\nyaml.safe_load('')\n",
+	})
+
+	r := NewReport("find-direct-deps", "project")
+	r.AddImportedModules(importedModules)
+	r.ManifestDrift = &ManifestDrift{Undeclared: []*UndeclaredDependency{{ImportName: "yaml",
+		Distribution: "PyYAML",
+		Occurrences:  r.ImportedModules[0].Occurrences}}}
+	r.Reachability = &Reachability{Symbols: []*Reachable{
+		{Symbol: "yaml.load", Reachable: true, Path: []*CallStep{
+			{Caller: "app", Callee: "app.main", Path: "app.py", Line: 5, Column: 1},
+			{Caller: "app.main", Callee: "yaml.load", Path: "app.py", Line: 7, Column: 5,
+				Region: &Region{ColumnStart: 5, ColumnEnd: 19, ByteStart: 60, ByteEnd: 74}}}},
+		{Symbol: "yaml.unsafe_load", Reachable: false},
+	}}
+	r.ImportCycles = []*ImportCycle{{Modules: []string{"a", "b"}, Deferred: true, Path: []*ModuleGraphEdge{
+		{From: "a", To: "b", Imports: []*Occurrence{{Path: "a.py", LineStart: 1, LineEnd: 1, Statement: "import b",
+			Region: &Region{ColumnStart: 1, ColumnEnd: 9, ByteStart: 0, ByteEnd: 8}}}},
+	}}}
+
+	var out bytes.Buffer
+	assert.NoError(t, Write(&out, FORMAT_SARIF, r))
+
+	var log map[string]interface{}
+	assert.NoError(t, json.Unmarshal(out.Bytes(), &log))
+	run := log["runs"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "unicodeCodePoints", run["columnKind"])
+
+	results := run["results"].([]interface{})
+	ids := make([]string, 0, len(results))
+	for _, result := range results {
+		ids = append(ids, result.(map[string]interface{})["ruleId"].(string))
+	}
+	assert.Equal(t, []string{"finding/unused-import", "drift/undeclared-dependency",
+		"reachability/yaml-load", "cycles/import-cycle"}, ids)
+
+	unused := results[0].(map[string]interface{})
+	assert.Equal(t, "warning", unused["level"])
+	location := unused["locations"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"startLine": 1.0, "endLine": 1.0,
+		"startColumn": 8.0, "endColumn": 12.0, "byteOffset": 7.0, "byteLength": 4.0,
+	}, location["physicalLocation"].(map[string]interface{})["region"])
+
+	reachable := results[2].(map[string]interface{})
+	location = reachable["locations"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"startLine": 7.0, "endLine": 7.0,
+		"startColumn": 5.0, "endColumn": 19.0, "byteOffset": 60.0, "byteLength": 14.0,
+	}, location["physicalLocation"].(map[string]interface{})["region"])
+
+	cycle := results[3].(map[string]interface{})
+	assert.Equal(t, "note", cycle["level"])
+	location = cycle["locations"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"startLine": 1.0, "endLine": 1.0,
+		"startColumn": 1.0, "endColumn": 9.0, "byteOffset": 0.0, "byteLength": 8.0,
+		"snippet": map[string]interface{}{"text": "import b"},
+	}, location["physicalLocation"].(map[string]interface{})["region"])
+}
+
+func TestWrite(t *testing.T) {
+	importedModules, exportedModules := findImportedModules(t, map[string]string{
+		"mypkg/__init__.py": "import requests\nimport yaml\n",
+	})
+
+	r := NewReport("find-direct-deps", "project")
+	r.AddImportedModules(importedModules)
+	r.AddExportedModules(exportedModules)
+
+	var out bytes.Buffer
+	assert.NoError(t, Write(&out, FORMAT_JSON, r))
+
+	var decoded Report
+	assert.NoError(t, json.Unmarshal(out.Bytes(), &decoded))
+	assert.Equal(t, r, &decoded)
+
+	out.Reset()
+	assert.NoError(t, Write(&out, FORMAT_YAML, r))
+
+	decoded = Report{}
+	assert.NoError(t, yaml.Unmarshal(out.Bytes(), &decoded))
+	assert.Equal(t, r, &decoded)
+
+	out.Reset()
+	assert.NoError(t, Write(&out, FORMAT_JSONL, r))
+
+	kinds := []string{}
+	scanner := bufio.NewScanner(&out)
+	for scanner.Scan() {
+		var record map[string]interface{}
+		assert.NoError(t, json.Unmarshal(scanner.Bytes(), &record))
+		kinds = append(kinds, record["kind"].(string))
+	}
+	assert.Equal(t, []string{"report", "imported_module", "imported_module", "exported_module", "file"}, kinds)
+
+	assert.Error(t, Write(&out, FORMAT_TEXT, r))
+}
+
+func TestParseFormat(t *testing.T) {
+	format, err := ParseFormat("jsonl")
+	assert.NoError(t, err)
+	assert.Equal(t, FORMAT_JSONL, format)
+
+	_, err = ParseFormat("xml")
+	assert.Error(t, err)
+}
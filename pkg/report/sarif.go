@@ -0,0 +1,284 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+const (
+	SARIF_VERSION = "2.1.0"
+	SARIF_SCHEMA  = "https://json.schemastore.org/sarif-2.1.0.json"
+
+	// Name of the tool in the runs of the SARIF logs
+	SARIF_TOOL_NAME = "codex"
+	SARIF_TOOL_URI  = "https://github.com/safedep/codex"
+
+	// Base of the paths of the results, the project directory
+	SARIF_SRCROOT = "%SRCROOT%"
+)
+
+// sarifLog is the root of a SARIF 2.1.0 log, with the properties read by code
+// scanning tools only
+type sarifLog struct {
+	Schema  string      `json:"$schema"`
+	Version string      `json:"version"`
+	Runs    []*sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool               sarifTool                        `json:"tool"`
+	OriginalUriBaseIds map[string]sarifArtifactLocation `json:"originalUriBaseIds,omitempty"`
+	ColumnKind         string                           `json:"columnKind"`
+	Results            []*sarifResult                   `json:"results"`
+
+	ruleIndexes map[string]int  // indexes of the rules by check and name
+	ruleIds     map[string]bool // ids of the rules, unique in the run
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string       `json:"name"`
+	InformationUri string       `json:"informationUri"`
+	Rules          []*sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	Name             string       `json:"name"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string           `json:"ruleId"`
+	RuleIndex int              `json:"ruleIndex"`
+	Level     string           `json:"level"`
+	Message   sarifMessage     `json:"message"`
+	Locations []*sarifLocation `json:"locations"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           sarifRegion           `json:"region"`
+}
+
+type sarifArtifactLocation struct {
+	URI       string `json:"uri"`
+	UriBaseId string `json:"uriBaseId,omitempty"`
+}
+
+type sarifRegion struct {
+	StartLine   uint32        `json:"startLine"`
+	EndLine     uint32        `json:"endLine"`
+	StartColumn uint32        `json:"startColumn,omitempty"`
+	EndColumn   uint32        `json:"endColumn,omitempty"`
+	ByteOffset  *uint32       `json:"byteOffset,omitempty"`
+	ByteLength  *uint32       `json:"byteLength,omitempty"`
+	Snippet     *sarifMessage `json:"snippet,omitempty"`
+}
+
+// Rules of the results which are not violations of a configured rule
+const (
+	SARIF_RULE_UNDECLARED_DEPENDENCY = "drift/undeclared-dependency"
+	SARIF_RULE_IMPORT_CYCLE          = "cycles/import-cycle"
+)
+
+// writeSarif writes the violations, the findings, the undeclared dependencies,
+// the reachable symbols and the import cycles of the report as the results of a
+// SARIF log. Rules are identified by their check and the slug of their name, e.g.
+// policy/no-pickle-outside-of-the-tests or finding/unused-import, with a suffix when
+// the names of two rules have the same slug. Columns count unicode code points and
+// are 1 based, the end column is after the last character. Paths are relative to
+// %SRCROOT%, the project directory.
+func writeSarif(w io.Writer, r *Report) error {
+	run := &sarifRun{Tool: sarifTool{Driver: sarifDriver{Name: SARIF_TOOL_NAME,
+		InformationUri: SARIF_TOOL_URI,
+		Rules:          make([]*sarifRule, 0)}},
+		ColumnKind:  "unicodeCodePoints",
+		Results:     make([]*sarifResult, 0),
+		ruleIndexes: make(map[string]int, 0),
+		ruleIds:     make(map[string]bool, 0)}
+	if srcRoot, ok := srcRootOf(r.Input); ok {
+		run.OriginalUriBaseIds = map[string]sarifArtifactLocation{SARIF_SRCROOT: {URI: srcRoot}}
+	}
+
+	for _, v := range r.Violations {
+		run.addResult(v.Check+"/"+slugOf(v.Rule), v.Rule, "error", v.Message,
+			v.Path, newSarifRegion(v.LineStart, v.LineEnd, v.Region, v.Statement))
+	}
+
+	for _, f := range findingsOf(r) {
+		level := "warning"
+		if strings.HasPrefix(f.Type, "unresolved-") {
+			level = "note"
+		}
+		run.addResult("finding/"+slugOf(f.Type), f.Type, level, f.Message,
+			f.Path, newSarifRegion(f.LineStart, f.LineEnd, f.Region, ""))
+	}
+
+	if r.ManifestDrift != nil {
+		for _, dep := range r.ManifestDrift.Undeclared {
+			message := fmt.Sprintf("%s is imported but %s is not declared in the manifests",
+				dep.ImportName, dep.Distribution)
+			for _, o := range dep.Occurrences {
+				run.addResult(SARIF_RULE_UNDECLARED_DEPENDENCY, "Undeclared dependency", "error", message,
+					o.Path, newSarifRegion(o.LineStart, o.LineEnd, o.Region, o.Statement))
+			}
+		}
+	}
+
+	if r.Reachability != nil {
+		for _, symbol := range r.Reachability.Symbols {
+			if !symbol.Reachable || len(symbol.Path) == 0 {
+				continue
+			}
+
+			// The last call of the path is the use of the symbol
+			step := symbol.Path[len(symbol.Path)-1]
+			region := newSarifRegion(step.Line, step.Line, step.Region, "")
+			if step.Region == nil {
+				region.StartColumn = step.Column
+			}
+			message := fmt.Sprintf("%s is reachable from %s", symbol.Symbol, symbol.Path[0].Caller)
+			run.addResult("reachability/"+slugOf(symbol.Symbol), symbol.Symbol, "error", message,
+				step.Path, region)
+		}
+	}
+
+	for _, cycle := range r.ImportCycles {
+		if len(cycle.Path) == 0 || len(cycle.Path[0].Imports) == 0 {
+			continue
+		}
+
+		// The cycle is located at the first import of the path
+		o := cycle.Path[0].Imports[0]
+		level := "error"
+		if cycle.Deferred {
+			level = "note"
+		}
+		message := "import cycle " + strings.Join(append(append([]string{}, cycle.Modules...), cycle.Modules[0]), " -> ")
+		run.addResult(SARIF_RULE_IMPORT_CYCLE, "Import cycle", level, message,
+			o.Path, newSarifRegion(o.LineStart, o.LineEnd, o.Region, o.Statement))
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(&sarifLog{Schema: SARIF_SCHEMA, Version: SARIF_VERSION, Runs: []*sarifRun{run}})
+}
+
+// addResult adds a result located in a file, and its rule the first time it is used
+func (run *sarifRun) addResult(id string, name string, level string, message string, path string, region sarifRegion) {
+	index := run.ruleIndexOf(id, name)
+	run.Results = append(run.Results, &sarifResult{RuleID: run.Tool.Driver.Rules[index].ID, RuleIndex: index,
+		Level:   level,
+		Message: sarifMessage{Text: message},
+		Locations: []*sarifLocation{{PhysicalLocation: sarifPhysicalLocation{
+			ArtifactLocation: sarifArtifactLocation{URI: filepath.ToSlash(path), UriBaseId: SARIF_SRCROOT},
+			Region:           region}}}})
+}
+
+// ruleIndexOf returns the index of the rule, added the first time it is used. Rules
+// whose ids collide, e.g. "No pickle" and "no-pickle", get a numbered suffix.
+func (run *sarifRun) ruleIndexOf(id string, name string) int {
+	key := id + "\x00" + name
+	if index, ok := run.ruleIndexes[key]; ok {
+		return index
+	}
+
+	unique := id
+	for n := 2; run.ruleIds[unique]; n++ {
+		unique = fmt.Sprintf("%s-%d", id, n)
+	}
+	run.ruleIds[unique] = true
+
+	index := len(run.Tool.Driver.Rules)
+	run.ruleIndexes[key] = index
+	run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, &sarifRule{ID: unique, Name: name,
+		ShortDescription: sarifMessage{Text: name}})
+	return index
+}
+
+// srcRootOf returns the file URI of the directory the paths of the results are
+// relative to: the project directory, or the working directory when a single
+// file is analyzed.
+func srcRootOf(input string) (string, bool) {
+	root := input
+	if info, err := os.Stat(input); err != nil || !info.IsDir() {
+		root = "."
+	}
+
+	abs, err := filepath.Abs(root)
+	if err != nil {
+		return "", false
+	}
+
+	// Base URIs must end with a slash
+	uri := (&url.URL{Scheme: "file", Path: filepath.ToSlash(abs)}).String()
+	if !strings.HasSuffix(uri, "/") {
+		uri += "/"
+	}
+	return uri, true
+}
+
+func newSarifRegion(lineStart uint32, lineEnd uint32, r *Region, snippet string) sarifRegion {
+	region := sarifRegion{StartLine: lineStart, EndLine: lineEnd}
+	if r != nil {
+		length := r.ByteEnd - r.ByteStart
+		region.StartColumn = r.ColumnStart
+		region.EndColumn = r.ColumnEnd
+		region.ByteOffset = &r.ByteStart
+		region.ByteLength = &length
+	}
+	if snippet != "" {
+		region.Snippet = &sarifMessage{Text: snippet}
+	}
+	return region
+}
+
+// findingsOf returns the findings of the report and of its files, once each
+func findingsOf(r *Report) []*Finding {
+	findings := make([]*Finding, 0, len(r.Findings))
+	seen := make(map[string]bool, 0)
+	add := func(f *Finding) {
+		key := fmt.Sprintf("%s|%s|%d|%s", f.Type, f.Path, f.LineStart, f.Message)
+		if !seen[key] {
+			seen[key] = true
+			findings = append(findings, f)
+		}
+	}
+
+	for _, f := range r.Findings {
+		add(f)
+	}
+	for _, file := range r.Files {
+		for _, f := range file.Findings {
+			add(f)
+		}
+	}
+	return findings
+}
+
+// slugOf lowercases the name and replaces every run of other characters than
+// letters and digits with a dash
+func slugOf(name string) string {
+	words := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return strings.Join(words, "-")
+}
@@ -0,0 +1,154 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"gopkg.in/yaml.v3"
+)
+
+type Format string
+
+const (
+	FORMAT_TEXT  Format = "text"
+	FORMAT_JSON  Format = "json"
+	FORMAT_JSONL Format = "jsonl"
+	FORMAT_YAML  Format = "yaml"
+
+	// Static analysis results interchange format, written by the check commands
+	FORMAT_SARIF Format = "sarif"
+
+	// Package manifest of safedep/vet, only written by the commands finding imported packages
+	FORMAT_VET Format = "vet"
+
+	// Graph description languages, only written by the graph commands
+	FORMAT_DOT     Format = "dot"
+	FORMAT_GRAPHML Format = "graphml"
+	FORMAT_MERMAID Format = "mermaid"
+)
+
+// SupportedFormats returns the output formats of the scan commands
+func SupportedFormats() []Format {
+	return []Format{FORMAT_TEXT, FORMAT_JSON, FORMAT_JSONL, FORMAT_YAML, FORMAT_SARIF, FORMAT_VET,
+		FORMAT_DOT, FORMAT_GRAPHML, FORMAT_MERMAID}
+}
+
+// GraphFormats returns the output formats of the graph commands besides the report formats
+func GraphFormats() []Format {
+	return []Format{FORMAT_DOT, FORMAT_GRAPHML, FORMAT_MERMAID}
+}
+
+// ParseFormat validates the name of an output format
+func ParseFormat(name string) (Format, error) {
+	for _, format := range SupportedFormats() {
+		if string(format) == name {
+			return format, nil
+		}
+	}
+
+	return "", fmt.Errorf("unsupported output format %q", name)
+}
+
+// jsonlRecord is a line of the jsonl output. The first record is of kind
+// report and carries the schema version, every other record is one item
+// of a report section.
+type jsonlRecord struct {
+	Kind          string      `json:"kind"`
+	SchemaVersion string      `json:"schema_version,omitempty"`
+	Command       string      `json:"command,omitempty"`
+	Input         string      `json:"input,omitempty"`
+	Data          interface{} `json:"data,omitempty"`
+}
+
+// Write writes the report in a machine readable format. The text, vet and graph
+// formats are specific to the commands and are not written by the report.
+func Write(w io.Writer, format Format, r *Report) error {
+	switch format {
+	case FORMAT_JSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(r)
+	case FORMAT_YAML:
+		encoder := yaml.NewEncoder(w)
+		defer encoder.Close()
+		return encoder.Encode(r)
+	case FORMAT_JSONL:
+		return writeJsonl(w, r)
+	case FORMAT_SARIF:
+		return writeSarif(w, r)
+	}
+
+	return fmt.Errorf("report can not be written as %q", format)
+}
+
+func writeJsonl(w io.Writer, r *Report) error {
+	encoder := json.NewEncoder(w)
+	records := []jsonlRecord{{Kind: "report", SchemaVersion: r.SchemaVersion,
+		Command: r.Command, Input: r.Input}}
+
+	for _, item := range r.ImportedModules {
+		records = append(records, jsonlRecord{Kind: "imported_module", Data: item})
+	}
+	for _, item := range r.ExportedModules {
+		records = append(records, jsonlRecord{Kind: "exported_module", Data: item})
+	}
+	for _, item := range r.Files {
+		records = append(records, jsonlRecord{Kind: "file", Data: item})
+	}
+	if r.ManifestDrift != nil {
+		for _, item := range r.ManifestDrift.Unused {
+			records = append(records, jsonlRecord{Kind: "unused_dependency", Data: item})
+		}
+		for _, item := range r.ManifestDrift.Undeclared {
+			records = append(records, jsonlRecord{Kind: "undeclared_dependency", Data: item})
+		}
+	}
+	if r.CallGraph != nil {
+		for _, item := range r.CallGraph.Nodes {
+			records = append(records, jsonlRecord{Kind: "call_graph_node", Data: item})
+		}
+		for _, item := range r.CallGraph.Edges {
+			records = append(records, jsonlRecord{Kind: "call_graph_edge", Data: item})
+		}
+	}
+	if r.Reachability != nil {
+		for _, item := range r.Reachability.EntryPoints {
+			records = append(records, jsonlRecord{Kind: "entry_point", Data: item})
+		}
+		for _, item := range r.Reachability.Symbols {
+			records = append(records, jsonlRecord{Kind: "reachable_symbol", Data: item})
+		}
+	}
+	if r.ModuleGraph != nil {
+		for _, item := range r.ModuleGraph.Nodes {
+			records = append(records, jsonlRecord{Kind: "module_graph_node", Data: item})
+		}
+		for _, item := range r.ModuleGraph.Edges {
+			records = append(records, jsonlRecord{Kind: "module_graph_edge", Data: item})
+		}
+	}
+	for _, item := range r.ImportCycles {
+		records = append(records, jsonlRecord{Kind: "import_cycle", Data: item})
+	}
+	for _, item := range r.Violations {
+		records = append(records, jsonlRecord{Kind: "violation", Data: item})
+	}
+	for _, item := range r.ApiUsage {
+		records = append(records, jsonlRecord{Kind: "package_usage", Data: item})
+	}
+	for _, item := range r.Findings {
+		records = append(records, jsonlRecord{Kind: "finding", Data: item})
+	}
+	for _, item := range r.Errors {
+		records = append(records, jsonlRecord{Kind: "error", Data: item})
+	}
+
+	for _, record := range records {
+		if err := encoder.Encode(record); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
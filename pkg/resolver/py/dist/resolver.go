@@ -0,0 +1,235 @@
+/*
+	Resolve Python import names to the PyPI distributions providing them
+*/
+
+package dist
+
+import (
+	"bufio"
+	_ "embed"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/safedep/codex/pkg/utils/py/dir"
+	"github.com/safedep/dry/log"
+)
+
+//go:embed data/import_to_dist.txt
+var bundledMapping string
+
+type Confidence string
+
+const (
+	CONFIDENCE_HIGH   Confidence = "high"
+	CONFIDENCE_MEDIUM Confidence = "medium"
+	CONFIDENCE_LOW    Confidence = "low"
+)
+
+type Source string
+
+const (
+	SOURCE_OVERRIDE      Source = "override"
+	SOURCE_SITE_PACKAGES Source = "site-packages"
+	SOURCE_MAPPING       Source = "mapping"
+	SOURCE_IMPORT_NAME   Source = "import-name"
+)
+
+// Candidate is a distribution which may provide an import name
+type Candidate struct {
+	Distribution string
+	Version      string // only known for installed distributions
+	Source       Source
+	Confidence   Confidence
+}
+
+// Resolution holds the candidate distributions of an import name, best first
+type Resolution struct {
+	ImportName string
+	Candidates []*Candidate
+}
+
+// Best returns the most likely distribution for the import name
+func (r *Resolution) Best() *Candidate {
+	if len(r.Candidates) == 0 {
+		return nil
+	}
+	return r.Candidates[0]
+}
+
+// IsAmbiguous returns true when more than one distribution may provide the import name
+func (r *Resolution) IsAmbiguous() bool {
+	return len(r.Candidates) > 1
+}
+
+type ResolverConfig struct {
+	// site-packages directories of the environment the project is installed in
+	SitePackagesDirs []string
+
+	// Files with user overrides in the same format as the bundled mapping
+	OverrideFiles []string
+
+	// User overrides of import name to distribution names
+	Overrides map[string][]string
+}
+
+// Resolver maps import names to distribution names. Sources are consulted
+// in order: user overrides, site-packages metadata, bundled mapping table.
+type Resolver struct {
+	overrides map[string][]string
+	installed map[string][]*dir.InstalledDistribution
+	mapping   map[string][]string
+}
+
+func NewResolver(config ResolverConfig) (*Resolver, error) {
+	mapping, err := parseMapping(strings.NewReader(bundledMapping))
+	if err != nil {
+		return nil, fmt.Errorf("error while reading bundled mapping: %w", err)
+	}
+
+	overrides := make(map[string][]string, 0)
+	for _, overrideFile := range config.OverrideFiles {
+		file, err := os.Open(overrideFile)
+		if err != nil {
+			return nil, err
+		}
+
+		fileOverrides, err := parseMapping(file)
+		file.Close()
+		if err != nil {
+			return nil, fmt.Errorf("error while reading overrides %s: %w", overrideFile, err)
+		}
+		for name, dists := range fileOverrides {
+			overrides[name] = dists
+		}
+	}
+
+	for name, dists := range config.Overrides {
+		overrides[name] = dists
+	}
+
+	installed := make(map[string][]*dir.InstalledDistribution, 0)
+	for _, siteDir := range config.SitePackagesDirs {
+		dists, err := dir.FindInstalledDistributions(siteDir)
+		if err != nil {
+			return nil, fmt.Errorf("error while reading site-packages %s: %w", siteDir, err)
+		}
+
+		log.Debugf("Found %d installed distributions in %s", len(dists), siteDir)
+		for _, d := range dists {
+			for _, mod := range d.Modules {
+				installed[mod] = append(installed[mod], d)
+			}
+		}
+	}
+
+	return &Resolver{overrides: overrides, installed: installed, mapping: mapping}, nil
+}
+
+// Resolve returns the candidate distributions for a top level import name.
+// Dotted names are resolved by their top level package.
+func (r *Resolver) Resolve(importName string) *Resolution {
+	name := dir.SplitAndGetLeftMost(importName, ".")
+	res := &Resolution{ImportName: name}
+
+	if dists, ok := r.overrides[name]; ok {
+		res.Candidates = makeCandidates(dists, SOURCE_OVERRIDE, CONFIDENCE_HIGH)
+		return res
+	}
+
+	if installed, ok := r.installed[name]; ok {
+		confidence := CONFIDENCE_HIGH
+		if len(installed) > 1 {
+			// Namespace packages, e.g. google, are provided by many distributions
+			confidence = CONFIDENCE_MEDIUM
+		}
+		for _, d := range installed {
+			res.Candidates = append(res.Candidates, &Candidate{Distribution: d.Name,
+				Version:    d.Version,
+				Source:     SOURCE_SITE_PACKAGES,
+				Confidence: confidence})
+		}
+		return res
+	}
+
+	if dists, ok := r.mapping[name]; ok {
+		confidence := CONFIDENCE_MEDIUM
+		if len(dists) > 1 {
+			confidence = CONFIDENCE_LOW
+		}
+		res.Candidates = makeCandidates(dists, SOURCE_MAPPING, confidence)
+		return res
+	}
+
+	// Most distributions are published under the name they are imported with
+	res.Candidates = makeCandidates([]string{name}, SOURCE_IMPORT_NAME, CONFIDENCE_LOW)
+	return res
+}
+
+// ResolveAll resolves a list of import names, keyed by top level import name
+func (r *Resolver) ResolveAll(importNames []string) map[string]*Resolution {
+	resolutions := make(map[string]*Resolution, len(importNames))
+	for _, importName := range importNames {
+		res := r.Resolve(importName)
+		resolutions[res.ImportName] = res
+	}
+
+	return resolutions
+}
+
+// FindSitePackagesDirs finds site-packages directories of a virtual environment
+func FindSitePackagesDirs(venvDir string) ([]string, error) {
+	patterns := []string{
+		filepath.Join(venvDir, "lib", "python*", "site-packages"),
+		filepath.Join(venvDir, "lib64", "python*", "site-packages"),
+		filepath.Join(venvDir, "Lib", "site-packages"),
+	}
+
+	dirs := make([]string, 0)
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, err
+		}
+		dirs = append(dirs, matches...)
+	}
+
+	sort.Strings(dirs)
+	return dirs, nil
+}
+
+func makeCandidates(dists []string, source Source, confidence Confidence) []*Candidate {
+	candidates := make([]*Candidate, 0, len(dists))
+	for _, d := range dists {
+		candidates = append(candidates, &Candidate{Distribution: d,
+			Source: source, Confidence: confidence})
+	}
+
+	return candidates
+}
+
+// parseMapping reads lines of "<import name> <distribution> [<distribution> ...]"
+func parseMapping(reader io.Reader) (map[string][]string, error) {
+	mapping := make(map[string][]string, 0)
+
+	scanner := bufio.NewScanner(reader)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber += 1
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			return nil, fmt.Errorf("line %d: expected import name and distribution", lineNumber)
+		}
+		mapping[fields[0]] = fields[1:]
+	}
+
+	return mapping, scanner.Err()
+}
@@ -0,0 +1,87 @@
+package dist
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func createDistInfo(t *testing.T, siteDir, dirName string, files map[string]string) {
+	distDir := filepath.Join(siteDir, dirName)
+	assert.NoError(t, os.MkdirAll(distDir, os.ModePerm))
+	for name, content := range files {
+		assert.NoError(t, os.WriteFile(filepath.Join(distDir, name), []byte(content), 0644))
+	}
+}
+
+func TestResolveBundledMapping(t *testing.T) {
+	resolver, err := NewResolver(ResolverConfig{})
+	assert.NoError(t, err)
+
+	tests := []struct {
+		importName   string
+		distribution string
+		confidence   Confidence
+		source       Source
+		ambiguous    bool
+	}{
+		{"yaml", "PyYAML", CONFIDENCE_MEDIUM, SOURCE_MAPPING, false},
+		{"sklearn.metrics", "scikit-learn", CONFIDENCE_MEDIUM, SOURCE_MAPPING, false},
+		{"bs4", "beautifulsoup4", CONFIDENCE_MEDIUM, SOURCE_MAPPING, false},
+		{"cv2", "opencv-python", CONFIDENCE_LOW, SOURCE_MAPPING, true},
+		{"requests", "requests", CONFIDENCE_LOW, SOURCE_IMPORT_NAME, false},
+	}
+
+	for _, test := range tests {
+		res := resolver.Resolve(test.importName)
+		assert.Equal(t, test.distribution, res.Best().Distribution, test.importName)
+		assert.Equal(t, test.confidence, res.Best().Confidence, test.importName)
+		assert.Equal(t, test.source, res.Best().Source, test.importName)
+		assert.Equal(t, test.ambiguous, res.IsAmbiguous(), test.importName)
+	}
+}
+
+func TestResolveSitePackagesAndOverrides(t *testing.T) {
+	siteDir := t.TempDir()
+	createDistInfo(t, siteDir, "PyYAML-6.0.1.dist-info", map[string]string{
+		"METADATA":      "Metadata-Version: 2.1\nName: PyYAML\nVersion: 6.0.1\n\nYAML parser",
+		"top_level.txt": "_yaml\nyaml\n",
+	})
+	createDistInfo(t, siteDir, "attrs-23.1.0.dist-info", map[string]string{
+		"RECORD": "attr/__init__.py,sha256=abc,100\nattrs/__init__.py,sha256=abc,100\n" +
+			"attrs-23.1.0.dist-info/RECORD,,\n../../bin/attrs,,\n",
+	})
+
+	overrides := filepath.Join(t.TempDir(), "overrides.txt")
+	assert.NoError(t, os.WriteFile(overrides, []byte("# team overrides\nmyorg_lib myorg-internal-lib\n"), 0644))
+
+	resolver, err := NewResolver(ResolverConfig{SitePackagesDirs: []string{siteDir},
+		OverrideFiles: []string{overrides},
+		Overrides:     map[string][]string{"jwt": {"PyJWT"}}})
+	assert.NoError(t, err)
+
+	res := resolver.Resolve("yaml")
+	assert.Equal(t, "PyYAML", res.Best().Distribution)
+	assert.Equal(t, "6.0.1", res.Best().Version)
+	assert.Equal(t, SOURCE_SITE_PACKAGES, res.Best().Source)
+	assert.Equal(t, CONFIDENCE_HIGH, res.Best().Confidence)
+
+	res = resolver.Resolve("attrs")
+	assert.Equal(t, "attrs", res.Best().Distribution)
+	assert.Equal(t, SOURCE_SITE_PACKAGES, res.Best().Source)
+
+	res = resolver.Resolve("jwt")
+	assert.False(t, res.IsAmbiguous())
+	assert.Equal(t, "PyJWT", res.Best().Distribution)
+	assert.Equal(t, SOURCE_OVERRIDE, res.Best().Source)
+
+	res = resolver.Resolve("myorg_lib.client")
+	assert.Equal(t, "myorg_lib", res.ImportName)
+	assert.Equal(t, "myorg-internal-lib", res.Best().Distribution)
+
+	resolutions := resolver.ResolveAll([]string{"yaml", "attr", "bs4"})
+	assert.Equal(t, 3, len(resolutions))
+	assert.Equal(t, "attrs", resolutions["attr"].Best().Distribution)
+}
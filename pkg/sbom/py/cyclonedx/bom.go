@@ -0,0 +1,191 @@
+/*
+	Generate a CycloneDX SBOM from the packages imported in the code
+*/
+
+package cyclonedx
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/safedep/codex/pkg/manifest/py/manifest"
+	"github.com/safedep/codex/pkg/parser/py/imports"
+	"github.com/safedep/codex/pkg/resolver/py/dist"
+)
+
+const (
+	TOOL_NAME = "codex"
+
+	// Identity technique of components found by parsing the imports
+	IDENTITY_TECHNIQUE = "source-code-analysis"
+
+	PROPERTY_IMPORT_NAME = "codex:import_name"
+	PROPERTY_SCOPE       = "codex:scope"
+)
+
+// Confidence of the identity of a component resolved from an import name
+var identityConfidence = map[dist.Confidence]float64{
+	dist.CONFIDENCE_HIGH:   1.0,
+	dist.CONFIDENCE_MEDIUM: 0.7,
+	dist.CONFIDENCE_LOW:    0.3,
+}
+
+type BomConfig struct {
+	// Name of the root component when the code exports no or more than one module
+	RootName string
+
+	// Resolver of import names to distributions
+	Resolver *dist.Resolver
+}
+
+// NewBom creates a CycloneDX document with a component for every imported
+// third-party package, and the exported modules as the root component
+func NewBom(importedModules *imports.ImportedModules, exportedModules *imports.ExportedModules,
+	config BomConfig) (*Bom, error) {
+	serialNumber, err := newSerialNumber()
+	if err != nil {
+		return nil, err
+	}
+
+	root := newRootComponent(exportedModules, config.RootName)
+	bom := &Bom{BomFormat: CYCLONEDX_BOM_FORMAT,
+		SpecVersion:  CYCLONEDX_SPEC_VERSION,
+		SerialNumber: serialNumber,
+		Version:      1,
+		Metadata: &Metadata{Timestamp: time.Now().UTC().Format(time.RFC3339),
+			Tools: &Tools{Components: []*Component{{Type: COMPONENT_TYPE_APPLICATION,
+				Name: TOOL_NAME}}},
+			Component: root},
+		Components: make([]*Component, 0)}
+
+	optional := make(map[string]bool, 0)
+	for _, pkg := range importedModules.GetOptionalDependencies() {
+		optional[pkg] = true
+	}
+
+	importNames := importedModules.GetPackagesNames()
+	sort.Strings(importNames)
+
+	// Import names provided by the same distribution share a component
+	components := make(map[string]*Component, 0)
+	scopes := make(map[string]imports.DependencyScope, 0)
+	for _, importName := range importNames {
+		res := config.Resolver.Resolve(importName)
+		candidate := res.Best()
+		if candidate == nil {
+			candidate = &dist.Candidate{Distribution: importName, Source: dist.SOURCE_IMPORT_NAME,
+				Confidence: dist.CONFIDENCE_LOW}
+		}
+
+		purl := newPurl(candidate.Distribution, candidate.Version)
+		component, ok := components[purl]
+		if !ok {
+			component = &Component{Type: COMPONENT_TYPE_LIBRARY,
+				BomRef:   purl,
+				Name:     manifest.NormalizeName(candidate.Distribution),
+				Version:  candidate.Version,
+				Scope:    COMPONENT_SCOPE_OPTIONAL,
+				Purl:     purl,
+				Evidence: &Evidence{Identity: &Identity{Field: "purl"}, Occurrences: make([]*Occurrence, 0)}}
+			components[purl] = component
+			bom.Components = append(bom.Components, component)
+		}
+
+		// A component is required when any of its import names is a hard dependency
+		if !optional[importName] {
+			component.Scope = COMPONENT_SCOPE_REQUIRED
+		}
+
+		scope := importedModules.GetScope(importName)
+		if current, ok := scopes[purl]; ok {
+			scope = imports.MoreImportantScope(current, scope)
+		}
+		scopes[purl] = scope
+
+		confidence := identityConfidence[candidate.Confidence]
+		component.Evidence.Identity.Confidence = max(component.Evidence.Identity.Confidence, confidence)
+		component.Evidence.Identity.Methods = append(component.Evidence.Identity.Methods,
+			&IdentityMethod{Technique: IDENTITY_TECHNIQUE, Confidence: confidence, Value: importName})
+		component.Properties = append(component.Properties, &Property{Name: PROPERTY_IMPORT_NAME,
+			Value: importName})
+
+		for _, prov := range importedModules.GetProvenance(importName) {
+			component.Evidence.Occurrences = append(component.Evidence.Occurrences,
+				&Occurrence{Location: fmt.Sprintf("%s#L%d", prov.Path, prov.RowStart+1)})
+		}
+	}
+
+	// Test, build and dev dependencies are not needed at runtime
+	for purl, component := range components {
+		component.Properties = append(component.Properties, &Property{Name: PROPERTY_SCOPE,
+			Value: string(scopes[purl])})
+		if scopes[purl] != imports.SCOPE_RUNTIME {
+			component.Scope = COMPONENT_SCOPE_EXCLUDED
+		}
+	}
+
+	sort.SliceStable(bom.Components, func(i, j int) bool {
+		return bom.Components[i].BomRef < bom.Components[j].BomRef
+	})
+
+	rootDependency := &Dependency{Ref: root.BomRef, DependsOn: make([]string, 0)}
+	for _, component := range bom.Components {
+		rootDependency.DependsOn = append(rootDependency.DependsOn, component.BomRef)
+	}
+	bom.Dependencies = []*Dependency{rootDependency}
+
+	return bom, nil
+}
+
+// Write writes the document as CycloneDX JSON
+func Write(w io.Writer, bom *Bom) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(bom)
+}
+
+// newRootComponent names the root component after the exported module,
+// a package exporting several modules lists them as its components
+func newRootComponent(exportedModules *imports.ExportedModules, rootName string) *Component {
+	names := exportedModules.GetExportedModules()
+	sort.Strings(names)
+
+	root := &Component{Type: COMPONENT_TYPE_APPLICATION, Name: rootName}
+	if len(names) == 1 {
+		root.Name = names[0]
+	} else {
+		for _, name := range names {
+			root.Components = append(root.Components, &Component{Type: COMPONENT_TYPE_LIBRARY,
+				BomRef: "module:" + name,
+				Name:   name})
+		}
+	}
+	root.BomRef = "root:" + root.Name
+
+	return root
+}
+
+// newPurl creates the package URL of a PyPI distribution
+func newPurl(distribution, version string) string {
+	purl := "pkg:pypi/" + manifest.NormalizeName(distribution)
+	if version != "" {
+		purl += "@" + version
+	}
+	return purl
+}
+
+// newSerialNumber creates a random RFC 4122 version 4 UUID URN
+func newSerialNumber() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+
+	return fmt.Sprintf("urn:uuid:%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]), nil
+}
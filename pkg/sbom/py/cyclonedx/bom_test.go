@@ -0,0 +1,125 @@
+package cyclonedx
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/safedep/codex/pkg/parser/py/imports"
+	"github.com/safedep/codex/pkg/resolver/py/dist"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestBom(t *testing.T, files map[string]string, overrides map[string][]string) *Bom {
+	rootDir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(rootDir, name)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		assert.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+
+	cf := imports.NewPyCodeParserFactory()
+	parser, err := cf.NewCodeParser()
+	assert.NoError(t, err)
+
+	importedModules, err := parser.FindImportedModules(context.Background(), rootDir, true, []string{".py"}, []string{})
+	assert.NoError(t, err)
+
+	exportedModules, err := parser.FindExportedModules(context.Background(), rootDir)
+	assert.NoError(t, err)
+
+	resolver, err := dist.NewResolver(dist.ResolverConfig{Overrides: overrides})
+	assert.NoError(t, err)
+
+	bom, err := NewBom(importedModules, exportedModules, BomConfig{RootName: "project", Resolver: resolver})
+	assert.NoError(t, err)
+
+	return bom
+}
+
+func TestNewBom(t *testing.T) {
+	bom := newTestBom(t, map[string]string{
+		"mypkg/__init__.py": "import os\nimport yaml\n",
+		"mypkg/api.py":      "import yaml\n\ntry:\n    import ujson\nexcept ImportError:\n    ujson = None\n",
+	}, nil)
+
+	assert.Equal(t, "CycloneDX", bom.BomFormat)
+	assert.Equal(t, "1.5", bom.SpecVersion)
+	assert.Regexp(t, regexp.MustCompile(`^urn:uuid:[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`),
+		bom.SerialNumber)
+
+	assert.Equal(t, "mypkg", bom.Metadata.Component.Name)
+	assert.Equal(t, 0, len(bom.Metadata.Component.Components))
+
+	assert.Equal(t, 2, len(bom.Components))
+
+	ujson := bom.Components[1]
+	assert.Equal(t, "pkg:pypi/ujson", ujson.Purl)
+	assert.Equal(t, COMPONENT_SCOPE_OPTIONAL, ujson.Scope)
+	assert.Equal(t, []*Occurrence{{Location: "mypkg/api.py#L4"}}, ujson.Evidence.Occurrences)
+
+	pyyaml := bom.Components[0]
+	assert.Equal(t, "pkg:pypi/pyyaml", pyyaml.Purl)
+	assert.Equal(t, "pyyaml", pyyaml.Name)
+	assert.Equal(t, COMPONENT_SCOPE_REQUIRED, pyyaml.Scope)
+	assert.Equal(t, []*Occurrence{{Location: "mypkg/__init__.py#L2"}, {Location: "mypkg/api.py#L1"}},
+		pyyaml.Evidence.Occurrences)
+	assert.Equal(t, "yaml", pyyaml.Evidence.Identity.Methods[0].Value)
+
+	assert.Equal(t, 1, len(bom.Dependencies))
+	assert.Equal(t, bom.Metadata.Component.BomRef, bom.Dependencies[0].Ref)
+	assert.Equal(t, []string{"pkg:pypi/pyyaml", "pkg:pypi/ujson"}, bom.Dependencies[0].DependsOn)
+}
+
+func TestNewBomScopes(t *testing.T) {
+	bom := newTestBom(t, map[string]string{
+		"app.py":            "import requests\n",
+		"tests/test_app.py": "import pytest\nimport requests\n",
+	}, nil)
+
+	assert.Equal(t, 2, len(bom.Components))
+	assert.Equal(t, "pkg:pypi/pytest", bom.Components[0].Purl)
+	assert.Equal(t, COMPONENT_SCOPE_EXCLUDED, bom.Components[0].Scope)
+	assert.Contains(t, bom.Components[0].Properties, &Property{Name: PROPERTY_SCOPE, Value: "test"})
+
+	assert.Equal(t, "pkg:pypi/requests", bom.Components[1].Purl)
+	assert.Equal(t, COMPONENT_SCOPE_REQUIRED, bom.Components[1].Scope)
+	assert.Contains(t, bom.Components[1].Properties, &Property{Name: PROPERTY_SCOPE, Value: "runtime"})
+}
+
+func TestNewBomMergesImportNames(t *testing.T) {
+	bom := newTestBom(t, map[string]string{
+		"a.py": "import first\n",
+		"b.py": "import second\n",
+		"c.py": "import third\n",
+	}, map[string][]string{"first": {"shared-dist"}, "second": {"shared-dist"}})
+
+	assert.Equal(t, "project", bom.Metadata.Component.Name)
+	assert.Equal(t, 2, len(bom.Components))
+	assert.Equal(t, "pkg:pypi/shared-dist", bom.Components[0].Purl)
+	assert.Equal(t, 2, len(bom.Components[0].Evidence.Occurrences))
+	assert.Equal(t, 2, len(bom.Components[0].Evidence.Identity.Methods))
+	assert.Equal(t, "pkg:pypi/third", bom.Components[1].Purl)
+}
+
+func TestWrite(t *testing.T) {
+	bom := newTestBom(t, map[string]string{"app.py": "import requests\n"}, nil)
+
+	var out bytes.Buffer
+	assert.NoError(t, Write(&out, bom))
+
+	var decoded map[string]interface{}
+	assert.NoError(t, json.Unmarshal(out.Bytes(), &decoded))
+	assert.Equal(t, "CycloneDX", decoded["bomFormat"])
+	assert.Equal(t, "1.5", decoded["specVersion"])
+
+	component := decoded["components"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "library", component["type"])
+	assert.Equal(t, "pkg:pypi/requests", component["bom-ref"])
+	occurrences := component["evidence"].(map[string]interface{})["occurrences"].([]interface{})
+	assert.Equal(t, "app.py#L1", occurrences[0].(map[string]interface{})["location"])
+}
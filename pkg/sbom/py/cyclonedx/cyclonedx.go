@@ -0,0 +1,89 @@
+package cyclonedx
+
+// Subset of the CycloneDX 1.5 JSON schema written by codex,
+// see https://cyclonedx.org/docs/1.5/json/
+
+const (
+	CYCLONEDX_BOM_FORMAT   = "CycloneDX"
+	CYCLONEDX_SPEC_VERSION = "1.5"
+)
+
+type ComponentType string
+
+const (
+	COMPONENT_TYPE_APPLICATION ComponentType = "application"
+	COMPONENT_TYPE_LIBRARY     ComponentType = "library"
+)
+
+type ComponentScope string
+
+const (
+	COMPONENT_SCOPE_REQUIRED ComponentScope = "required"
+	COMPONENT_SCOPE_OPTIONAL ComponentScope = "optional"
+	COMPONENT_SCOPE_EXCLUDED ComponentScope = "excluded" // not needed at runtime, e.g. test dependencies
+)
+
+type Bom struct {
+	BomFormat    string        `json:"bomFormat"`
+	SpecVersion  string        `json:"specVersion"`
+	SerialNumber string        `json:"serialNumber,omitempty"`
+	Version      int           `json:"version"`
+	Metadata     *Metadata     `json:"metadata,omitempty"`
+	Components   []*Component  `json:"components"`
+	Dependencies []*Dependency `json:"dependencies,omitempty"`
+}
+
+type Metadata struct {
+	Timestamp string     `json:"timestamp,omitempty"`
+	Tools     *Tools     `json:"tools,omitempty"`
+	Component *Component `json:"component,omitempty"`
+}
+
+type Tools struct {
+	Components []*Component `json:"components,omitempty"`
+}
+
+type Component struct {
+	Type       ComponentType  `json:"type"`
+	BomRef     string         `json:"bom-ref,omitempty"`
+	Name       string         `json:"name"`
+	Version    string         `json:"version,omitempty"`
+	Scope      ComponentScope `json:"scope,omitempty"`
+	Purl       string         `json:"purl,omitempty"`
+	Properties []*Property    `json:"properties,omitempty"`
+	Components []*Component   `json:"components,omitempty"`
+	Evidence   *Evidence      `json:"evidence,omitempty"`
+}
+
+type Property struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type Evidence struct {
+	Identity    *Identity     `json:"identity,omitempty"`
+	Occurrences []*Occurrence `json:"occurrences,omitempty"`
+}
+
+type Identity struct {
+	Field      string            `json:"field"`
+	Confidence float64           `json:"confidence"`
+	Methods    []*IdentityMethod `json:"methods,omitempty"`
+}
+
+type IdentityMethod struct {
+	Technique  string  `json:"technique"`
+	Confidence float64 `json:"confidence"`
+	Value      string  `json:"value,omitempty"`
+}
+
+// Occurrence locates the component in the code. CycloneDX 1.5 has no line field,
+// the location is written as <path>#L<line>.
+type Occurrence struct {
+	Location string `json:"location"`
+}
+
+type Dependency struct {
+	Ref       string   `json:"ref"`
+	DependsOn []string `json:"dependsOn,omitempty"`
+}
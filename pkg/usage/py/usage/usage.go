@@ -0,0 +1,113 @@
+/*
+	Find the symbols of the third-party packages used by the code
+*/
+
+package usage
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/safedep/codex/pkg/parser/py/imports"
+	"github.com/safedep/codex/pkg/utils/py/dir"
+)
+
+type UsageKind string
+
+const (
+	USAGE_IMPORT UsageKind = "import" // imported by an import statement, e.g. from django.db import models
+	USAGE_CALL   UsageKind = "call"   // called, e.g. requests.get(url)
+)
+
+// Location is a file and a row (0 based) using a symbol
+type Location struct {
+	Path string
+	Row  uint32
+}
+
+// SymbolUsage is a symbol of a package with every place using it
+type SymbolUsage struct {
+	Symbol    string // qualified name, e.g. numpy.array for np.array
+	Kind      UsageKind
+	Count     int
+	Locations []*Location
+}
+
+// PackageUsage is the inventory of the symbols of a package used by the code
+type PackageUsage struct {
+	Package string
+	Symbols []*SymbolUsage // sorted by symbol and kind
+}
+
+// FindUsage lists the symbols of every third-party package of the imported modules
+// which are imported or called by the code. Names bound by imports are replaced by
+// the names they refer to, e.g. np.array is numpy.array for import numpy as np.
+func FindUsage(importedModules *imports.ImportedModules, repoSymbols *imports.RepoSymbols) []*PackageUsage {
+	packages := make(map[string]map[string]*SymbolUsage, 0)
+	for _, pkg := range importedModules.GetPackagesNames() {
+		packages[pkg] = make(map[string]*SymbolUsage, 0)
+	}
+
+	add := func(symbol string, kind UsageKind, path string, row uint32) {
+		symbols, ok := packages[dir.SplitAndGetLeftMost(symbol, ".")]
+		if !ok {
+			return
+		}
+
+		key := string(kind) + "|" + symbol
+		usage, ok := symbols[key]
+		if !ok {
+			usage = &SymbolUsage{Symbol: symbol, Kind: kind, Locations: make([]*Location, 0)}
+			symbols[key] = usage
+		}
+		usage.Count += 1
+		usage.Locations = append(usage.Locations, &Location{Path: path, Row: row})
+	}
+
+	for _, symbols := range repoSymbols.Files {
+		for _, binding := range symbols.ImportBindings {
+			add(binding.Imported, USAGE_IMPORT, symbols.Path, binding.Node.RowStart)
+		}
+
+		for _, call := range symbols.Calls {
+			if qualified, ok := qualifyCallee(call.Callee, symbols.Imports); ok {
+				add(qualified, USAGE_CALL, symbols.Path, call.Node.RowStart)
+			}
+		}
+	}
+
+	usages := make([]*PackageUsage, 0, len(packages))
+	for pkg, symbols := range packages {
+		pu := &PackageUsage{Package: pkg, Symbols: make([]*SymbolUsage, 0, len(symbols))}
+		for _, usage := range symbols {
+			pu.Symbols = append(pu.Symbols, usage)
+		}
+		sort.Slice(pu.Symbols, func(i, j int) bool {
+			if pu.Symbols[i].Symbol != pu.Symbols[j].Symbol {
+				return pu.Symbols[i].Symbol < pu.Symbols[j].Symbol
+			}
+			return pu.Symbols[i].Kind < pu.Symbols[j].Kind
+		})
+		usages = append(usages, pu)
+	}
+
+	sort.Slice(usages, func(i, j int) bool {
+		return usages[i].Package < usages[j].Package
+	})
+	return usages
+}
+
+// qualifyCallee replaces the name bound by an import at the start of the callee
+func qualifyCallee(callee string, bindings map[string]string) (string, bool) {
+	first, rest, hasRest := strings.Cut(callee, ".")
+	qualified, ok := bindings[first]
+	if !ok || strings.HasPrefix(qualified, ".") {
+		// Relative imports are modules of the project
+		return "", false
+	}
+
+	if hasRest {
+		qualified += "." + rest
+	}
+	return qualified, true
+}
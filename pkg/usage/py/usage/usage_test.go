@@ -0,0 +1,71 @@
+package usage
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/safedep/codex/pkg/parser/py/imports"
+	"github.com/stretchr/testify/assert"
+)
+
+func findTestUsage(t *testing.T, files map[string]string) []*PackageUsage {
+	rootDir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(rootDir, name)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		assert.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+
+	parser, err := imports.NewPyCodeParserFactory().NewCodeParser()
+	assert.NoError(t, err)
+
+	importedModules, err := parser.FindImportedModules(context.Background(), rootDir, true, []string{".py"}, []string{})
+	assert.NoError(t, err)
+	repoSymbols, err := parser.FindSymbols(context.Background(), rootDir, true, []string{".py"}, []string{})
+	assert.NoError(t, err)
+
+	return FindUsage(importedModules, repoSymbols)
+}
+
+func TestFindUsage(t *testing.T) {
+	usages := findTestUsage(t, map[string]string{
+		"app/__init__.py": "",
+		"app/api.py": `import os
+import requests
+import numpy as np
+from django.db import models
+from . import helpers
+
+def fetch(url):
+    os.getcwd()
+    helpers.run()
+    np.array([1])
+    return requests.get(url), requests.get(url + "/next")
+
+class Item(models.Model):
+    query = models.Q(name="x")
+`,
+		"app/helpers.py": "import numpy\n\ndef run():\n    return numpy.array([2]).sum()\n",
+	})
+
+	assert.Equal(t, 3, len(usages))
+	assert.Equal(t, "django", usages[0].Package)
+	assert.Equal(t, []*SymbolUsage{
+		{Symbol: "django.db.models", Kind: USAGE_IMPORT, Count: 1, Locations: []*Location{{Path: "app/api.py", Row: 3}}},
+		{Symbol: "django.db.models.Q", Kind: USAGE_CALL, Count: 1, Locations: []*Location{{Path: "app/api.py", Row: 13}}},
+	}, usages[0].Symbols)
+
+	assert.Equal(t, "numpy", usages[1].Package)
+	assert.Equal(t, []*SymbolUsage{
+		{Symbol: "numpy", Kind: USAGE_IMPORT, Count: 2,
+			Locations: []*Location{{Path: "app/api.py", Row: 2}, {Path: "app/helpers.py", Row: 0}}},
+		{Symbol: "numpy.array", Kind: USAGE_CALL, Count: 2,
+			Locations: []*Location{{Path: "app/api.py", Row: 9}, {Path: "app/helpers.py", Row: 3}}},
+	}, usages[1].Symbols)
+
+	assert.Equal(t, "requests", usages[2].Package)
+	assert.Equal(t, "requests.get", usages[2].Symbols[1].Symbol)
+	assert.Equal(t, 2, usages[2].Symbols[1].Count)
+}
@@ -0,0 +1,208 @@
+/*
+	Filter the files of a directory tree with glob patterns and ignore files
+*/
+
+package pathfilter
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+)
+
+// Ignore files honored in every directory of the tree, in the syntax of .gitignore
+var IGNORE_FILES = []string{".gitignore", ".codexignore"}
+
+// DEFAULT_EXCLUDES skips version control metadata, virtualenvs and caches
+var DEFAULT_EXCLUDES = []string{"**/.git", "**/.hg", "**/.svn",
+	"**/venv", "**/.venv", "**/.tox", "**/.nox", "**/site-packages", "**/__pycache__"}
+
+// File whose presence marks a directory as a virtualenv
+const VIRTUALENV_MARKER = "pyvenv.cfg"
+
+type Config struct {
+	// Doublestar patterns of the files to include, relative to the root directory.
+	// Every file is included when empty.
+	Include []string
+
+	// Doublestar patterns of the files and directories to exclude, relative to the root directory
+	Exclude []string
+
+	// Honor the ignore files of the tree, and skip DEFAULT_EXCLUDES and virtualenvs
+	HonorIgnores bool
+}
+
+// Filter decides which paths of a tree are scanned. Paths are relative to the
+// root directory. Ignore files are loaded as their directories are entered, so
+// directories must be checked with ExcludeDir before the files they contain.
+type Filter struct {
+	rootDir string
+	config  Config
+	rules   []*ignoreRule
+}
+
+// ignoreRule is a line of an ignore file
+type ignoreRule struct {
+	pattern string // doublestar pattern relative to the root directory
+	negate  bool   // the line starts with !
+	dirOnly bool   // the line ends with /
+}
+
+func NewFilter(rootDir string, config Config) (*Filter, error) {
+	for _, pattern := range append(append([]string{}, config.Include...), config.Exclude...) {
+		if !doublestar.ValidatePattern(pattern) {
+			return nil, fmt.Errorf("invalid glob pattern %q", pattern)
+		}
+	}
+
+	if config.HonorIgnores {
+		config.Exclude = append(append([]string{}, config.Exclude...), DEFAULT_EXCLUDES...)
+	}
+
+	f := &Filter{rootDir: rootDir, config: config, rules: make([]*ignoreRule, 0)}
+	if config.HonorIgnores {
+		if err := f.loadIgnoreFiles("."); err != nil {
+			return nil, err
+		}
+	}
+
+	return f, nil
+}
+
+// ExcludeDir checks if the directory is skipped with everything in it
+func (f *Filter) ExcludeDir(relPath string) bool {
+	relPath = filepath.ToSlash(relPath)
+	if relPath == "." {
+		return false
+	}
+
+	if matchAny(f.config.Exclude, relPath) || f.isIgnored(relPath, true) {
+		return true
+	}
+
+	if f.config.HonorIgnores {
+		if _, err := os.Stat(filepath.Join(f.rootDir, relPath, VIRTUALENV_MARKER)); err == nil {
+			return true
+		}
+
+		// Rules of the ignore files in the directory apply to its content.
+		// An unreadable ignore file does not stop the scan.
+		_ = f.loadIgnoreFiles(relPath)
+	}
+
+	return false
+}
+
+// IncludeFile checks if the file is scanned
+func (f *Filter) IncludeFile(relPath string) bool {
+	relPath = filepath.ToSlash(relPath)
+	if matchAny(f.config.Exclude, relPath) || f.isIgnored(relPath, false) {
+		return false
+	}
+
+	return len(f.config.Include) == 0 || matchAny(f.config.Include, relPath)
+}
+
+// isIgnored applies the ignore rules, the last matching rule wins
+func (f *Filter) isIgnored(relPath string, isDir bool) bool {
+	ignored := false
+	for _, rule := range f.rules {
+		if rule.dirOnly && !isDir {
+			continue
+		}
+		if match(rule.pattern, relPath) {
+			ignored = !rule.negate
+		}
+	}
+
+	return ignored
+}
+
+// loadIgnoreFiles adds the rules of the ignore files in the directory
+func (f *Filter) loadIgnoreFiles(relDir string) error {
+	for _, name := range IGNORE_FILES {
+		file, err := os.Open(filepath.Join(f.rootDir, relDir, name))
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return err
+		}
+
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			if rule, ok := parseIgnoreLine(relDir, scanner.Text()); ok {
+				f.rules = append(f.rules, rule)
+			}
+		}
+		file.Close()
+
+		if err := scanner.Err(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// parseIgnoreLine converts a line of an ignore file in the directory to a rule
+func parseIgnoreLine(relDir string, line string) (*ignoreRule, bool) {
+	line = strings.TrimRight(line, " \t\r")
+	if line == "" || strings.HasPrefix(line, "#") {
+		return nil, false
+	}
+
+	rule := &ignoreRule{}
+	if strings.HasPrefix(line, "!") {
+		rule.negate = true
+		line = line[1:]
+	} else if strings.HasPrefix(line, `\`) {
+		// \# and \! escape the first character
+		line = line[1:]
+	}
+
+	if strings.HasSuffix(line, "/") {
+		rule.dirOnly = true
+		line = strings.TrimSuffix(line, "/")
+	}
+
+	// A pattern with a slash is relative to the directory of the ignore file,
+	// any other pattern matches at any depth below it
+	anchored := strings.Contains(line, "/")
+	line = strings.TrimPrefix(line, "/")
+	if line == "" {
+		return nil, false
+	}
+	if !anchored {
+		line = "**/" + line
+	}
+
+	rule.pattern = line
+	if relDir != "." {
+		rule.pattern = path.Join(filepath.ToSlash(relDir), line)
+	}
+
+	if !doublestar.ValidatePattern(rule.pattern) {
+		return nil, false
+	}
+	return rule, true
+}
+
+func matchAny(patterns []string, relPath string) bool {
+	for _, pattern := range patterns {
+		if match(pattern, relPath) {
+			return true
+		}
+	}
+	return false
+}
+
+func match(pattern, relPath string) bool {
+	matched, _ := doublestar.Match(pattern, relPath)
+	return matched
+}
@@ -0,0 +1,106 @@
+package pathfilter
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	rootDir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(rootDir, name)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		assert.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+	return rootDir
+}
+
+func TestFilterPatterns(t *testing.T) {
+	f, err := NewFilter(t.TempDir(), Config{Include: []string{"src/**/*.py"},
+		Exclude: []string{"**/test", "src/gen/*.py"}})
+	assert.NoError(t, err)
+
+	assert.False(t, f.ExcludeDir("."))
+	assert.False(t, f.ExcludeDir("src"))
+	assert.True(t, f.ExcludeDir("test"))
+	assert.True(t, f.ExcludeDir("src/app/test"))
+	assert.False(t, f.ExcludeDir("src/app/tests"))
+
+	assert.True(t, f.IncludeFile("src/app.py"))
+	assert.True(t, f.IncludeFile("src/app/views.py"))
+	assert.False(t, f.IncludeFile("setup.py"))
+	assert.False(t, f.IncludeFile("src/gen/models.py"))
+
+	// Virtualenvs are only skipped when ignores are honored
+	assert.False(t, f.ExcludeDir(".venv"))
+
+	_, err = NewFilter(t.TempDir(), Config{Exclude: []string{"src/[a"}})
+	assert.Error(t, err)
+}
+
+func TestFilterIgnores(t *testing.T) {
+	rootDir := writeFiles(t, map[string]string{
+		".gitignore":          "# build output\nbuild/\n*.pyc\n/dist\n!keep.pyc\n",
+		"src/.codexignore":    "generated\n/local.py\n",
+		"myenv/pyvenv.cfg":    "home = /usr/bin\n",
+		"src/pkg/local.py":    "",
+		"lib/site-packages/a": "",
+	})
+
+	f, err := NewFilter(rootDir, Config{HonorIgnores: true})
+	assert.NoError(t, err)
+
+	assert.True(t, f.ExcludeDir("build"))
+	assert.True(t, f.ExcludeDir("src/build"))
+	assert.True(t, f.ExcludeDir("dist"))
+	assert.False(t, f.ExcludeDir("src/dist"))
+	assert.True(t, f.ExcludeDir(".git"))
+	assert.True(t, f.ExcludeDir("lib/site-packages"))
+	assert.True(t, f.ExcludeDir("myenv"))
+
+	assert.True(t, f.IncludeFile("build.py"))
+	assert.True(t, f.IncludeFile("build"))
+	assert.False(t, f.IncludeFile("cache.pyc"))
+	assert.True(t, f.IncludeFile("keep.pyc"))
+
+	// Rules of src/.codexignore apply once src is entered
+	assert.True(t, f.IncludeFile("src/generated"))
+	assert.False(t, f.ExcludeDir("src"))
+	assert.False(t, f.IncludeFile("src/generated"))
+	assert.True(t, f.ExcludeDir("src/pkg/generated"))
+	assert.False(t, f.IncludeFile("src/local.py"))
+	assert.True(t, f.IncludeFile("src/pkg/local.py"))
+	assert.True(t, f.IncludeFile("generated"))
+
+	f, err = NewFilter(rootDir, Config{})
+	assert.NoError(t, err)
+	assert.False(t, f.ExcludeDir("build"))
+	assert.False(t, f.ExcludeDir("myenv"))
+}
+
+func TestParseIgnoreLine(t *testing.T) {
+	cases := []struct {
+		relDir string
+		line   string
+		rule   *ignoreRule
+	}{
+		{".", "# comment", nil},
+		{".", "   ", nil},
+		{".", "*.pyc", &ignoreRule{pattern: "**/*.pyc"}},
+		{".", "/dist", &ignoreRule{pattern: "dist"}},
+		{".", "docs/_build/", &ignoreRule{pattern: "docs/_build", dirOnly: true}},
+		{".", "!keep.py", &ignoreRule{pattern: "**/keep.py", negate: true}},
+		{".", `\#file`, &ignoreRule{pattern: "**/#file"}},
+		{"src", "gen", &ignoreRule{pattern: "src/**/gen"}},
+		{"src", "/gen", &ignoreRule{pattern: "src/gen"}},
+	}
+
+	for _, test := range cases {
+		rule, ok := parseIgnoreLine(test.relDir, test.line)
+		assert.Equal(t, test.rule != nil, ok, test.line)
+		assert.Equal(t, test.rule, rule, test.line)
+	}
+}
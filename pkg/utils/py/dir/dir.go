@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"fmt"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
 
@@ -110,3 +111,56 @@ func SplitAndGetLeftMost(path, sep string) string {
 	// If there are no parts, return an empty string
 	return ""
 }
+
+// ModuleName returns the dotted name a Python file is imported by, e.g. pkg.sub.mod
+// for pkg/sub/mod.py or pkg.sub for pkg/sub/__init__.py. The name starts at the
+// outermost directory of the package, so files of a src layout are named without src.
+func ModuleName(rootDir, relPath string) string {
+	relPath = filepath.ToSlash(relPath)
+	base := path.Base(relPath)
+	name := strings.TrimSuffix(base, path.Ext(base))
+
+	parts := make([]string, 0)
+	if name != "__init__" {
+		parts = append(parts, name)
+	}
+
+	// Walk up the directories as long as they are packages
+	for d := path.Dir(relPath); d != "." && d != "/"; d = path.Dir(d) {
+		if _, err := os.Stat(filepath.Join(rootDir, d, "__init__.py")); err != nil {
+			break
+		}
+		parts = append([]string{path.Base(d)}, parts...)
+	}
+
+	return strings.Join(parts, ".")
+}
+
+// ResolveRelativeName returns the absolute name of a name imported relatively by a
+// module, e.g. pkg.utils for .utils or pkg for .. in pkg/sub/views.py. The package of
+// a module is its parent, a package is its own. Names going beyond the top-level
+// package of the module are errors.
+func ResolveRelativeName(moduleName string, isPackage bool, name string) (string, error) {
+	relative := strings.TrimLeft(name, ".")
+	level := len(name) - len(relative)
+	if level == 0 {
+		return name, nil
+	}
+
+	parts := []string{}
+	if moduleName != "" {
+		parts = strings.Split(moduleName, ".")
+	}
+	if !isPackage && len(parts) > 0 {
+		parts = parts[:len(parts)-1]
+	}
+	if level > len(parts) {
+		return "", fmt.Errorf("relative import %s of %s goes beyond the top-level package", name, moduleName)
+	}
+	parts = parts[:len(parts)-(level-1)]
+
+	if relative != "" {
+		parts = append(parts, relative)
+	}
+	return strings.Join(parts, "."), nil
+}
@@ -144,3 +144,52 @@ func createEmptyDirectory(rootDir, directoryName string) string {
 
 	return directoryPath
 }
+
+func TestModuleName(t *testing.T) {
+	rootDir := createTempDirectory(t)
+	srcDir := createEmptyDirectory(rootDir, "src")
+	pkgDir := createDirectoryWithInitPy(srcDir, "pkg")
+	createDirectoryWithInitPy(pkgDir, "sub")
+	createEmptyDirectory(rootDir, "scripts")
+
+	tests := []struct {
+		relPath  string
+		expected string
+	}{
+		{"app.py", "app"},
+		{"src/pkg/__init__.py", "pkg"},
+		{"src/pkg/api.py", "pkg.api"},
+		{"src/pkg/sub/__init__.py", "pkg.sub"},
+		{"src/pkg/sub/models.py", "pkg.sub.models"},
+		{"scripts/run.py", "run"},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.expected, ModuleName(rootDir, test.relPath), test.relPath)
+	}
+}
+
+func TestResolveRelativeName(t *testing.T) {
+	tests := []struct {
+		moduleName string
+		isPackage  bool
+		name       string
+		expected   string
+		err        bool
+	}{
+		{"pkg.sub.views", false, "os.path", "os.path", false},
+		{"pkg.sub.views", false, ".services", "pkg.sub.services", false},
+		{"pkg.sub.views", false, ".", "pkg.sub", false},
+		{"pkg.sub.views", false, "..helper.get_score", "pkg.helper.get_score", false},
+		{"pkg.sub", true, ".models", "pkg.sub.models", false},
+		{"pkg.sub", true, "..", "pkg", false},
+		{"pkg.sub.views", false, "...", "", true},
+		{"run", false, ".utils", "", true},
+	}
+
+	for _, test := range tests {
+		actual, err := ResolveRelativeName(test.moduleName, test.isPackage, test.name)
+		assert.Equal(t, test.expected, actual, test.name)
+		assert.Equal(t, test.err, err != nil, test.name)
+	}
+}
@@ -0,0 +1,170 @@
+package dir
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/safedep/dry/log"
+)
+
+// InstalledDistribution is a distribution found in a site-packages directory
+// along with the top level modules it provides
+type InstalledDistribution struct {
+	Name    string   // distribution name, e.g. PyYAML
+	Version string   // distribution version, if known
+	Path    string   // metadata directory relative to the root directory
+	Modules []string // top level importable modules, e.g. yaml, _yaml
+}
+
+// FindInstalledDistributions finds the distributions installed in a site-packages
+// directory by reading the top_level.txt and RECORD files of their metadata directories
+func FindInstalledDistributions(rootDir string) ([]*InstalledDistribution, error) {
+	dists := make([]*InstalledDistribution, 0)
+
+	// Reuse the top level module discovery to find metadata directories having top_level.txt
+	topLevelModules, err := FindTopLevelModules(rootDir)
+	if err != nil {
+		return nil, err
+	}
+
+	metadataDirs := make(map[string]bool, 0)
+	for _, relPath := range topLevelModules {
+		if strings.HasSuffix(relPath, "top_level.txt") {
+			metadataDirs[filepath.Dir(relPath)] = true
+		}
+	}
+
+	// Distributions without top_level.txt are still described by their RECORD file
+	entries, err := os.ReadDir(rootDir)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if entry.IsDir() && isMetadataDir(entry.Name()) {
+			metadataDirs[entry.Name()] = true
+		}
+	}
+
+	for metadataDir := range metadataDirs {
+		dist := readInstalledDistribution(rootDir, metadataDir)
+		if dist != nil {
+			dists = append(dists, dist)
+		}
+	}
+
+	sort.Slice(dists, func(i, j int) bool {
+		return dists[i].Path < dists[j].Path
+	})
+
+	return dists, nil
+}
+
+func isMetadataDir(name string) bool {
+	return strings.HasSuffix(name, ".dist-info") || strings.HasSuffix(name, ".egg-info")
+}
+
+func readInstalledDistribution(rootDir, metadataDir string) *InstalledDistribution {
+	name, version := splitMetadataDirName(filepath.Base(metadataDir))
+	dist := &InstalledDistribution{Name: name, Version: version, Path: metadataDir}
+
+	// METADATA (wheel) or PKG-INFO (egg) carry the canonical name
+	for _, metadataFile := range []string{"METADATA", "PKG-INFO"} {
+		lines, err := ReadAllLines(filepath.Join(rootDir, metadataDir, metadataFile))
+		if err != nil {
+			continue
+		}
+		for _, line := range lines {
+			if line == "" {
+				// Headers end at the first empty line
+				break
+			}
+			if value, found := strings.CutPrefix(line, "Name:"); found {
+				dist.Name = strings.TrimSpace(value)
+			} else if value, found := strings.CutPrefix(line, "Version:"); found {
+				dist.Version = strings.TrimSpace(value)
+			}
+		}
+		break
+	}
+
+	modules := make(map[string]bool, 0)
+	topLevel, err := ReadAllLines(filepath.Join(rootDir, metadataDir, "top_level.txt"))
+	if err == nil {
+		for _, mod := range topLevel {
+			mod = strings.TrimSpace(mod)
+			if mod != "" {
+				modules[strings.ReplaceAll(mod, "/", ".")] = true
+			}
+		}
+	}
+
+	// Fall back to RECORD only when top_level.txt is not available since
+	// RECORD also lists scripts and data files
+	if len(modules) == 0 {
+		for _, recordFile := range []string{"RECORD", "installed-files.txt"} {
+			records, err := ReadAllLines(filepath.Join(rootDir, metadataDir, recordFile))
+			if err != nil {
+				log.Debugf("Error while reading %s of %s: %s", recordFile, metadataDir, err)
+				continue
+			}
+			for _, record := range records {
+				mod := moduleFromRecord(record)
+				if mod != "" {
+					modules[mod] = true
+				}
+			}
+		}
+	}
+
+	for mod := range modules {
+		dist.Modules = append(dist.Modules, mod)
+	}
+	sort.Strings(dist.Modules)
+
+	if dist.Name == "" {
+		return nil
+	}
+
+	return dist
+}
+
+// splitMetadataDirName splits PyYAML-6.0.1.dist-info into name and version
+func splitMetadataDirName(dirName string) (string, string) {
+	base := strings.TrimSuffix(strings.TrimSuffix(dirName, ".dist-info"), ".egg-info")
+	name, version, _ := strings.Cut(base, "-")
+	if idx := strings.Index(version, "-"); idx >= 0 {
+		// Egg directories can carry python version, e.g. -py3.11
+		version = version[:idx]
+	}
+
+	return name, version
+}
+
+// moduleFromRecord returns the top level module of a file listed in a RECORD line
+// such as yaml/__init__.py,sha256=...,1234
+func moduleFromRecord(record string) string {
+	filePath, _, _ := strings.Cut(record, ",")
+	filePath = strings.TrimSpace(filePath)
+	if filePath == "" || strings.HasPrefix(filePath, "..") || strings.HasPrefix(filePath, "/") {
+		return ""
+	}
+
+	top := SplitAndGetLeftMost(filepath.ToSlash(filePath), "/")
+	if top == filePath {
+		// Single file module, e.g. six.py or _yaml.cpython-311-x86_64-linux-gnu.so
+		ext := filepath.Ext(top)
+		if ext != ".py" && ext != ".so" && ext != ".pyd" {
+			return ""
+		}
+		top = SplitAndGetLeftMost(top, ".")
+	}
+
+	if top == "__pycache__" || top == "bin" || isMetadataDir(top) ||
+		strings.HasSuffix(top, ".data") || strings.HasSuffix(top, ".pth") {
+		return ""
+	}
+
+	return top
+}
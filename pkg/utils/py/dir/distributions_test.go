@@ -0,0 +1,34 @@
+package dir
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindInstalledDistributions(t *testing.T) {
+	siteDir := t.TempDir()
+
+	wheelDir := createEmptyDirectory(siteDir, "opencv_python-4.8.1.78.dist-info")
+	assert.NoError(t, os.WriteFile(filepath.Join(wheelDir, "METADATA"),
+		[]byte("Metadata-Version: 2.1\nName: opencv-python\nVersion: 4.8.1.78\n"), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(wheelDir, "top_level.txt"), []byte("cv2\n"), 0644))
+
+	eggDir := createEmptyDirectory(siteDir, "six-1.16.0-py3.11.egg-info")
+	assert.NoError(t, os.WriteFile(filepath.Join(eggDir, "installed-files.txt"),
+		[]byte("six.py\n__pycache__/six.cpython-311.pyc\n"), 0644))
+
+	dists, err := FindInstalledDistributions(siteDir)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(dists))
+
+	assert.Equal(t, "opencv-python", dists[0].Name)
+	assert.Equal(t, "4.8.1.78", dists[0].Version)
+	assert.Equal(t, []string{"cv2"}, dists[0].Modules)
+
+	assert.Equal(t, "six", dists[1].Name)
+	assert.Equal(t, "1.16.0", dists[1].Version)
+	assert.Equal(t, []string{"six"}, dists[1].Modules)
+}
@@ -0,0 +1,100 @@
+package stdlib
+
+import (
+	"embed"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+//go:embed data/*.txt
+var stdlibLists embed.FS
+
+// DEFAULT_VERSION matches a module which is part of any Python 3 standard library
+const DEFAULT_VERSION = ""
+
+var supportedVersions = []string{"2.7", "3.6", "3.7", "3.8", "3.9", "3.10", "3.11", "3.12", "3.13"}
+
+// StdlibModules is the set of top level standard library modules of a Python version
+type StdlibModules struct {
+	version string
+	modules map[string]bool
+}
+
+// SupportedVersions returns the Python versions having an embedded module list
+func SupportedVersions() []string {
+	versions := make([]string, len(supportedVersions))
+	copy(versions, supportedVersions)
+	return versions
+}
+
+// NewStdlibModules loads the standard library modules of a Python version such
+// as 3.11 or 3.11.4. With the DEFAULT_VERSION or just the major version 3, the
+// modules of every supported Python 3 version are loaded.
+func NewStdlibModules(version string) (*StdlibModules, error) {
+	versions, err := matchVersions(version)
+	if err != nil {
+		return nil, err
+	}
+
+	modules := make(map[string]bool, 0)
+	for _, v := range versions {
+		data, err := stdlibLists.ReadFile(fmt.Sprintf("data/%s.txt", v))
+		if err != nil {
+			return nil, err
+		}
+
+		for _, line := range strings.Split(string(data), "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			modules[line] = true
+		}
+	}
+
+	return &StdlibModules{version: version, modules: modules}, nil
+}
+
+// Version returns the Python version the modules were loaded for
+func (s *StdlibModules) Version() string {
+	return s.version
+}
+
+// IsStdlib returns true when the top level package of the dotted module name
+// is part of the standard library
+func (s *StdlibModules) IsStdlib(moduleName string) bool {
+	topLevel, _, _ := strings.Cut(moduleName, ".")
+	return s.modules[topLevel]
+}
+
+// GetModules returns the sorted top level standard library modules
+func (s *StdlibModules) GetModules() []string {
+	modules := make([]string, 0, len(s.modules))
+	for mod := range s.modules {
+		modules = append(modules, mod)
+	}
+
+	sort.Strings(modules)
+	return modules
+}
+
+func matchVersions(version string) ([]string, error) {
+	if version == DEFAULT_VERSION || version == "3" {
+		return supportedVersions[1:], nil
+	}
+
+	// Only major and minor versions matter, e.g. 3.11.4 is 3.11
+	parts := strings.Split(version, ".")
+	if len(parts) >= 2 {
+		majorMinor := parts[0] + "." + parts[1]
+		for _, v := range supportedVersions {
+			if v == majorMinor {
+				return []string{v}, nil
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("unsupported python version %q, supported versions are %s",
+		version, strings.Join(supportedVersions, ", "))
+}
@@ -0,0 +1,51 @@
+package stdlib
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsStdlib(t *testing.T) {
+	tests := []struct {
+		version  string
+		module   string
+		expected bool
+	}{
+		{DEFAULT_VERSION, "json", true},
+		{DEFAULT_VERSION, "os.path", true},
+		{DEFAULT_VERSION, "distutils", true},
+		{DEFAULT_VERSION, "requests", false},
+		{"2.7", "urllib2", true},
+		{"2.7", "asyncio", false},
+		{"3.6", "dataclasses", false},
+		{"3.7", "dataclasses", true},
+		{"3.8", "zoneinfo", false},
+		{"3.8", "_aix_support", false},
+		{"3.8", "_peg_parser", false},
+		{"3.9", "_peg_parser", true},
+		{"3.10", "binhex", true},
+		{"3.11", "binhex", false},
+		{"3.9.18", "zoneinfo", true},
+		{"3.10", "tomllib", false},
+		{"3.11", "tomllib", true},
+		{"3.11", "distutils.core", true},
+		{"3.12", "distutils.core", false},
+		{"3.12", "telnetlib", true},
+		{"3.13", "telnetlib", false},
+	}
+
+	for _, test := range tests {
+		modules, err := NewStdlibModules(test.version)
+		assert.NoError(t, err)
+		assert.Equal(t, test.expected, modules.IsStdlib(test.module), "%s in %s", test.module, test.version)
+	}
+}
+
+func TestUnsupportedVersion(t *testing.T) {
+	_, err := NewStdlibModules("3.99")
+	assert.Error(t, err)
+
+	_, err = NewStdlibModules("python")
+	assert.Error(t, err)
+}
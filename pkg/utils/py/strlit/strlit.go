@@ -0,0 +1,40 @@
+package strlit
+
+import "strings"
+
+// Unquote returns the value of a Python string literal such as 'a', "b", r'c' or """d""".
+// Formatted strings and concatenations can not be evaluated statically and are rejected.
+func Unquote(literal string) (string, bool) {
+	literal = strings.TrimSpace(literal)
+
+	prefixEnd := strings.IndexAny(literal, `'"`)
+	if prefixEnd < 0 {
+		return "", false
+	}
+
+	prefix := strings.ToLower(literal[:prefixEnd])
+	if strings.Contains(prefix, "f") || strings.Trim(prefix, "rbu") != "" {
+		return "", false
+	}
+
+	body := literal[prefixEnd:]
+	for _, quote := range []string{`"""`, `'''`, `"`, `'`} {
+		if len(body) >= 2*len(quote) && strings.HasPrefix(body, quote) && strings.HasSuffix(body, quote) {
+			value := body[len(quote) : len(body)-len(quote)]
+			if strings.Contains(value, quote) && len(quote) == 1 && !strings.Contains(value, `\`+quote) {
+				// Implicit concatenation such as 'a' 'b'
+				return "", false
+			}
+			if !strings.Contains(prefix, "r") {
+				value = unescape(value)
+			}
+			return value, true
+		}
+	}
+
+	return "", false
+}
+
+func unescape(value string) string {
+	return strings.NewReplacer(`\\`, `\`, `\'`, `'`, `\"`, `"`, `\n`, "\n", `\t`, "\t").Replace(value)
+}
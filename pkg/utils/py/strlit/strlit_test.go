@@ -0,0 +1,32 @@
+package strlit
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnquote(t *testing.T) {
+	tests := []struct {
+		literal  string
+		expected string
+		ok       bool
+	}{
+		{`'requests'`, "requests", true},
+		{`"foo.bar"`, "foo.bar", true},
+		{`r"C:\path"`, `C:\path`, true},
+		{`u'yaml'`, "yaml", true},
+		{`"""multi"""`, "multi", true},
+		{`'it\'s'`, "it's", true},
+		{`f"plugins.{name}"`, "", false},
+		{`'a' 'b'`, "", false},
+		{`name`, "", false},
+		{`"`, "", false},
+	}
+
+	for _, test := range tests {
+		value, ok := Unquote(test.literal)
+		assert.Equal(t, test.ok, ok, test.literal)
+		assert.Equal(t, test.expected, value, test.literal)
+	}
+}
@@ -103,3 +103,21 @@ func FindAllChildrenOfType(root *tree_sitter.Node, childType string, level int)
 
 	return res
 }
+
+// FindChildrenByFieldName returns the children of the node with the field name. Unlike
+// Node.FieldNameForChild, fields repeated for several children are all found, e.g. the
+// names of import a, b.
+func FindChildrenByFieldName(root *tree_sitter.Node, fieldName string) []*tree_sitter.Node {
+	var res []*tree_sitter.Node
+
+	cursor := tree_sitter.NewTreeCursor(root)
+	defer cursor.Close()
+
+	for ok := cursor.GoToFirstChild(); ok; ok = cursor.GoToNextSibling() {
+		if cursor.CurrentFieldName() == fieldName {
+			res = append(res, cursor.CurrentNode())
+		}
+	}
+
+	return res
+}
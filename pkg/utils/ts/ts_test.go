@@ -1 +1,25 @@
 package ts
+
+import (
+	"context"
+	"testing"
+
+	tree_sitter "github.com/smacker/go-tree-sitter"
+	"github.com/smacker/go-tree-sitter/python"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindChildrenByFieldName(t *testing.T) {
+	code := []byte("from a import b, c as d, e\n")
+	tree, err := tree_sitter.ParseCtx(context.Background(), code, python.GetLanguage())
+	assert.NoError(t, err)
+
+	statement := tree.NamedChild(0)
+	names := []string{}
+	for _, node := range FindChildrenByFieldName(statement, "name") {
+		names = append(names, node.Content(code))
+	}
+
+	assert.Equal(t, []string{"b", "c as d", "e"}, names)
+	assert.Equal(t, 1, len(FindChildrenByFieldName(statement, "module_name")))
+}
@@ -0,0 +1,91 @@
+/*
+	Apply a function to the files of a repository with a pool of workers
+*/
+
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/safedep/dry/log"
+)
+
+// Result is the result of a worker for a file
+type Result[T any] struct {
+	RelPath string
+	Value   T
+	Err     error
+}
+
+// Run applies the function to every path with a pool of workers, each with its
+// own state created by newWorker, e.g. a parser which must not be shared between
+// goroutines. The results are in the order of the paths, whatever the number of
+// workers. With failOnFirstError the remaining paths are skipped after a failure
+// and the error of the first failing path is returned.
+func Run[W any, T any](ctx context.Context, concurrency int, relPaths []string, failOnFirstError bool,
+	newWorker func() W, fn func(ctx context.Context, worker W, relPath string) (T, error)) ([]*Result[T], error) {
+	poolCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	workers := concurrency
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > len(relPaths) {
+		workers = len(relPaths)
+	}
+
+	results := make([]*Result[T], len(relPaths))
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			worker := newWorker()
+			for idx := range jobs {
+				value, err := fn(poolCtx, worker, relPaths[idx])
+				if err != nil {
+					log.Debugf("Error while parsing the file %s", relPaths[idx])
+					if failOnFirstError {
+						// Skip the files which are not parsed yet
+						cancel()
+					}
+				}
+				results[idx] = &Result[T]{RelPath: relPaths[idx], Value: value, Err: err}
+			}
+		}()
+	}
+
+	// Feed the workers until every file is queued or the scan is cancelled
+feed:
+	for idx := range relPaths {
+		select {
+		case jobs <- idx:
+		case <-poolCtx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	// The scan is cancelled by the caller
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if failOnFirstError {
+		for _, result := range results {
+			// Files interrupted by the failure of another file are not errors
+			if result == nil || result.Err == nil || errors.Is(result.Err, context.Canceled) {
+				continue
+			}
+			return nil, result.Err
+		}
+	}
+
+	return results, nil
+}
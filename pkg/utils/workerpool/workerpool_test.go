@@ -0,0 +1,75 @@
+package workerpool
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRun(t *testing.T) {
+	relPaths := make([]string, 0)
+	for i := 0; i < 20; i++ {
+		relPaths = append(relPaths, fmt.Sprintf("file%d.py", i))
+	}
+
+	for _, concurrency := range []int{0, 1, 4, 64} {
+		var workers int32
+		results, err := Run(context.Background(), concurrency, relPaths, false,
+			func() int { return int(atomic.AddInt32(&workers, 1)) },
+			func(ctx context.Context, worker int, relPath string) (string, error) {
+				if relPath == "file3.py" {
+					return "", fmt.Errorf("invalid file")
+				}
+				return "parsed " + relPath, nil
+			})
+		assert.NoError(t, err)
+		assert.Equal(t, len(relPaths), len(results))
+		assert.LessOrEqual(t, int(workers), max(1, min(concurrency, len(relPaths))))
+
+		for i, result := range results {
+			assert.Equal(t, relPaths[i], result.RelPath)
+			if i == 3 {
+				assert.Error(t, result.Err)
+				continue
+			}
+			assert.Equal(t, "parsed "+relPaths[i], result.Value)
+		}
+	}
+}
+
+func TestRunFailOnFirstError(t *testing.T) {
+	relPaths := make([]string, 0)
+	for i := 0; i < 1000; i++ {
+		relPaths = append(relPaths, fmt.Sprintf("file%d.py", i))
+	}
+
+	var parsed int32
+	_, err := Run(context.Background(), 1, relPaths, true,
+		func() struct{} { return struct{}{} },
+		func(ctx context.Context, worker struct{}, relPath string) (int, error) {
+			atomic.AddInt32(&parsed, 1)
+			if relPath == "file1.py" {
+				return 0, fmt.Errorf("invalid file %s", relPath)
+			}
+			return 1, nil
+		})
+	assert.ErrorContains(t, err, "invalid file file1.py")
+
+	// The files queued after the failure are skipped
+	assert.Less(t, int(parsed), 10)
+}
+
+func TestRunCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := Run(ctx, 2, []string{"a.py", "b.py"}, false,
+		func() struct{} { return struct{}{} },
+		func(ctx context.Context, worker struct{}, relPath string) (int, error) {
+			return 1, nil
+		})
+	assert.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,23 @@
+from __future__ import annotations, division as div
+import os, sys
+import numpy as np
+import xml.etree.ElementTree as ET
+import os.path
+from . import views
+from .. import settings as conf
+from ...core.models import User as U, Group
+from .utils import *
+from typing import (
+    Any,
+    Optional as Opt,
+)
+
+try:
+    import ujson as json
+except ImportError:
+    import json
+
+
+def handler():
+    from django.http import JsonResponse
+    return JsonResponse({})